@@ -3,12 +3,15 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
 	"log"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"text/tabwriter"
 	"time"
 
 	"zzz-tournament/internal/config"
@@ -18,8 +21,10 @@ import (
 	"zzz-tournament/internal/websocket"
 	"zzz-tournament/pkg/auth"
 	authConfig "zzz-tournament/pkg/config"
+	"zzz-tournament/pkg/storage"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
 	"github.com/joho/godotenv"
 )
 
@@ -58,6 +63,54 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Загружаем конфигурацию турниров
+	tournamentCfg, err := authConfig.LoadTournamentConfig()
+	if err != nil {
+		logger.Error("Failed to load tournament config", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	if err := tournamentCfg.Validate(); err != nil {
+		logger.Error("Invalid tournament config", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	// Загружаем конфигурацию рейтинга
+	ratingCfg, err := authConfig.LoadRatingConfig()
+	if err != nil {
+		logger.Error("Failed to load rating config", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	if err := ratingCfg.Validate(); err != nil {
+		logger.Error("Invalid rating config", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	// Загружаем конфигурацию очереди рейтинговых матчей
+	matchmakingCfg, err := authConfig.LoadMatchmakingConfig()
+	if err != nil {
+		logger.Error("Failed to load matchmaking config", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	if err := matchmakingCfg.Validate(); err != nil {
+		logger.Error("Invalid matchmaking config", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	// Загружаем конфигурацию поиска договорных результатов
+	collusionCfg, err := authConfig.LoadCollusionConfig()
+	if err != nil {
+		logger.Error("Failed to load collusion config", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	if err := collusionCfg.Validate(); err != nil {
+		logger.Error("Invalid collusion config", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
 	// Устанавливаем JWT секрет из конфигурации
 	if authCfg.JWTSecret == "" {
 		authCfg.JWTSecret = cfg.JWTSecret // Fallback на старую конфигурацию
@@ -68,6 +121,7 @@ func main() {
 		slog.String("environment", cfg.Environment),
 		slog.String("port", cfg.Port),
 		slog.Bool("rate_limiting", authCfg.RateLimitEnabled),
+		slog.String("rating_engine", ratingCfg.Engine),
 	)
 
 	// Подключение к БД
@@ -88,6 +142,11 @@ func main() {
 
 	logger.Info("Database migrations completed")
 
+	// Административные команды выполняются без запуска сервера
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:], database, logger, ratingCfg))
+	}
+
 	// WebSocket Hub
 	hub := websocket.NewHub()
 	go hub.Run()
@@ -141,8 +200,34 @@ func main() {
 		logger.Info("Global rate limiting enabled")
 	}
 
+	// === STORAGE ===
+	store, err := storage.New(storage.Options{
+		Driver:    cfg.StorageDriver,
+		LocalPath: cfg.UploadPath,
+		PublicURL: cfg.PublicURL,
+		Secret:    cfg.StorageSecret,
+		S3: storage.S3Options{
+			Endpoint:  cfg.AWSEndpoint,
+			Region:    cfg.AWSRegion,
+			Bucket:    cfg.AWSBucket,
+			AccessKey: cfg.AWSAccessKey,
+			SecretKey: cfg.AWSSecretKey,
+			PathStyle: cfg.AWSPathStyle,
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to initialize storage", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	logger.Info("Storage initialized", slog.String("driver", cfg.StorageDriver))
+
 	// === HANDLERS ===
-	h := handlers.New(database, hub, logger, authCfg)
+	h := handlers.New(database, hub, logger, authCfg, tournamentCfg, ratingCfg, matchmakingCfg, collusionCfg, handlers.EvidenceOptions{
+		Storage:       store,
+		MaxUploadSize: cfg.MaxUploadSize,
+		URLTTL:        time.Duration(cfg.EvidenceURLTTL) * time.Minute,
+	})
 
 	logger.Info("Handlers initialized successfully")
 
@@ -184,6 +269,10 @@ func main() {
 		authGroup.POST("/reset-password", h.Auth.ResetPassword)
 	}
 
+	// === FILES ===
+	// Файлы локального хранилища по подписанным ссылкам (права проверяются при выдаче ссылки)
+	api.GET("/files/*key", h.Evidence.ServeFile)
+
 	// === PROTECTED ROUTES ===
 	protected := api.Group("/")
 
@@ -206,12 +295,16 @@ func main() {
 			users.GET("/search", h.Users.SearchUsers)
 			users.GET("/:id", h.Users.GetUserByID)
 			users.GET("/:id/stats", h.Users.GetUserStats)
+			users.GET("/:id/rating-history", h.Users.GetRatingHistory)
+			users.GET("/:id/seasons", h.Seasons.GetUserSeasons)
+			users.GET("/:id/vs/:other_id", h.Users.GetHeadToHead)
 		}
 
 		// === HERO ROUTES ===
 		heroes := protected.Group("/heroes")
 		{
 			heroes.GET("", h.Heroes.GetHeroes)
+			heroes.GET("/stats", h.Heroes.GetHeroesStats)
 			heroes.GET("/:id", h.Heroes.GetHero)
 			heroes.GET("/:id/stats", h.Heroes.GetHeroStats)
 
@@ -244,6 +337,12 @@ func main() {
 			rooms.POST("/:id/kick", h.Rooms.KickPlayer)
 			rooms.PUT("/:id/password", h.Rooms.SetRoomPassword)
 
+			// Check-in перед турниром
+			rooms.GET("/:id/checkin", h.CheckIn.GetCheckInStatus)
+			rooms.POST("/:id/checkin", h.CheckIn.CheckIn)
+			rooms.POST("/:id/checkin/open", h.CheckIn.OpenCheckIn)
+			rooms.POST("/:id/checkin/close", h.CheckIn.CloseCheckIn)
+
 			// Чат
 			rooms.GET("/:id/messages", h.Chat.GetRoomMessages)
 			rooms.POST("/:id/messages", h.Chat.SendMessage)
@@ -255,6 +354,62 @@ func main() {
 			rooms.DELETE("/:id/chat/mute/:user_id", h.Chat.UnmuteUser)
 		}
 
+		// === TEAM ROUTES ===
+		teams := protected.Group("/teams")
+		{
+			teams.GET("", h.Teams.GetTeams)
+			teams.POST("", h.Teams.CreateTeam)
+			teams.GET("/:id", h.Teams.GetTeam)
+			teams.PUT("/:id", h.Teams.UpdateTeam)
+			teams.DELETE("/:id", h.Teams.DeleteTeam)
+
+			// Управление составом (капитан)
+			teams.POST("/:id/members", h.Teams.AddTeamMember)
+			teams.PUT("/:id/members/:user_id", h.Teams.UpdateTeamMember)
+			teams.DELETE("/:id/members/:user_id", h.Teams.RemoveTeamMember)
+			teams.POST("/:id/captain", h.Teams.TransferCaptain)
+		}
+
+		// === SEASON ROUTES ===
+		seasons := protected.Group("/seasons")
+		{
+			seasons.GET("", h.Seasons.GetSeasons)
+			seasons.GET("/current", h.Seasons.GetCurrentSeason)
+			seasons.GET("/:id/leaderboard", h.Seasons.GetSeasonLeaderboard)
+
+			// Только администраторы могут управлять сезонами
+			admin := seasons.Group("")
+			admin.Use(middleware.AdminOnlyMiddleware())
+			{
+				admin.POST("", h.Seasons.CreateSeason)
+				admin.PUT("/:id", h.Seasons.UpdateSeason)
+				admin.DELETE("/:id", h.Seasons.DeleteSeason)
+			}
+		}
+
+		// === RATING ROUTES ===
+		ratings := protected.Group("/ratings")
+		ratings.Use(middleware.AdminOnlyMiddleware())
+		{
+			ratings.POST("/recompute", h.Ratings.RecomputeRatings)
+		}
+
+		// === RANKED ROUTES ===
+		ranked := protected.Group("/ranked")
+		{
+			ranked.GET("/queue", h.Matchmaking.GetQueueStatus)
+		}
+
+		// === MODERATION ROUTES ===
+		moderation := protected.Group("/moderation")
+		moderation.Use(middleware.AdminOnlyMiddleware())
+		{
+			moderation.GET("/flags", h.Moderation.GetCollusionFlags)
+			moderation.GET("/flags/:id", h.Moderation.GetCollusionFlag)
+			moderation.POST("/flags/:id/review", h.Moderation.ReviewCollusionFlag)
+			moderation.POST("/scan", h.Moderation.RunCollusionScan)
+		}
+
 		// === TOURNAMENT ROUTES ===
 		tournaments := protected.Group("/tournaments")
 		{
@@ -264,6 +419,28 @@ func main() {
 			tournaments.POST("/:id/cancel", h.Tournaments.CancelTournament)
 			tournaments.GET("/:id/matches/:match_id", h.Tournaments.GetMatch)
 			tournaments.POST("/:id/matches/:match_id/result", h.Tournaments.SubmitMatchResult)
+			tournaments.POST("/:id/matches/:match_id/resolve", h.Tournaments.ResolveMatchDispute)
+			tournaments.POST("/:id/matches/:match_id/revert", h.Tournaments.RevertMatchResult)
+			tournaments.POST("/:id/matches/:match_id/ready", h.Tournaments.MarkMatchReady)
+			tournaments.GET("/:id/matches/:match_id/draft", h.Tournaments.GetMatchDraft)
+			tournaments.POST("/:id/matches/:match_id/draft", h.Tournaments.SubmitDraftAction)
+			tournaments.GET("/:id/runs", h.Tournaments.GetRuns)
+			tournaments.POST("/:id/runs", h.Tournaments.SubmitRun)
+			tournaments.POST("/:id/runs/:run_id/verify", h.Tournaments.VerifyRun)
+			tournaments.POST("/:id/runs/:run_id/reject", h.Tournaments.RejectRun)
+			tournaments.GET("/:id/leaderboard", h.Tournaments.GetLeaderboard)
+			tournaments.GET("/:id/matches/:match_id/evidence", h.Evidence.GetMatchEvidence)
+			tournaments.GET("/:id/runs/:run_id/evidence", h.Evidence.GetRunEvidence)
+
+			// Загрузка скриншотов-подтверждений
+			uploads := tournaments.Group("")
+			if authCfg.RateLimitEnabled {
+				uploads.Use(middleware.UploadRateLimiter())
+			}
+			{
+				uploads.POST("/:id/matches/:match_id/evidence", h.Evidence.UploadMatchEvidence)
+				uploads.POST("/:id/runs/:run_id/evidence", h.Evidence.UploadRunEvidence)
+			}
 
 			// Запуск турнира
 			protected.POST("/rooms/:id/tournament/start", h.Tournaments.StartTournament)
@@ -342,39 +519,96 @@ func main() {
 						"POST /api/v1/auth/reset-password":  "Сброс пароля",
 					},
 					"users": map[string]string{
-						"GET /api/v1/users/profile":     "Получить профиль",
-						"PUT /api/v1/users/profile":     "Обновить профиль",
-						"GET /api/v1/users/leaderboard": "Рейтинговая таблица",
-						"GET /api/v1/users/search":      "Поиск пользователей",
-						"GET /api/v1/users/:id":         "Информация о пользователе",
-						"GET /api/v1/users/:id/stats":   "Статистика пользователя",
+						"GET /api/v1/users/profile":            "Получить профиль",
+						"PUT /api/v1/users/profile":            "Обновить профиль",
+						"GET /api/v1/users/leaderboard":        "Рейтинговая таблица",
+						"GET /api/v1/users/search":             "Поиск пользователей",
+						"GET /api/v1/users/:id":                "Информация о пользователе",
+						"GET /api/v1/users/:id/stats":          "Статистика пользователя",
+						"GET /api/v1/users/:id/rating-history": "История рейтинга для графиков",
+						"GET /api/v1/users/:id/seasons":        "Итоги сезонов пользователя со значками тиров",
+						"GET /api/v1/users/:id/vs/:other_id":   "Личные встречи двух игроков",
 					},
 					"heroes": map[string]string{
 						"GET /api/v1/heroes":           "Список героев",
 						"GET /api/v1/heroes/:id":       "Информация о герое",
+						"GET /api/v1/heroes/stats":     "Статистика всех героев (мета)",
 						"GET /api/v1/heroes/:id/stats": "Статистика героя",
 						"POST /api/v1/heroes":          "Создать героя (админ)",
 						"PUT /api/v1/heroes/:id":       "Обновить героя (админ)",
 						"DELETE /api/v1/heroes/:id":    "Удалить героя (админ)",
 					},
 					"rooms": map[string]string{
-						"GET /api/v1/rooms":               "Список комнат",
-						"POST /api/v1/rooms":              "Создать комнату",
-						"GET /api/v1/rooms/:id":           "Информация о комнате",
-						"PUT /api/v1/rooms/:id":           "Обновить комнату",
-						"DELETE /api/v1/rooms/:id":        "Удалить комнату",
-						"POST /api/v1/rooms/:id/join":     "Присоединиться к комнате",
-						"POST /api/v1/rooms/:id/leave":    "Покинуть комнату",
-						"POST /api/v1/rooms/:id/kick":     "Исключить игрока",
-						"GET /api/v1/rooms/:id/messages":  "Сообщения чата",
-						"POST /api/v1/rooms/:id/messages": "Отправить сообщение",
+						"GET /api/v1/rooms":                    "Список комнат",
+						"POST /api/v1/rooms":                   "Создать комнату",
+						"GET /api/v1/rooms/:id":                "Информация о комнате",
+						"PUT /api/v1/rooms/:id":                "Обновить комнату",
+						"DELETE /api/v1/rooms/:id":             "Удалить комнату",
+						"POST /api/v1/rooms/:id/join":          "Присоединиться к комнате",
+						"POST /api/v1/rooms/:id/leave":         "Покинуть комнату",
+						"POST /api/v1/rooms/:id/kick":          "Исключить игрока",
+						"GET /api/v1/rooms/:id/messages":       "Сообщения чата",
+						"POST /api/v1/rooms/:id/messages":      "Отправить сообщение",
+						"GET /api/v1/rooms/:id/checkin":        "Состояние check-in",
+						"POST /api/v1/rooms/:id/checkin":       "Подтвердить участие",
+						"POST /api/v1/rooms/:id/checkin/open":  "Открыть check-in (хост)",
+						"POST /api/v1/rooms/:id/checkin/close": "Закрыть check-in (хост)",
+					},
+					"teams": map[string]string{
+						"GET /api/v1/teams":                         "Список команд",
+						"POST /api/v1/teams":                        "Создать команду",
+						"GET /api/v1/teams/:id":                     "Информация о команде",
+						"PUT /api/v1/teams/:id":                     "Обновить команду (капитан)",
+						"DELETE /api/v1/teams/:id":                  "Удалить команду (капитан)",
+						"POST /api/v1/teams/:id/members":            "Добавить игрока в состав (капитан)",
+						"PUT /api/v1/teams/:id/members/:user_id":    "Изменить роль игрока (капитан)",
+						"DELETE /api/v1/teams/:id/members/:user_id": "Исключить игрока или покинуть команду",
+						"POST /api/v1/teams/:id/captain":            "Передать капитанство",
+					},
+					"seasons": map[string]string{
+						"GET /api/v1/seasons":                 "Список сезонов",
+						"GET /api/v1/seasons/current":         "Текущий сезон",
+						"GET /api/v1/seasons/:id/leaderboard": "Итоговая таблица завершенного сезона",
+						"POST /api/v1/seasons":                "Создать сезон (админ)",
+						"PUT /api/v1/seasons/:id":             "Изменить сезон (админ)",
+						"DELETE /api/v1/seasons/:id":          "Удалить запланированный сезон (админ)",
+					},
+					"ratings": map[string]string{
+						"POST /api/v1/ratings/recompute": "Пересчет рейтингов по истории матчей: отчет или запись при commit (админ)",
+					},
+					"ranked": map[string]string{
+						"GET /api/v1/ranked/queue": "Состояние игрока в очереди рейтинговых матчей 1v1",
+						"WS ranked_queue_join":     "Встать в очередь рейтинговых матчей",
+						"WS ranked_queue_leave":    "Выйти из очереди",
+						"WS ranked_ready":          "Ответ на проверку готовности: {accept: true|false}",
+					},
+					"moderation": map[string]string{
+						"GET /api/v1/moderation/flags":             "Метки подозрений на договорные результаты (админ)",
+						"GET /api/v1/moderation/flags/:id":         "Метка с матчами и событием безопасности (админ)",
+						"POST /api/v1/moderation/flags/:id/review": "Подтвердить или отклонить метку (админ)",
+						"POST /api/v1/moderation/scan":             "Запустить поиск договорных результатов (админ)",
 					},
 					"tournaments": map[string]string{
-						"GET /api/v1/tournaments":                               "Список турниров",
-						"POST /api/v1/rooms/:id/tournament/start":               "Запустить турнир",
-						"GET /api/v1/tournaments/:id":                           "Информация о турнире",
-						"POST /api/v1/tournaments/:id/matches/:match_id/result": "Результат матча",
-						"POST /api/v1/tournaments/:id/cancel":                   "Отменить турнир",
+						"GET /api/v1/tournaments":                                 "Список турниров",
+						"POST /api/v1/rooms/:id/tournament/start":                 "Запустить турнир",
+						"GET /api/v1/tournaments/:id":                             "Информация о турнире",
+						"POST /api/v1/tournaments/:id/matches/:match_id/result":   "Результат матча",
+						"POST /api/v1/tournaments/:id/matches/:match_id/resolve":  "Разрешить спор о результате",
+						"POST /api/v1/tournaments/:id/matches/:match_id/revert":   "Отменить результат матча",
+						"POST /api/v1/tournaments/:id/matches/:match_id/ready":    "Отметить готовность к матчу",
+						"GET /api/v1/tournaments/:id/matches/:match_id/draft":     "Состояние драфта героев",
+						"POST /api/v1/tournaments/:id/matches/:match_id/draft":    "Бан или пик героя в драфте",
+						"GET /api/v1/tournaments/:id/runs":                        "Заходы турнира на очки или время",
+						"POST /api/v1/tournaments/:id/runs":                       "Отправить заход",
+						"POST /api/v1/tournaments/:id/runs/:run_id/verify":        "Подтвердить заход",
+						"POST /api/v1/tournaments/:id/runs/:run_id/reject":        "Отклонить заход",
+						"GET /api/v1/tournaments/:id/leaderboard":                 "Таблица лучших заходов",
+						"POST /api/v1/tournaments/:id/matches/:match_id/evidence": "Загрузить скриншот результата матча",
+						"GET /api/v1/tournaments/:id/matches/:match_id/evidence":  "Скриншоты матча",
+						"POST /api/v1/tournaments/:id/runs/:run_id/evidence":      "Загрузить скриншот захода",
+						"GET /api/v1/tournaments/:id/runs/:run_id/evidence":       "Скриншоты захода",
+						"GET /api/v1/files/*key":                                  "Файл по подписанной ссылке",
+						"POST /api/v1/tournaments/:id/cancel":                     "Отменить турнир",
 					},
 					"admin": map[string]string{
 						"POST /api/v1/admin/cleanup-tokens": "Очистка просроченных токенов",
@@ -433,6 +667,119 @@ func main() {
 		logger.Info("Token cleanup task started (runs every 6 hours)")
 	}
 
+	// Закрытие истекших окон check-in (каждые 15 секунд)
+	go func() {
+		ticker := time.NewTicker(15 * time.Second)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := h.CheckIn.CloseExpiredCheckIns(); err != nil {
+				logger.Error("Failed to close expired check-ins", slog.String("error", err.Error()))
+			}
+		}
+	}()
+
+	logger.Info("Check-in expiration task started (runs every 15 seconds)")
+
+	// Предупреждения о сроках матчей, технические победы и итоги турниров на очки или время
+	go func() {
+		ticker := time.NewTicker(tournamentCfg.DeadlineCheckInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := h.Tournaments.ProcessMatchDeadlines(); err != nil {
+				logger.Error("Failed to process match deadlines", slog.String("error", err.Error()))
+			}
+			if err := h.Tournaments.FinalizeScoreAttacks(); err != nil {
+				logger.Error("Failed to finalize score attack tournaments", slog.String("error", err.Error()))
+			}
+		}
+	}()
+
+	logger.Info("Match deadline task started",
+		slog.Duration("interval", tournamentCfg.DeadlineCheckInterval),
+		slog.String("walkover_rule", tournamentCfg.WalkoverRule),
+	)
+
+	// Истечение времени ходов драфта героев
+	go func() {
+		ticker := time.NewTicker(5 * time.Second)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := h.Tournaments.ProcessDraftTimeouts(); err != nil {
+				logger.Error("Failed to process draft timeouts", slog.String("error", err.Error()))
+			}
+		}
+	}()
+
+	logger.Info("Draft timeout task started (runs every 5 seconds)")
+
+	// Смена сезонов: архив итогов и сброс рейтингов
+	go func() {
+		ticker := time.NewTicker(ratingCfg.SeasonCheckInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := h.Seasons.ProcessSeasons(); err != nil {
+				logger.Error("Failed to process seasons", slog.String("error", err.Error()))
+			}
+		}
+	}()
+
+	logger.Info("Season task started", slog.Duration("interval", ratingCfg.SeasonCheckInterval))
+
+	// Снижение рейтинга неактивных игроков
+	go func() {
+		ticker := time.NewTicker(ratingCfg.DecayCheckInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := h.Users.ProcessRatingDecay(); err != nil {
+				logger.Error("Failed to process rating decay", slog.String("error", err.Error()))
+			}
+		}
+	}()
+
+	logger.Info("Rating decay task started",
+		slog.Duration("interval", ratingCfg.DecayCheckInterval),
+		slog.Int("threshold", ratingCfg.DecayThreshold),
+		slog.Int("after_days", ratingCfg.DecayAfterDays),
+	)
+
+	// Подбор пар в очереди рейтинговых матчей и истечение проверок готовности
+	go func() {
+		ticker := time.NewTicker(matchmakingCfg.TickInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			h.Matchmaking.ProcessQueue()
+		}
+	}()
+
+	logger.Info("Ranked matchmaking task started",
+		slog.Duration("interval", matchmakingCfg.TickInterval),
+		slog.Int("window_base", matchmakingCfg.WindowBase),
+		slog.Int("window_max", matchmakingCfg.WindowMax),
+	)
+
+	// Поиск договорных результатов (win-trading)
+	go func() {
+		ticker := time.NewTicker(collusionCfg.CheckInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if _, err := h.Moderation.DetectCollusion(); err != nil {
+				logger.Error("Failed to detect collusion", slog.String("error", err.Error()))
+			}
+		}
+	}()
+
+	logger.Info("Collusion detection task started",
+		slog.Duration("interval", collusionCfg.CheckInterval),
+		slog.Int("window_days", collusionCfg.WindowDays),
+	)
+
 	// === GRACEFUL SHUTDOWN ===
 	go func() {
 		logger.Info("Server starting",
@@ -478,3 +825,65 @@ func main() {
 func createUserBasedRateLimiter(rate time.Duration, burst int) gin.HandlerFunc {
 	return middleware.NewUserBasedRateLimiter(rate, burst).Middleware()
 }
+
+// runCommand выполняет административную команду и возвращает код завершения
+func runCommand(args []string, database *sqlx.DB, logger *slog.Logger, ratingCfg *authConfig.RatingConfig) int {
+	switch args[0] {
+	case "recompute-ratings":
+		return recomputeRatingsCommand(args[1:], database, logger, ratingCfg)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", args[0])
+		fmt.Fprintln(os.Stderr, "Available commands: recompute-ratings")
+		return 2
+	}
+}
+
+// recomputeRatingsCommand пересчитывает рейтинги по истории матчей.
+// Без флага -commit выводит только отчет о различиях.
+func recomputeRatingsCommand(args []string, database *sqlx.DB, logger *slog.Logger, ratingCfg *authConfig.RatingConfig) int {
+	flags := flag.NewFlagSet("recompute-ratings", flag.ContinueOnError)
+	commit := flags.Bool("commit", false, "write recomputed ratings and history")
+	limit := flags.Int("limit", 50, "number of changed ratings to print, 0 - all")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	ratings := handlers.NewRatingHandlers(database, nil, logger, ratingCfg)
+	report, err := ratings.Recompute(*commit)
+	if err != nil {
+		logger.Error("Failed to recompute ratings", slog.String("error", err.Error()))
+		return 1
+	}
+
+	fmt.Printf("Engine: %s\n", report.Engine)
+	fmt.Printf("Matches replayed: %d (walkovers: %d), season resets: %d\n",
+		report.Matches, report.Walkovers, report.SeasonResets)
+	fmt.Printf("Ratings changed: %d of %d\n", report.Changed, report.Ratings)
+	fmt.Printf("Decay entries not replayed: %d (kept in history, not included in new ratings)\n\n", report.DecayDropped)
+
+	entries := report.Entries
+	if *limit > 0 && len(entries) > *limit {
+		entries = entries[:*limit]
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "USER\tLADDER\tOLD\tNEW\tDELTA\tW/L OLD\tW/L NEW")
+	for _, e := range entries {
+		fmt.Fprintf(w, "%d %s\t%s\t%d\t%d\t%+d\t%d/%d\t%d/%d\n",
+			e.UserID, e.Username, e.Ladder, e.OldRating, e.NewRating, e.Delta,
+			e.OldWins, e.OldLosses, e.NewWins, e.NewLosses)
+	}
+	w.Flush()
+
+	if len(entries) < len(report.Entries) {
+		fmt.Printf("... and %d more\n", len(report.Entries)-len(entries))
+	}
+
+	if report.Committed {
+		fmt.Println("\nRecomputed ratings have been saved")
+	} else {
+		fmt.Println("\nDry run: nothing was saved, run with -commit to apply")
+	}
+
+	return 0
+}
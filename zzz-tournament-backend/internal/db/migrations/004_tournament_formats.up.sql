@@ -0,0 +1,17 @@
+-- migrations/004_tournament_formats.up.sql
+
+-- Формат турнира
+ALTER TABLE tournaments
+ADD COLUMN IF NOT EXISTS format VARCHAR(30) DEFAULT 'single_elimination' NOT NULL;
+
+-- Сетка и позиция матча внутри раунда
+ALTER TABLE matches
+ADD COLUMN IF NOT EXISTS bracket VARCHAR(20) DEFAULT 'winners' NOT NULL,
+ADD COLUMN IF NOT EXISTS position INTEGER DEFAULT 0 NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_matches_tournament_bracket
+    ON matches(tournament_id, bracket, round, position);
+
+COMMENT ON COLUMN tournaments.format IS 'Формат турнира: single_elimination, double_elimination';
+COMMENT ON COLUMN matches.bracket IS 'Сетка матча: winners, losers, grand_final';
+COMMENT ON COLUMN matches.position IS 'Позиция матча внутри раунда сетки';
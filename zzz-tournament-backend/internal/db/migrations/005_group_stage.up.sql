@@ -0,0 +1,19 @@
+-- migrations/005_group_stage.up.sql
+
+-- Параметры группового этапа
+ALTER TABLE tournaments
+ADD COLUMN IF NOT EXISTS group_count INTEGER DEFAULT 0 NOT NULL,
+ADD COLUMN IF NOT EXISTS advance_per_group INTEGER DEFAULT 0 NOT NULL;
+
+-- Номер группы матча
+ALTER TABLE matches
+ADD COLUMN IF NOT EXISTS group_number INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_matches_tournament_group
+    ON matches(tournament_id, group_number) WHERE group_number IS NOT NULL;
+
+COMMENT ON COLUMN tournaments.format IS 'Формат турнира: single_elimination, double_elimination, round_robin, groups_playoffs';
+COMMENT ON COLUMN tournaments.group_count IS 'Количество групп (round_robin, groups_playoffs)';
+COMMENT ON COLUMN tournaments.advance_per_group IS 'Сколько игроков из каждой группы выходят в плей-офф';
+COMMENT ON COLUMN matches.bracket IS 'Сетка матча: winners, losers, grand_final, group';
+COMMENT ON COLUMN matches.group_number IS 'Номер группы (с 1) для матчей группового этапа';
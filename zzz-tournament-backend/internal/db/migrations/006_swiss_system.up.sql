@@ -0,0 +1,9 @@
+-- migrations/006_swiss_system.up.sql
+
+-- Туры швейцарской системы создаются по мере завершения предыдущих,
+-- поэтому часто проверяется количество незавершенных матчей сетки
+CREATE INDEX IF NOT EXISTS idx_matches_tournament_bracket_status
+    ON matches(tournament_id, bracket, status);
+
+COMMENT ON COLUMN tournaments.format IS 'Формат турнира: single_elimination, double_elimination, round_robin, groups_playoffs, swiss';
+COMMENT ON COLUMN matches.bracket IS 'Сетка матча: winners, losers, grand_final, group, swiss';
@@ -0,0 +1,28 @@
+-- migrations/007_bracket_graph.up.sql
+
+-- Явные переходы по сетке: куда уходят победитель и проигравший матча
+ALTER TABLE matches
+ADD COLUMN IF NOT EXISTS next_match_id INTEGER REFERENCES matches(id) ON DELETE SET NULL,
+ADD COLUMN IF NOT EXISTS next_slot SMALLINT DEFAULT 0 NOT NULL,
+ADD COLUMN IF NOT EXISTS loser_match_id INTEGER REFERENCES matches(id) ON DELETE SET NULL,
+ADD COLUMN IF NOT EXISTS loser_slot SMALLINT DEFAULT 0 NOT NULL;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conname = 'chk_matches_slots'
+    ) THEN
+        ALTER TABLE matches
+        ADD CONSTRAINT chk_matches_slots
+        CHECK (next_slot IN (0, 1) AND loser_slot IN (0, 1));
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_matches_next_match ON matches(next_match_id) WHERE next_match_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_matches_loser_match ON matches(loser_match_id) WHERE loser_match_id IS NOT NULL;
+
+COMMENT ON COLUMN matches.next_match_id IS 'Матч, в который переходит победитель';
+COMMENT ON COLUMN matches.next_slot IS 'Слот победителя в следующем матче: 0 - player1, 1 - player2';
+COMMENT ON COLUMN matches.loser_match_id IS 'Матч, в который переходит проигравший (double elimination)';
+COMMENT ON COLUMN matches.loser_slot IS 'Слот проигравшего в матче нижней сетки: 0 - player1, 1 - player2';
@@ -0,0 +1,47 @@
+-- migrations/008_match_games.up.sql
+
+-- Количество игр в серии матча
+ALTER TABLE matches
+ADD COLUMN IF NOT EXISTS best_of SMALLINT DEFAULT 1 NOT NULL;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conname = 'chk_matches_best_of'
+    ) THEN
+        ALTER TABLE matches
+        ADD CONSTRAINT chk_matches_best_of
+        CHECK (best_of BETWEEN 1 AND 9 AND best_of % 2 = 1);
+    END IF;
+END $$;
+
+-- Результаты отдельных игр серии
+CREATE TABLE IF NOT EXISTS match_games (
+    id SERIAL PRIMARY KEY,
+    match_id INTEGER NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+    game_number SMALLINT NOT NULL,
+    winner_id INTEGER NOT NULL REFERENCES users(id),
+    player1_score INTEGER,
+    player2_score INTEGER,
+    clear_time_ms INTEGER,
+    player1_heroes INTEGER[] DEFAULT '{}' NOT NULL,
+    player2_heroes INTEGER[] DEFAULT '{}' NOT NULL,
+    reported_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (match_id, game_number),
+    CHECK (game_number BETWEEN 1 AND 9),
+    CHECK (clear_time_ms IS NULL OR clear_time_ms > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_match_games_match ON match_games(match_id, game_number);
+CREATE INDEX IF NOT EXISTS idx_match_games_winner ON match_games(winner_id);
+
+COMMENT ON COLUMN matches.best_of IS 'Количество игр в серии (best-of-N)';
+COMMENT ON TABLE match_games IS 'Результаты отдельных игр серии матча';
+COMMENT ON COLUMN match_games.player1_score IS 'Очки первого игрока в игре, если режим считает очки';
+COMMENT ON COLUMN match_games.player2_score IS 'Очки второго игрока в игре, если режим считает очки';
+COMMENT ON COLUMN match_games.clear_time_ms IS 'Время прохождения победителя в миллисекундах';
+COMMENT ON COLUMN match_games.player1_heroes IS 'ID героев, использованных первым игроком';
+COMMENT ON COLUMN match_games.player2_heroes IS 'ID героев, использованных вторым игроком';
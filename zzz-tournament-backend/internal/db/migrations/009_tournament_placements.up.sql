@@ -0,0 +1,20 @@
+-- migrations/009_tournament_placements.up.sql
+
+-- Итоговые места участников завершенных турниров
+CREATE TABLE IF NOT EXISTS tournament_placements (
+    tournament_id INTEGER NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    place INTEGER NOT NULL,
+    place_to INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tournament_id, user_id),
+    CHECK (place >= 1 AND place_to >= place)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tournament_placements_user ON tournament_placements(user_id, place);
+CREATE INDEX IF NOT EXISTS idx_tournament_placements_tournament ON tournament_placements(tournament_id, place);
+
+COMMENT ON TABLE tournament_placements IS 'Итоговые места участников турниров';
+COMMENT ON COLUMN tournament_placements.place IS 'Лучшее место в диапазоне (например, 5 для 5-8 мест)';
+COMMENT ON COLUMN tournament_placements.place_to IS 'Худшее место в диапазоне (равно place, если место не делится)';
+COMMENT ON COLUMN matches.bracket IS 'Сетка матча: winners, losers, grand_final, group, swiss, third_place';
@@ -0,0 +1,30 @@
+-- migrations/010_match_reports.up.sql
+
+-- Независимые отчеты участников о результате матча
+CREATE TABLE IF NOT EXISTS match_reports (
+    id SERIAL PRIMARY KEY,
+    match_id INTEGER NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+    reporter_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    games JSONB DEFAULT '[]' NOT NULL,
+    details TEXT DEFAULT '' NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (match_id, reporter_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_match_reports_match ON match_reports(match_id);
+
+-- Кто и почему разрешил спор о результате
+ALTER TABLE matches
+ADD COLUMN IF NOT EXISTS resolved_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+ADD COLUMN IF NOT EXISTS resolution_reason TEXT,
+ADD COLUMN IF NOT EXISTS resolved_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_matches_disputed ON matches(tournament_id) WHERE status = 'disputed';
+
+COMMENT ON TABLE match_reports IS 'Отчеты участников о результате матча до подтверждения';
+COMMENT ON COLUMN match_reports.games IS 'Результаты игр в том виде, в котором их отправил участник';
+COMMENT ON COLUMN matches.status IS 'Статус матча: pending, in_progress, disputed, finished, cancelled';
+COMMENT ON COLUMN matches.resolved_by IS 'Хост или администратор, разрешивший спор';
+COMMENT ON COLUMN matches.resolution_reason IS 'Обоснование решения по спору';
+COMMENT ON COLUMN matches.resolved_at IS 'Время разрешения спора';
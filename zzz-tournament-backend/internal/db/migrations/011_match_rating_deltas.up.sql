@@ -0,0 +1,18 @@
+-- migrations/011_match_rating_deltas.up.sql
+
+-- Изменения рейтинга, примененные по итогам матча. Нужны для точной отмены результата.
+ALTER TABLE matches
+ADD COLUMN IF NOT EXISTS winner_rating_delta INTEGER,
+ADD COLUMN IF NOT EXISTS loser_rating_delta INTEGER;
+
+-- Кто и почему отменил результат матча
+ALTER TABLE matches
+ADD COLUMN IF NOT EXISTS reverted_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+ADD COLUMN IF NOT EXISTS revert_reason TEXT,
+ADD COLUMN IF NOT EXISTS reverted_at TIMESTAMP;
+
+COMMENT ON COLUMN matches.winner_rating_delta IS 'Изменение рейтинга победителя по итогам матча';
+COMMENT ON COLUMN matches.loser_rating_delta IS 'Изменение рейтинга проигравшего по итогам матча';
+COMMENT ON COLUMN matches.reverted_by IS 'Хост или администратор, последним отменивший результат матча';
+COMMENT ON COLUMN matches.revert_reason IS 'Причина отмены результата';
+COMMENT ON COLUMN matches.reverted_at IS 'Время последней отмены результата';
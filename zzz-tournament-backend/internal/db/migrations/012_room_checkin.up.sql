@@ -0,0 +1,14 @@
+-- migrations/012_room_checkin.up.sql
+
+-- Окно регистрации (check-in) перед запуском турнира
+ALTER TABLE rooms
+ADD COLUMN IF NOT EXISTS checkin_deadline TIMESTAMP;
+
+ALTER TABLE room_participants
+ADD COLUMN IF NOT EXISTS checked_in_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_rooms_checkin_deadline ON rooms(checkin_deadline) WHERE status = 'check_in';
+
+COMMENT ON COLUMN rooms.status IS 'Статус комнаты: waiting, check_in, in_progress, finished';
+COMMENT ON COLUMN rooms.checkin_deadline IS 'Время закрытия последнего окна check-in';
+COMMENT ON COLUMN room_participants.checked_in_at IS 'Время подтверждения участия в текущем окне check-in';
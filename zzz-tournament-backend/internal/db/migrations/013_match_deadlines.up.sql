@@ -0,0 +1,37 @@
+-- migrations/013_match_deadlines.up.sql
+
+-- Настройки сроков матчей турнира
+ALTER TABLE tournaments
+ADD COLUMN IF NOT EXISTS match_deadline_minutes INTEGER DEFAULT 0 NOT NULL,
+ADD COLUMN IF NOT EXISTS walkover_rule VARCHAR(20) DEFAULT 'ready' NOT NULL;
+
+-- Срок матча, готовность игроков и техническая победа
+ALTER TABLE matches
+ADD COLUMN IF NOT EXISTS deadline TIMESTAMP,
+ADD COLUMN IF NOT EXISTS deadline_warned_at TIMESTAMP,
+ADD COLUMN IF NOT EXISTS player1_ready_at TIMESTAMP,
+ADD COLUMN IF NOT EXISTS player2_ready_at TIMESTAMP,
+ADD COLUMN IF NOT EXISTS is_walkover BOOLEAN DEFAULT false NOT NULL;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conname = 'chk_tournaments_walkover_rule'
+    ) THEN
+        ALTER TABLE tournaments
+        ADD CONSTRAINT chk_tournaments_walkover_rule
+        CHECK (walkover_rule IN ('ready', 'rating', 'none'));
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_matches_deadline ON matches(deadline)
+WHERE status IN ('pending', 'in_progress') AND deadline IS NOT NULL;
+
+COMMENT ON COLUMN tournaments.match_deadline_minutes IS 'Время на матч с момента, когда известны оба игрока (0 - без срока)';
+COMMENT ON COLUMN tournaments.walkover_rule IS 'Правило технической победы: ready, rating, none';
+COMMENT ON COLUMN matches.deadline IS 'Срок, до которого матч должен быть сыгран';
+COMMENT ON COLUMN matches.deadline_warned_at IS 'Когда игроки были предупреждены о скором истечении срока';
+COMMENT ON COLUMN matches.player1_ready_at IS 'Когда первый игрок отметился готовым';
+COMMENT ON COLUMN matches.player2_ready_at IS 'Когда второй игрок отметился готовым';
+COMMENT ON COLUMN matches.is_walkover IS 'Техническая победа по истечении срока, рейтинг не меняется';
@@ -0,0 +1,68 @@
+-- migrations/014_hero_draft.up.sql
+
+-- Настройки драфта героев турнира (draft_picks = 0 - без драфта)
+ALTER TABLE tournaments
+ADD COLUMN IF NOT EXISTS draft_bans SMALLINT DEFAULT 0 NOT NULL,
+ADD COLUMN IF NOT EXISTS draft_picks SMALLINT DEFAULT 0 NOT NULL,
+ADD COLUMN IF NOT EXISTS draft_turn_seconds INTEGER DEFAULT 30 NOT NULL,
+ADD COLUMN IF NOT EXISTS draft_timeout_rule VARCHAR(20) DEFAULT 'auto_pick' NOT NULL;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conname = 'chk_tournaments_draft_timeout_rule'
+    ) THEN
+        ALTER TABLE tournaments
+        ADD CONSTRAINT chk_tournaments_draft_timeout_rule
+        CHECK (draft_timeout_rule IN ('auto_pick', 'skip'));
+    END IF;
+END $$;
+
+-- Состояние драфта матча
+ALTER TABLE matches
+ADD COLUMN IF NOT EXISTS draft_status VARCHAR(20) DEFAULT 'none' NOT NULL,
+ADD COLUMN IF NOT EXISTS draft_turn SMALLINT DEFAULT 0 NOT NULL,
+ADD COLUMN IF NOT EXISTS draft_turn_deadline TIMESTAMP;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conname = 'chk_matches_draft_status'
+    ) THEN
+        ALTER TABLE matches
+        ADD CONSTRAINT chk_matches_draft_status
+        CHECK (draft_status IN ('none', 'in_progress', 'completed'));
+    END IF;
+END $$;
+
+-- Баны и пики героев в драфте матча
+CREATE TABLE IF NOT EXISTS match_hero_picks (
+    id SERIAL PRIMARY KEY,
+    match_id INTEGER NOT NULL REFERENCES matches(id) ON DELETE CASCADE,
+    turn SMALLINT NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    hero_id INTEGER REFERENCES heroes(id) ON DELETE CASCADE,
+    action VARCHAR(10) NOT NULL CHECK (action IN ('ban', 'pick')),
+    is_auto BOOLEAN DEFAULT false NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (match_id, turn)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_match_hero_picks_hero ON match_hero_picks(match_id, hero_id)
+WHERE hero_id IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_matches_draft_turn_deadline ON matches(draft_turn_deadline)
+WHERE draft_status = 'in_progress';
+
+COMMENT ON COLUMN tournaments.draft_bans IS 'Количество банов каждого игрока в драфте';
+COMMENT ON COLUMN tournaments.draft_picks IS 'Количество пиков каждого игрока в драфте (0 - без драфта)';
+COMMENT ON COLUMN tournaments.draft_turn_seconds IS 'Время на ход драфта в секундах';
+COMMENT ON COLUMN tournaments.draft_timeout_rule IS 'Действие при истечении времени хода: auto_pick, skip';
+COMMENT ON COLUMN matches.draft_status IS 'Статус драфта героев: none, in_progress, completed';
+COMMENT ON COLUMN matches.draft_turn IS 'Номер текущего хода драфта, начиная с 1';
+COMMENT ON COLUMN matches.draft_turn_deadline IS 'Срок текущего хода драфта';
+COMMENT ON TABLE match_hero_picks IS 'Баны и пики героев в драфте матча';
+COMMENT ON COLUMN match_hero_picks.hero_id IS 'Выбранный герой (NULL - ход пропущен)';
+COMMENT ON COLUMN match_hero_picks.is_auto IS 'Ход сделан автоматически по истечении времени';
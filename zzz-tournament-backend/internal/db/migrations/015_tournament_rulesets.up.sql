@@ -0,0 +1,7 @@
+-- migrations/015_tournament_rulesets.up.sql
+
+-- Правила составов команд турнира
+ALTER TABLE tournaments
+ADD COLUMN IF NOT EXISTS ruleset JSONB;
+
+COMMENT ON COLUMN tournaments.ruleset IS 'Правила составов: пул героев, запреты, лимиты редкости, элементов и ролей, размер команды (NULL - без ограничений)';
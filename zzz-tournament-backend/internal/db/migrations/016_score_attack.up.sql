@@ -0,0 +1,53 @@
+-- migrations/016_score_attack.up.sql
+
+-- Турниры на очки или время: тип результата и окно приема заходов
+ALTER TABLE tournaments
+ADD COLUMN IF NOT EXISTS score_type VARCHAR(10),
+ADD COLUMN IF NOT EXISTS runs_open_at TIMESTAMP,
+ADD COLUMN IF NOT EXISTS runs_close_at TIMESTAMP;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conname = 'chk_tournaments_score_type'
+    ) THEN
+        ALTER TABLE tournaments
+        ADD CONSTRAINT chk_tournaments_score_type
+        CHECK (score_type IN ('score', 'time'));
+    END IF;
+END $$;
+
+-- Заходы участников
+CREATE TABLE IF NOT EXISTS tournament_runs (
+    id SERIAL PRIMARY KEY,
+    tournament_id INTEGER NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    score BIGINT CHECK (score >= 0),
+    clear_time_ms BIGINT CHECK (clear_time_ms > 0),
+    heroes INTEGER[] DEFAULT '{}' NOT NULL,
+    evidence_url VARCHAR(500) NOT NULL,
+    notes TEXT,
+    status VARCHAR(20) DEFAULT 'pending' NOT NULL CHECK (status IN ('pending', 'verified', 'rejected')),
+    reviewed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    review_reason TEXT,
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_tournament_runs_tournament ON tournament_runs(tournament_id, status);
+CREATE INDEX IF NOT EXISTS idx_tournament_runs_user ON tournament_runs(user_id);
+
+CREATE INDEX IF NOT EXISTS idx_tournaments_runs_close_at ON tournaments(runs_close_at)
+WHERE status = 'started' AND runs_close_at IS NOT NULL;
+
+COMMENT ON COLUMN tournaments.format IS 'Формат турнира: single_elimination, double_elimination, round_robin, groups_playoffs, swiss, score_attack';
+COMMENT ON COLUMN tournaments.score_type IS 'Тип результата турнира score_attack: score (больше - лучше), time (меньше - лучше)';
+COMMENT ON COLUMN tournaments.runs_open_at IS 'Начало приема заходов';
+COMMENT ON COLUMN tournaments.runs_close_at IS 'Окончание приема заходов, после проверки всех заходов турнир завершается';
+COMMENT ON TABLE tournament_runs IS 'Заходы участников турниров на очки или время';
+COMMENT ON COLUMN tournament_runs.clear_time_ms IS 'Время прохождения в миллисекундах';
+COMMENT ON COLUMN tournament_runs.heroes IS 'Команда героев, использованная в заходе';
+COMMENT ON COLUMN tournament_runs.evidence_url IS 'Ссылка на подтверждение результата (скриншот или запись)';
+COMMENT ON COLUMN tournament_runs.status IS 'Статус проверки хостом: pending, verified, rejected';
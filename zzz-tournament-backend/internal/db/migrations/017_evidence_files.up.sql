@@ -0,0 +1,28 @@
+-- migrations/017_evidence_files.up.sql
+
+-- Скриншоты-подтверждения результатов матчей и заходов
+CREATE TABLE IF NOT EXISTS evidence_files (
+    id SERIAL PRIMARY KEY,
+    tournament_id INTEGER NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    match_id INTEGER REFERENCES matches(id) ON DELETE CASCADE,
+    run_id INTEGER REFERENCES tournament_runs(id) ON DELETE CASCADE,
+    uploader_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    storage_key VARCHAR(255) NOT NULL UNIQUE,
+    content_type VARCHAR(50) NOT NULL,
+    size_bytes INTEGER NOT NULL CHECK (size_bytes > 0),
+    original_name VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((match_id IS NULL) <> (run_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_evidence_files_match ON evidence_files(match_id) WHERE match_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_evidence_files_run ON evidence_files(run_id) WHERE run_id IS NOT NULL;
+
+-- Ссылка на подтверждение захода больше не обязательна: его можно загрузить файлом
+ALTER TABLE tournament_runs
+ALTER COLUMN evidence_url DROP NOT NULL;
+
+COMMENT ON TABLE evidence_files IS 'Загруженные скриншоты-подтверждения результатов матчей и заходов';
+COMMENT ON COLUMN evidence_files.storage_key IS 'Ключ файла в хранилище (локальном или S3)';
+COMMENT ON COLUMN evidence_files.content_type IS 'Тип изображения, определенный по содержимому: image/png, image/jpeg, image/webp';
+COMMENT ON COLUMN evidence_files.original_name IS 'Имя файла у пользователя, только для отображения';
@@ -0,0 +1,89 @@
+-- migrations/018_teams.up.sql
+
+-- Команды для турниров отрядов
+CREATE TABLE IF NOT EXISTS teams (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL UNIQUE,
+    tag VARCHAR(5),
+    captain_id INTEGER NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+    rating INTEGER DEFAULT 1000 NOT NULL,
+    wins INTEGER DEFAULT 0 NOT NULL,
+    losses INTEGER DEFAULT 0 NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Состав команды: основные игроки и запасные. Игрок состоит не больше чем в одной команде.
+CREATE TABLE IF NOT EXISTS team_members (
+    team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) DEFAULT 'player' NOT NULL CHECK (role IN ('player', 'substitute')),
+    joined_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (team_id, user_id),
+    UNIQUE (user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_teams_rating ON teams(rating DESC);
+
+-- Командный режим комнаты: капитан регистрирует команду и представляет ее в комнате
+ALTER TABLE rooms
+ADD COLUMN IF NOT EXISTS team_mode BOOLEAN DEFAULT false NOT NULL,
+ADD COLUMN IF NOT EXISTS team_size INTEGER;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conname = 'chk_rooms_team_size'
+    ) THEN
+        ALTER TABLE rooms
+        ADD CONSTRAINT chk_rooms_team_size
+        CHECK (team_size IS NULL OR team_size BETWEEN 1 AND 6);
+    END IF;
+END $$;
+
+ALTER TABLE room_participants
+ADD COLUMN IF NOT EXISTS team_id INTEGER REFERENCES teams(id) ON DELETE SET NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_room_participants_team ON room_participants(room_id, team_id) WHERE team_id IS NOT NULL;
+
+-- Командный турнир и составы команд на момент запуска
+ALTER TABLE tournaments
+ADD COLUMN IF NOT EXISTS team_mode BOOLEAN DEFAULT false NOT NULL,
+ADD COLUMN IF NOT EXISTS winner_team_id INTEGER REFERENCES teams(id) ON DELETE SET NULL;
+
+CREATE TABLE IF NOT EXISTS tournament_teams (
+    tournament_id INTEGER NOT NULL REFERENCES tournaments(id) ON DELETE CASCADE,
+    team_id INTEGER NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    captain_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    players INTEGER[] DEFAULT '{}' NOT NULL,
+    substitutes INTEGER[] DEFAULT '{}' NOT NULL,
+    PRIMARY KEY (tournament_id, team_id),
+    UNIQUE (tournament_id, captain_id)
+);
+
+-- Команды в матчах. Слоты игроков в командном матче занимают капитаны.
+ALTER TABLE matches
+ADD COLUMN IF NOT EXISTS team1_id INTEGER REFERENCES teams(id) ON DELETE SET NULL,
+ADD COLUMN IF NOT EXISTS team2_id INTEGER REFERENCES teams(id) ON DELETE SET NULL,
+ADD COLUMN IF NOT EXISTS winner_team_id INTEGER REFERENCES teams(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_matches_team1 ON matches(team1_id) WHERE team1_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_matches_team2 ON matches(team2_id) WHERE team2_id IS NOT NULL;
+
+ALTER TABLE tournament_placements
+ADD COLUMN IF NOT EXISTS team_id INTEGER REFERENCES teams(id) ON DELETE SET NULL;
+
+COMMENT ON TABLE teams IS 'Команды для турниров отрядов';
+COMMENT ON COLUMN teams.captain_id IS 'Капитан: регистрирует команду в комнатах и отправляет результаты матчей';
+COMMENT ON COLUMN teams.rating IS 'Рейтинг ELO команды по командным матчам';
+COMMENT ON TABLE team_members IS 'Состав команды';
+COMMENT ON COLUMN team_members.role IS 'Роль в составе: player - основной игрок, substitute - запасной';
+COMMENT ON COLUMN rooms.team_mode IS 'Комната для командного турнира';
+COMMENT ON COLUMN rooms.team_size IS 'Минимальное количество основных игроков в команде';
+COMMENT ON COLUMN room_participants.team_id IS 'Команда, которую представляет капитан в командной комнате';
+COMMENT ON TABLE tournament_teams IS 'Составы команд, зафиксированные при запуске турнира';
+COMMENT ON COLUMN matches.team1_id IS 'Команда первого игрока (капитана) в командном матче';
+COMMENT ON COLUMN matches.team2_id IS 'Команда второго игрока (капитана) в командном матче';
+COMMENT ON COLUMN matches.winner_team_id IS 'Команда-победитель командного матча';
@@ -0,0 +1,31 @@
+-- migrations/019_glicko2.up.sql
+
+-- Параметры Glicko-2: отклонение рейтинга (RD) и волатильность.
+-- При рейтинговой системе Elo они не меняются.
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS rating_deviation DOUBLE PRECISION DEFAULT 350 NOT NULL,
+ADD COLUMN IF NOT EXISTS rating_volatility DOUBLE PRECISION DEFAULT 0.06 NOT NULL,
+ADD COLUMN IF NOT EXISTS rating_updated_at TIMESTAMP;
+
+ALTER TABLE teams
+ADD COLUMN IF NOT EXISTS rating_deviation DOUBLE PRECISION DEFAULT 350 NOT NULL,
+ADD COLUMN IF NOT EXISTS rating_volatility DOUBLE PRECISION DEFAULT 0.06 NOT NULL,
+ADD COLUMN IF NOT EXISTS rating_updated_at TIMESTAMP;
+
+-- Время последнего рейтингового матча для уже сыгравших игроков
+UPDATE users u
+SET rating_updated_at = (
+    SELECT MAX(m.updated_at) FROM matches m
+    WHERE (m.player1_id = u.id OR m.player2_id = u.id) AND m.status = 'finished'
+)
+WHERE rating_updated_at IS NULL;
+
+-- Консервативный рейтинг для сортировки таблицы лидеров
+CREATE INDEX IF NOT EXISTS idx_users_conservative_rating ON users((rating - 2 * rating_deviation) DESC);
+
+COMMENT ON COLUMN users.rating_deviation IS 'Отклонение рейтинга Glicko-2 (RD): чем меньше, тем точнее рейтинг';
+COMMENT ON COLUMN users.rating_volatility IS 'Волатильность рейтинга Glicko-2';
+COMMENT ON COLUMN users.rating_updated_at IS 'Время последнего рейтингового матча';
+COMMENT ON COLUMN teams.rating_deviation IS 'Отклонение рейтинга Glicko-2 (RD) команды';
+COMMENT ON COLUMN teams.rating_volatility IS 'Волатильность рейтинга Glicko-2 команды';
+COMMENT ON COLUMN teams.rating_updated_at IS 'Время последнего рейтингового матча команды';
@@ -0,0 +1,24 @@
+-- migrations/020_rating_history.up.sql
+
+-- История изменений рейтинга пользователей
+CREATE TABLE IF NOT EXISTS rating_history (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    match_id INTEGER REFERENCES matches(id) ON DELETE SET NULL,
+    opponent_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    rating_type VARCHAR(20) DEFAULT 'tournament' NOT NULL CHECK (rating_type IN ('tournament', 'ranked', 'casual')),
+    reason VARCHAR(20) DEFAULT 'match' NOT NULL CHECK (reason IN ('match', 'revert')),
+    rating_before INTEGER NOT NULL,
+    rating_after INTEGER NOT NULL,
+    delta INTEGER NOT NULL,
+    rating_deviation DOUBLE PRECISION,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_rating_history_user ON rating_history(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_rating_history_match ON rating_history(match_id) WHERE match_id IS NOT NULL;
+
+COMMENT ON TABLE rating_history IS 'История изменений рейтинга пользователей';
+COMMENT ON COLUMN rating_history.rating_type IS 'Тип рейтингового матча: tournament, ranked, casual';
+COMMENT ON COLUMN rating_history.reason IS 'Причина изменения: match - результат матча, revert - отмена результата';
+COMMENT ON COLUMN rating_history.rating_deviation IS 'Отклонение рейтинга Glicko-2 после изменения';
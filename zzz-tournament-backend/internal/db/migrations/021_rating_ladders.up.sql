@@ -0,0 +1,45 @@
+-- migrations/021_rating_ladders.up.sql
+
+-- Рейтинг игроков в отдельных ладдерах по типу матчей.
+-- Основной рейтинг в users соответствует ладдеру tournament.
+CREATE TABLE IF NOT EXISTS user_ratings (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    ladder VARCHAR(20) NOT NULL CHECK (ladder IN ('tournament', 'ranked', 'casual')),
+    rating INTEGER DEFAULT 1000 NOT NULL,
+    rating_deviation DOUBLE PRECISION DEFAULT 350 NOT NULL,
+    rating_volatility DOUBLE PRECISION DEFAULT 0.06 NOT NULL,
+    wins INTEGER DEFAULT 0 NOT NULL,
+    losses INTEGER DEFAULT 0 NOT NULL,
+    rating_updated_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, ladder)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_ratings_ladder ON user_ratings(ladder, rating DESC);
+
+-- Текущие рейтинги переносятся в турнирный ладдер
+INSERT INTO user_ratings (user_id, ladder, rating, rating_deviation, rating_volatility, wins, losses, rating_updated_at)
+SELECT id, 'tournament', rating, rating_deviation, rating_volatility, wins, losses, rating_updated_at
+FROM users
+ON CONFLICT (user_id, ladder) DO NOTHING;
+
+-- Ладдер, в который идут результаты матчей турнира
+ALTER TABLE tournaments
+ADD COLUMN IF NOT EXISTS ladder VARCHAR(20) DEFAULT 'tournament' NOT NULL;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conname = 'chk_tournaments_ladder'
+    ) THEN
+        ALTER TABLE tournaments
+        ADD CONSTRAINT chk_tournaments_ladder
+        CHECK (ladder IN ('tournament', 'casual'));
+    END IF;
+END $$;
+
+COMMENT ON TABLE user_ratings IS 'Рейтинг игроков по ладдерам: tournament, ranked, casual';
+COMMENT ON COLUMN user_ratings.ladder IS 'Ладдер: tournament - турниры (совпадает с users.rating), ranked - рейтинговые матчи 1v1, casual - казуальные турниры';
+COMMENT ON COLUMN tournaments.ladder IS 'Ладдер, в который засчитываются матчи турнира';
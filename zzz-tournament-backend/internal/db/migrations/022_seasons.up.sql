@@ -0,0 +1,72 @@
+-- migrations/022_seasons.up.sql
+
+-- Соревновательные сезоны
+CREATE TABLE IF NOT EXISTS seasons (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    status VARCHAR(20) DEFAULT 'scheduled' NOT NULL CHECK (status IN ('scheduled', 'active', 'finished')),
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    finished_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_seasons_status ON seasons(status, starts_at);
+
+-- Одновременно может идти только один сезон
+CREATE UNIQUE INDEX IF NOT EXISTS idx_seasons_single_active ON seasons(status) WHERE status = 'active';
+
+-- Итоговые места игроков по ладдерам на момент завершения сезона
+CREATE TABLE IF NOT EXISTS season_standings (
+    season_id INTEGER NOT NULL REFERENCES seasons(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    ladder VARCHAR(20) NOT NULL CHECK (ladder IN ('tournament', 'ranked', 'casual')),
+    rank INTEGER NOT NULL,
+    rating INTEGER NOT NULL,
+    tier VARCHAR(20) NOT NULL,
+    peak_rating INTEGER NOT NULL,
+    peak_tier VARCHAR(20) NOT NULL,
+    wins INTEGER DEFAULT 0 NOT NULL,
+    losses INTEGER DEFAULT 0 NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (season_id, ladder, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_season_standings_rank ON season_standings(season_id, ladder, rank);
+CREATE INDEX IF NOT EXISTS idx_season_standings_user ON season_standings(user_id);
+
+-- Итог матча в истории рейтинга для подсчета побед и поражений за сезон
+ALTER TABLE rating_history
+ADD COLUMN IF NOT EXISTS won BOOLEAN;
+
+UPDATE rating_history
+SET won = delta > 0
+WHERE won IS NULL AND reason = 'match';
+
+UPDATE rating_history
+SET won = delta < 0
+WHERE won IS NULL AND reason = 'revert';
+
+-- Сброс рейтинга при смене сезона записывается в историю
+ALTER TABLE rating_history DROP CONSTRAINT IF EXISTS rating_history_reason_check;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint
+        WHERE conname = 'chk_rating_history_reason'
+    ) THEN
+        ALTER TABLE rating_history
+        ADD CONSTRAINT chk_rating_history_reason
+        CHECK (reason IN ('match', 'revert', 'season_reset'));
+    END IF;
+END $$;
+
+COMMENT ON TABLE seasons IS 'Соревновательные сезоны: по завершении итоги архивируются, а рейтинги частично сбрасываются';
+COMMENT ON TABLE season_standings IS 'Итоговые места игроков в ладдерах по завершенным сезонам';
+COMMENT ON COLUMN season_standings.peak_tier IS 'Наивысший тир, достигнутый за сезон';
+COMMENT ON COLUMN rating_history.won IS 'Победа в матче (для записей match и revert)';
+COMMENT ON COLUMN rating_history.reason IS 'Причина изменения: match - результат матча, revert - отмена результата, season_reset - сброс рейтинга при смене сезона';
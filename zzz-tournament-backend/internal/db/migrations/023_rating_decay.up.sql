@@ -0,0 +1,16 @@
+-- migrations/023_rating_decay.up.sql
+
+-- Время последнего снижения рейтинга за неактивность.
+-- rating_updated_at при снижении не меняется, чтобы не сбрасывать срок неактивности.
+ALTER TABLE user_ratings
+ADD COLUMN IF NOT EXISTS rating_decayed_at TIMESTAMP;
+
+-- Снижение рейтинга за неактивность записывается в историю
+ALTER TABLE rating_history DROP CONSTRAINT IF EXISTS chk_rating_history_reason;
+
+ALTER TABLE rating_history
+ADD CONSTRAINT chk_rating_history_reason
+CHECK (reason IN ('match', 'revert', 'season_reset', 'decay'));
+
+COMMENT ON COLUMN user_ratings.rating_decayed_at IS 'Время последнего снижения рейтинга за неактивность';
+COMMENT ON COLUMN rating_history.reason IS 'Причина изменения: match - результат матча, revert - отмена результата, season_reset - сброс рейтинга при смене сезона, decay - снижение за неактивность';
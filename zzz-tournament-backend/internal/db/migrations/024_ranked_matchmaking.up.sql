@@ -0,0 +1,13 @@
+-- migrations/024_ranked_matchmaking.up.sql
+
+-- Рейтинговый матч 1v1 из очереди оформляется турниром из одного матча в ладдере ranked
+ALTER TABLE tournaments DROP CONSTRAINT IF EXISTS chk_tournaments_ladder;
+
+ALTER TABLE tournaments
+ADD CONSTRAINT chk_tournaments_ladder
+CHECK (ladder IN ('tournament', 'ranked', 'casual'));
+
+-- Поиск незавершенных рейтинговых матчей игрока при постановке в очередь
+CREATE INDEX IF NOT EXISTS idx_tournaments_ladder_status ON tournaments(ladder, status);
+
+COMMENT ON COLUMN tournaments.ladder IS 'Ладдер, в который засчитываются матчи турнира: tournament, casual или ranked - матч из очереди рейтинговых матчей 1v1';
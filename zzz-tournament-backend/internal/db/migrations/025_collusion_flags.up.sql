@@ -0,0 +1,39 @@
+-- migrations/025_collusion_flags.up.sql
+
+-- События фоновых проверок не привязаны к запросу и не имеют IP клиента
+ALTER TABLE security_events ALTER COLUMN client_ip DROP NOT NULL;
+
+-- Подозрения на договорные результаты для проверки модераторами
+CREATE TABLE IF NOT EXISTS collusion_flags (
+    id SERIAL PRIMARY KEY,
+    pattern VARCHAR(30) NOT NULL CHECK (pattern IN ('repeated_pair', 'fast_result', 'alternating_wins', 'feeder_account')),
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    partner_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    match_ids INTEGER[] NOT NULL,
+    last_match_at TIMESTAMP NOT NULL,
+    details JSONB,
+    security_event_id INTEGER REFERENCES security_events(id) ON DELETE SET NULL,
+    status VARCHAR(20) DEFAULT 'open' NOT NULL CHECK (status IN ('open', 'confirmed', 'dismissed')),
+    reviewed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP,
+    review_note TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+-- По одной открытой метке на шаблон и пару игроков
+CREATE UNIQUE INDEX IF NOT EXISTS idx_collusion_flags_open
+ON collusion_flags(pattern, user_id, partner_id) WHERE status = 'open';
+
+CREATE INDEX IF NOT EXISTS idx_collusion_flags_status ON collusion_flags(status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_collusion_flags_user ON collusion_flags(user_id);
+CREATE INDEX IF NOT EXISTS idx_collusion_flags_partner ON collusion_flags(partner_id);
+
+COMMENT ON TABLE collusion_flags IS 'Подозрения на договорные результаты (win-trading), найденные фоновой проверкой';
+COMMENT ON COLUMN collusion_flags.pattern IS 'Шаблон: repeated_pair - частые встречи пары, fast_result - результаты сразу после создания матча, alternating_wins - чередование побед, feeder_account - новый аккаунт проигрывает только одному игроку';
+COMMENT ON COLUMN collusion_flags.user_id IS 'Игрок пары; для feeder_account - новый аккаунт';
+COMMENT ON COLUMN collusion_flags.partner_id IS 'Второй игрок пары; для feeder_account - игрок, получающий победы';
+COMMENT ON COLUMN collusion_flags.match_ids IS 'Матчи, на основании которых поставлена метка';
+COMMENT ON COLUMN collusion_flags.last_match_at IS 'Время последнего из матчей; повторная метка ставится только при новых матчах';
+COMMENT ON COLUMN collusion_flags.security_event_id IS 'Запись в журнале событий безопасности';
+COMMENT ON COLUMN collusion_flags.status IS 'Статус проверки: open, confirmed, dismissed';
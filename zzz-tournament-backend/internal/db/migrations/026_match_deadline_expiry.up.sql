@@ -0,0 +1,7 @@
+-- migrations/026_match_deadline_expiry.up.sql
+
+-- Просроченный матч, по которому техническая победа не присуждена и решение за хостом
+ALTER TABLE matches
+ADD COLUMN IF NOT EXISTS deadline_expired_at TIMESTAMP;
+
+COMMENT ON COLUMN matches.deadline_expired_at IS 'Когда хост был уведомлен об истечении срока матча без технической победы';
@@ -0,0 +1,22 @@
+-- migrations/027_match_rating_snapshots.up.sql
+
+-- Рейтинговое состояние участников перед матчем. Отмена результата возвращает из него
+-- отклонение и волатильность Glicko-2, если рейтинг игрока после матча не менялся.
+ALTER TABLE matches
+ADD COLUMN IF NOT EXISTS winner_rating_before INTEGER,
+ADD COLUMN IF NOT EXISTS winner_deviation_before DOUBLE PRECISION,
+ADD COLUMN IF NOT EXISTS winner_volatility_before DOUBLE PRECISION,
+ADD COLUMN IF NOT EXISTS winner_rated_at_before TIMESTAMP,
+ADD COLUMN IF NOT EXISTS loser_rating_before INTEGER,
+ADD COLUMN IF NOT EXISTS loser_deviation_before DOUBLE PRECISION,
+ADD COLUMN IF NOT EXISTS loser_volatility_before DOUBLE PRECISION,
+ADD COLUMN IF NOT EXISTS loser_rated_at_before TIMESTAMP;
+
+COMMENT ON COLUMN matches.winner_rating_before IS 'Рейтинг победителя перед матчем (NULL - техническая победа или матч сыгран до сохранения состояния)';
+COMMENT ON COLUMN matches.winner_deviation_before IS 'Отклонение рейтинга победителя перед матчем';
+COMMENT ON COLUMN matches.winner_volatility_before IS 'Волатильность рейтинга победителя перед матчем';
+COMMENT ON COLUMN matches.winner_rated_at_before IS 'Время предыдущего рейтингового матча победителя';
+COMMENT ON COLUMN matches.loser_rating_before IS 'Рейтинг проигравшего перед матчем (NULL - техническая победа или матч сыгран до сохранения состояния)';
+COMMENT ON COLUMN matches.loser_deviation_before IS 'Отклонение рейтинга проигравшего перед матчем';
+COMMENT ON COLUMN matches.loser_volatility_before IS 'Волатильность рейтинга проигравшего перед матчем';
+COMMENT ON COLUMN matches.loser_rated_at_before IS 'Время предыдущего рейтингового матча проигравшего';
@@ -0,0 +1,18 @@
+-- migrations/028_match_finished_at.up.sql
+
+-- Время фиксации результата матча. В отличие от updated_at не меняется при
+-- последующих правках матча и задает порядок пересчета рейтингов.
+ALTER TABLE matches
+ADD COLUMN IF NOT EXISTS finished_at TIMESTAMP;
+
+-- Для уже сыгранных матчей берем время записи изменения рейтинга, иначе время последнего обновления
+UPDATE matches m
+SET finished_at = COALESCE(
+    (SELECT MAX(h.created_at) FROM rating_history h WHERE h.match_id = m.id AND h.reason = 'match'),
+    m.updated_at
+)
+WHERE m.status = 'finished' AND m.winner_id IS NOT NULL AND m.finished_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_matches_finished_at ON matches(finished_at) WHERE finished_at IS NOT NULL;
+
+COMMENT ON COLUMN matches.finished_at IS 'Когда был зафиксирован результат матча (NULL - матч не сыгран или результат отменен)';
@@ -0,0 +1,50 @@
+-- migrations/029_rated_matches.up.sql
+
+-- Число рейтинговых матчей. Технические победы засчитываются в wins/losses, но
+-- рейтинг не меняют, поэтому калибровка и K-фактор считаются по этому счетчику.
+ALTER TABLE user_ratings
+ADD COLUMN IF NOT EXISTS rated_matches INTEGER DEFAULT 0 NOT NULL;
+
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS rated_matches INTEGER DEFAULT 0 NOT NULL;
+
+ALTER TABLE teams
+ADD COLUMN IF NOT EXISTS rated_matches INTEGER DEFAULT 0 NOT NULL;
+
+-- Уже сыгранные матчи игроков без технических побед
+UPDATE user_ratings ur
+SET rated_matches = played.rated
+FROM (
+    SELECT p.user_id, COALESCE(t.ladder, 'tournament') as ladder, COUNT(*) as rated
+    FROM matches m
+    LEFT JOIN tournaments t ON m.tournament_id = t.id
+    CROSS JOIN LATERAL (VALUES (m.player1_id), (m.player2_id)) p(user_id)
+    WHERE m.status = 'finished' AND m.winner_id IS NOT NULL AND NOT m.is_walkover
+      AND m.player1_id IS NOT NULL AND m.player2_id IS NOT NULL
+      AND COALESCE(t.team_mode, false) = false
+    GROUP BY p.user_id, COALESCE(t.ladder, 'tournament')
+) played
+WHERE played.user_id = ur.user_id AND played.ladder = ur.ladder;
+
+UPDATE users u
+SET rated_matches = ur.rated_matches
+FROM user_ratings ur
+WHERE ur.user_id = u.id AND ur.ladder = 'tournament';
+
+-- Уже сыгранные матчи команд без технических побед
+UPDATE teams tm
+SET rated_matches = played.rated
+FROM (
+    SELECT p.team_id, COUNT(*) as rated
+    FROM matches m
+    JOIN tournaments t ON m.tournament_id = t.id
+    CROSS JOIN LATERAL (VALUES (m.team1_id), (m.team2_id)) p(team_id)
+    WHERE m.status = 'finished' AND m.winner_id IS NOT NULL AND NOT m.is_walkover
+      AND t.team_mode = true AND p.team_id IS NOT NULL
+    GROUP BY p.team_id
+) played
+WHERE played.team_id = tm.id;
+
+COMMENT ON COLUMN user_ratings.rated_matches IS 'Число рейтинговых матчей в ладдере (без технических побед)';
+COMMENT ON COLUMN users.rated_matches IS 'Число рейтинговых матчей в турнирном ладдере (без технических побед)';
+COMMENT ON COLUMN teams.rated_matches IS 'Число рейтинговых матчей команды (без технических побед)';
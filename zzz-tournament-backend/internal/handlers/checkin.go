@@ -0,0 +1,483 @@
+// internal/handlers/checkin.go
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"zzz-tournament/internal/models"
+	"zzz-tournament/internal/websocket"
+	"zzz-tournament/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// Длительность окна check-in в минутах
+const (
+	DefaultCheckInMinutes = 10
+	MaxCheckInMinutes     = 120
+)
+
+// Ошибки check-in
+var (
+	errCheckInClosed      = errors.New("check-in is not open")
+	errNotRoomParticipant = errors.New("you are not a participant of this room")
+	errAlreadyCheckedIn   = errors.New("already checked in")
+)
+
+// CheckInHandlers обработчики регистрации участников перед турниром
+type CheckInHandlers struct {
+	BaseHandlers
+	Chat *ChatHandlers
+}
+
+// NewCheckInHandlers создает новый экземпляр CheckInHandlers
+func NewCheckInHandlers(db *sqlx.DB, hub *websocket.Hub, logger *slog.Logger, chat *ChatHandlers) *CheckInHandlers {
+	return &CheckInHandlers{
+		BaseHandlers: newBaseHandlers(db, hub, logger),
+		Chat:         chat,
+	}
+}
+
+// OpenCheckInRequest структура запроса открытия check-in
+type OpenCheckInRequest struct {
+	DurationMinutes int `json:"duration_minutes"` // По умолчанию DefaultCheckInMinutes
+}
+
+// CheckInStatus текущее состояние check-in комнаты
+type CheckInStatus struct {
+	RoomID      int           `json:"room_id"`
+	IsOpen      bool          `json:"is_open"`
+	Deadline    *time.Time    `json:"deadline,omitempty"`
+	SecondsLeft int           `json:"seconds_left"`
+	CheckedIn   []models.User `json:"checked_in"`
+	Pending     []models.User `json:"pending"`
+}
+
+// OpenCheckIn открытие окна check-in (только хост)
+func (h *CheckInHandlers) OpenCheckIn(c *gin.Context) {
+	roomID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid room ID")
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	var req OpenCheckInRequest
+	c.ShouldBindJSON(&req)
+
+	if req.DurationMinutes == 0 {
+		req.DurationMinutes = DefaultCheckInMinutes
+	}
+
+	if req.DurationMinutes < 1 || req.DurationMinutes > MaxCheckInMinutes {
+		utils.BadRequestResponse(c, "Check-in duration must be between 1 and "+strconv.Itoa(MaxCheckInMinutes)+" minutes")
+		return
+	}
+
+	var hostID, participants int
+	var status string
+	err = h.DB.QueryRow(`
+		SELECT r.host_id, r.status, (SELECT COUNT(*) FROM room_participants WHERE room_id = r.id)
+		FROM rooms r WHERE r.id = $1
+	`, roomID).Scan(&hostID, &status, &participants)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "Room not found")
+		} else {
+			utils.InternalErrorResponse(c, "Database error")
+		}
+		return
+	}
+
+	if hostID != userID {
+		utils.ForbiddenResponse(c, "Only room host can open check-in")
+		return
+	}
+
+	if status != models.RoomStatusWaiting {
+		utils.BadRequestResponse(c, "Room is not in waiting status")
+		return
+	}
+
+	if participants < 2 {
+		utils.BadRequestResponse(c, "Need at least 2 participants to open check-in")
+		return
+	}
+
+	deadline := time.Now().Add(time.Duration(req.DurationMinutes) * time.Minute)
+
+	tx, err := h.DB.Beginx()
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE rooms SET status = 'check_in', checkin_deadline = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`, deadline, roomID)
+
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to open check-in")
+		return
+	}
+
+	// Предыдущие подтверждения не действуют, хост подтверждает участие сразу
+	_, err = tx.Exec(`
+		UPDATE room_participants
+		SET checked_in_at = CASE WHEN user_id = $2 THEN CURRENT_TIMESTAMP END
+		WHERE room_id = $1
+	`, roomID, hostID)
+
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to reset check-in")
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		utils.InternalErrorResponse(c, "Failed to commit transaction")
+		return
+	}
+
+	h.systemMessage(roomID, fmt.Sprintf("Check-in is open for %d minutes. Confirm your participation before the tournament starts.", req.DurationMinutes))
+
+	checkIn, err := h.broadcastCheckIn(roomID, "check_in_opened")
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to get check-in status")
+		return
+	}
+
+	utils.SuccessResponse(c, checkIn)
+}
+
+// CheckIn подтверждение участия в турнире
+func (h *CheckInHandlers) CheckIn(c *gin.Context) {
+	roomID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid room ID")
+		return
+	}
+
+	checkIn, err := h.checkIn(roomID, c.GetInt("user_id"))
+	if err != nil {
+		switch {
+		case err == sql.ErrNoRows:
+			utils.NotFoundResponse(c, "Room not found")
+		case errors.Is(err, errCheckInClosed):
+			utils.BadRequestResponse(c, err.Error())
+		case errors.Is(err, errNotRoomParticipant):
+			utils.ForbiddenResponse(c, err.Error())
+		case errors.Is(err, errAlreadyCheckedIn):
+			utils.ConflictResponse(c, err.Error())
+		default:
+			utils.InternalErrorResponse(c, "Failed to check in")
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, checkIn)
+}
+
+// HandleCheckInMessage подтверждение участия через WebSocket: {"type": "check_in", "data": {"room_id": 1}}
+func (h *CheckInHandlers) HandleCheckInMessage(client *websocket.Client, data interface{}) (interface{}, error) {
+	dataMap, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid check-in data")
+	}
+
+	roomID, ok := dataMap["room_id"].(float64)
+	if !ok {
+		return nil, errors.New("room_id is required")
+	}
+
+	checkIn, err := h.checkIn(int(roomID), client.UserID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("room not found")
+		}
+		if errors.Is(err, errCheckInClosed) || errors.Is(err, errNotRoomParticipant) || errors.Is(err, errAlreadyCheckedIn) {
+			return nil, err
+		}
+		h.Logger.Error("Failed to check in", "room_id", int(roomID), "user_id", client.UserID, "error", err)
+		return nil, errors.New("failed to check in")
+	}
+
+	return checkIn, nil
+}
+
+// CloseCheckIn досрочное закрытие check-in (только хост)
+func (h *CheckInHandlers) CloseCheckIn(c *gin.Context) {
+	roomID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid room ID")
+		return
+	}
+
+	var hostID int
+	err = h.DB.Get(&hostID, `SELECT host_id FROM rooms WHERE id = $1`, roomID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "Room not found")
+		} else {
+			utils.InternalErrorResponse(c, "Database error")
+		}
+		return
+	}
+
+	if hostID != c.GetInt("user_id") {
+		utils.ForbiddenResponse(c, "Only room host can close check-in")
+		return
+	}
+
+	removed, err := h.closeCheckIn(roomID)
+	if err != nil {
+		if errors.Is(err, errCheckInClosed) {
+			utils.BadRequestResponse(c, err.Error())
+		} else {
+			utils.InternalErrorResponse(c, "Failed to close check-in")
+		}
+		return
+	}
+
+	checkIn, err := h.getCheckInStatus(h.DB, roomID)
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to get check-in status")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{
+		"check_in": checkIn,
+		"removed":  removed,
+	})
+}
+
+// GetCheckInStatus получение состояния check-in комнаты
+func (h *CheckInHandlers) GetCheckInStatus(c *gin.Context) {
+	roomID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid room ID")
+		return
+	}
+
+	checkIn, err := h.getCheckInStatus(h.DB, roomID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "Room not found")
+		} else {
+			utils.InternalErrorResponse(c, "Failed to get check-in status")
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, checkIn)
+}
+
+// CloseExpiredCheckIns закрывает окна check-in с истекшим сроком.
+// Вызывается фоновой задачей.
+func (h *CheckInHandlers) CloseExpiredCheckIns() error {
+	var roomIDs []int
+	err := h.DB.Select(&roomIDs, `
+		SELECT id FROM rooms
+		WHERE status = 'check_in' AND checkin_deadline <= CURRENT_TIMESTAMP
+	`)
+	if err != nil {
+		return err
+	}
+
+	for _, roomID := range roomIDs {
+		if _, err := h.closeCheckIn(roomID); err != nil && !errors.Is(err, errCheckInClosed) {
+			h.Logger.Error("Failed to close check-in", "room_id", roomID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// checkIn отмечает участника комнаты и рассылает обновленное состояние
+func (h *CheckInHandlers) checkIn(roomID, userID int) (*CheckInStatus, error) {
+	var status string
+	var deadline sql.NullTime
+	err := h.DB.QueryRow(`SELECT status, checkin_deadline FROM rooms WHERE id = $1`, roomID).Scan(&status, &deadline)
+	if err != nil {
+		return nil, err
+	}
+
+	if status != models.RoomStatusCheckIn || !deadline.Valid || time.Now().After(deadline.Time) {
+		return nil, errCheckInClosed
+	}
+
+	var checkedInAt sql.NullTime
+	err = h.DB.Get(&checkedInAt, `
+		SELECT checked_in_at FROM room_participants WHERE room_id = $1 AND user_id = $2
+	`, roomID, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errNotRoomParticipant
+		}
+		return nil, err
+	}
+
+	if checkedInAt.Valid {
+		return nil, errAlreadyCheckedIn
+	}
+
+	_, err = h.DB.Exec(`
+		UPDATE room_participants SET checked_in_at = CURRENT_TIMESTAMP
+		WHERE room_id = $1 AND user_id = $2
+	`, roomID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.broadcastCheckIn(roomID, "player_checked_in")
+}
+
+// closeCheckIn закрывает окно check-in: не подтвердившие участие удаляются из
+// комнаты, комната возвращается в ожидание запуска турнира
+func (h *CheckInHandlers) closeCheckIn(roomID int) ([]models.User, error) {
+	tx, err := h.DB.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var status string
+	if err = tx.Get(&status, `SELECT status FROM rooms WHERE id = $1 FOR UPDATE`, roomID); err != nil {
+		return nil, err
+	}
+
+	if status != models.RoomStatusCheckIn {
+		return nil, errCheckInClosed
+	}
+
+	var removed []models.User
+	err = tx.Select(&removed, `
+		DELETE FROM room_participants rp
+		USING users u
+		WHERE rp.room_id = $1 AND rp.checked_in_at IS NULL AND u.id = rp.user_id
+		RETURNING u.id, u.username
+	`, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE rooms
+		SET status = 'waiting', current_count = current_count - $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`, len(removed), roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	message := "Check-in is closed. All participants confirmed."
+	if len(removed) > 0 {
+		names := make([]string, len(removed))
+		for i, user := range removed {
+			names[i] = user.Username
+		}
+		message = fmt.Sprintf("Check-in is closed. Removed for not checking in: %s.", strings.Join(names, ", "))
+	}
+	h.systemMessage(roomID, message)
+
+	if _, err := h.broadcastCheckIn(roomID, "check_in_closed"); err != nil {
+		h.Logger.Error("Failed to broadcast check-in status", "room_id", roomID, "error", err)
+	}
+
+	return removed, nil
+}
+
+// getCheckInStatus собирает состояние check-in комнаты
+func (h *CheckInHandlers) getCheckInStatus(q sqlx.Queryer, roomID int) (*CheckInStatus, error) {
+	var status string
+	var deadline sql.NullTime
+	err := q.QueryRowx(`SELECT status, checkin_deadline FROM rooms WHERE id = $1`, roomID).Scan(&status, &deadline)
+	if err != nil {
+		return nil, err
+	}
+
+	checkIn := &CheckInStatus{
+		RoomID:    roomID,
+		IsOpen:    status == models.RoomStatusCheckIn,
+		CheckedIn: []models.User{},
+		Pending:   []models.User{},
+	}
+
+	if deadline.Valid {
+		checkIn.Deadline = &deadline.Time
+		if checkIn.IsOpen {
+			if left := time.Until(deadline.Time); left > 0 {
+				checkIn.SecondsLeft = int(left.Seconds())
+			}
+		}
+	}
+
+	var participants []struct {
+		models.User
+		CheckedIn bool `db:"checked_in"`
+	}
+	err = sqlx.Select(q, &participants, `
+		SELECT u.id, u.username, u.rating, rp.checked_in_at IS NOT NULL as checked_in
+		FROM room_participants rp
+		JOIN users u ON rp.user_id = u.id
+		WHERE rp.room_id = $1
+		ORDER BY rp.joined_at
+	`, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, participant := range participants {
+		if participant.CheckedIn {
+			checkIn.CheckedIn = append(checkIn.CheckedIn, participant.User)
+		} else {
+			checkIn.Pending = append(checkIn.Pending, participant.User)
+		}
+	}
+
+	return checkIn, nil
+}
+
+// broadcastCheckIn отправляет в комнату tournament_update с состоянием check-in
+func (h *CheckInHandlers) broadcastCheckIn(roomID int, action string) (*CheckInStatus, error) {
+	checkIn, err := h.getCheckInStatus(h.DB, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	wsMsg := models.WSMessage{
+		Type: models.WSTypeTournamentUpdate,
+		Data: gin.H{
+			"room_id":  roomID,
+			"stage":    "check_in",
+			"action":   action,
+			"check_in": checkIn,
+		},
+	}
+	msgBytes, _ := json.Marshal(wsMsg)
+	h.Hub.BroadcastToRoom(roomID, msgBytes)
+
+	return checkIn, nil
+}
+
+// systemMessage отправляет системное сообщение в чат комнаты
+func (h *CheckInHandlers) systemMessage(roomID int, content string) {
+	if err := h.Chat.SendSystemMessage(roomID, content, "system"); err != nil {
+		h.Logger.Error("Failed to send system message", "room_id", roomID, "error", err)
+	}
+}
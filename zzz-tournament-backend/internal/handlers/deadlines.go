@@ -0,0 +1,345 @@
+// internal/handlers/deadlines.go
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"zzz-tournament/internal/models"
+	"zzz-tournament/pkg/config"
+	"zzz-tournament/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// MaxMatchDeadlineMinutes максимальное время на матч (7 дней)
+const MaxMatchDeadlineMinutes = 7 * 24 * 60
+
+// MarkMatchReady отметка готовности участника к матчу. Учитывается правилом
+// технической победы ready при истечении срока матча и начинает драфт героев.
+func (h *TournamentHandlers) MarkMatchReady(c *gin.Context) {
+	tournamentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid tournament ID")
+		return
+	}
+
+	matchID, err := strconv.Atoi(c.Param("match_id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid match ID")
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	match, roomID, _, err := h.getMatchForResult(tournamentID, matchID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "Match not found")
+		} else {
+			utils.InternalErrorResponse(c, "Database error")
+		}
+		return
+	}
+
+	if !match.IsParticipant(userID) {
+		utils.ForbiddenResponse(c, "Only match participants can report ready")
+		return
+	}
+
+	if match.Status != models.MatchStatusPending && match.Status != models.MatchStatusInProgress {
+		utils.BadRequestResponse(c, "Match is already completed")
+		return
+	}
+
+	column := "player1_ready_at"
+	if userID == match.Player2ID {
+		column = "player2_ready_at"
+	}
+
+	_, err = h.DB.Exec(`
+		UPDATE matches SET `+column+` = COALESCE(`+column+`, CURRENT_TIMESTAMP), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, matchID)
+
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to update match")
+		return
+	}
+
+	wsMsg := models.WSMessage{
+		Type: "match_player_ready",
+		Data: gin.H{
+			"room_id":       roomID,
+			"tournament_id": tournamentID,
+			"match_id":      matchID,
+			"user_id":       userID,
+		},
+	}
+	msgBytes, _ := json.Marshal(wsMsg)
+	h.Hub.BroadcastToRoom(roomID, msgBytes)
+
+	// Когда готовы оба игрока, начинается драфт героев
+	draft, err := h.startMatchDraft(matchID)
+	if err != nil {
+		h.Logger.Error("Failed to start hero draft", "match_id", matchID, "error", err)
+	}
+
+	utils.SuccessResponse(c, gin.H{
+		"message":  "Ready status recorded",
+		"match_id": matchID,
+		"draft":    draft,
+	})
+}
+
+// ProcessMatchDeadlines предупреждает игроков о скором истечении срока матчей и
+// присуждает техническую победу в просроченных матчах либо передает решение хосту.
+// Вызывается фоновой задачей.
+func (h *TournamentHandlers) ProcessMatchDeadlines() error {
+	if err := h.warnMatchDeadlines(); err != nil {
+		return err
+	}
+
+	var expired []int
+	err := h.DB.Select(&expired, `
+		SELECT m.id
+		FROM matches m
+		JOIN tournaments t ON m.tournament_id = t.id
+		WHERE m.status IN ('pending', 'in_progress') AND m.deadline <= CURRENT_TIMESTAMP
+		  AND m.deadline_expired_at IS NULL
+		  AND m.player1_id IS NOT NULL AND m.player2_id IS NOT NULL
+		  AND t.status = 'started'
+		ORDER BY m.deadline
+	`)
+	if err != nil {
+		return err
+	}
+
+	for _, matchID := range expired {
+		if err := h.awardWalkover(matchID); err != nil {
+			h.Logger.Error("Failed to award walkover", "match_id", matchID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// setMatchDeadlines назначает срок матчам турнира, в которых известны оба игрока
+func (h *TournamentHandlers) setMatchDeadlines(tx *sqlx.Tx, tournamentID int) error {
+	_, err := tx.Exec(`
+		UPDATE matches m
+		SET deadline = CURRENT_TIMESTAMP + t.match_deadline_minutes * INTERVAL '1 minute'
+		FROM tournaments t
+		WHERE m.tournament_id = t.id AND t.id = $1 AND t.match_deadline_minutes > 0
+		  AND m.status = 'pending' AND m.deadline IS NULL
+		  AND m.player1_id IS NOT NULL AND m.player2_id IS NOT NULL
+	`, tournamentID)
+	return err
+}
+
+// warnMatchDeadlines отправляет предупреждение участникам матчей, срок которых скоро истекает
+func (h *TournamentHandlers) warnMatchDeadlines() error {
+	var matches []struct {
+		ID           int       `db:"id"`
+		TournamentID int       `db:"tournament_id"`
+		RoomID       int       `db:"room_id"`
+		Player1ID    int       `db:"player1_id"`
+		Player2ID    int       `db:"player2_id"`
+		Deadline     time.Time `db:"deadline"`
+	}
+
+	err := h.DB.Select(&matches, `
+		UPDATE matches m
+		SET deadline_warned_at = CURRENT_TIMESTAMP
+		FROM tournaments t
+		WHERE m.tournament_id = t.id AND t.status = 'started'
+		  AND m.status IN ('pending', 'in_progress') AND m.deadline_warned_at IS NULL
+		  AND m.deadline > CURRENT_TIMESTAMP AND m.deadline <= CURRENT_TIMESTAMP + $1 * INTERVAL '1 second'
+		  AND m.player1_id IS NOT NULL AND m.player2_id IS NOT NULL
+		RETURNING m.id, m.tournament_id, t.room_id, m.player1_id, m.player2_id, m.deadline
+	`, int(h.Config.MatchDeadlineWarning.Seconds()))
+	if err != nil {
+		return err
+	}
+
+	for _, match := range matches {
+		wsMsg := models.WSMessage{
+			Type: "match_deadline_warning",
+			Data: gin.H{
+				"room_id":       match.RoomID,
+				"tournament_id": match.TournamentID,
+				"match_id":      match.ID,
+				"deadline":      match.Deadline,
+				"seconds_left":  int(time.Until(match.Deadline).Seconds()),
+			},
+		}
+		msgBytes, _ := json.Marshal(wsMsg)
+
+		h.Hub.BroadcastToRoom(match.RoomID, msgBytes)
+		h.Hub.SendToUser(match.Player1ID, msgBytes)
+		h.Hub.SendToUser(match.Player2ID, msgBytes)
+	}
+
+	return nil
+}
+
+// awardWalkover присуждает техническую победу в просроченном матче по правилу турнира.
+// Если игры уже записаны, есть отчет участника или правило не определяет победителя,
+// матч остается открытым, а хост уведомляется об истечении срока.
+func (h *TournamentHandlers) awardWalkover(matchID int) error {
+	tx, err := h.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var row struct {
+		models.Match
+		RoomID         int    `db:"room_id"`
+		HostID         int    `db:"host_id"`
+		WalkoverRule   string `db:"walkover_rule"`
+		Player1Rating  int    `db:"player1_rating"`
+		Player2Rating  int    `db:"player2_rating"`
+		DeadlinePassed bool   `db:"deadline_passed"`
+		Expired        bool   `db:"expired"`
+		HasReports     bool   `db:"has_reports"`
+	}
+
+	err = tx.Get(&row, `
+		SELECT m.id, m.tournament_id, m.round, m.player1_id, m.player2_id, m.status, m.bracket, m.best_of,
+		       m.draft_status, m.player1_ready_at, m.player2_ready_at, t.room_id, r.host_id, t.walkover_rule,
+		       COALESCE(tm1.rating, p1.rating) as player1_rating, COALESCE(tm2.rating, p2.rating) as player2_rating,
+		       m.deadline <= CURRENT_TIMESTAMP as deadline_passed,
+		       m.deadline_expired_at IS NOT NULL as expired,
+		       EXISTS (SELECT 1 FROM match_reports mr WHERE mr.match_id = m.id) as has_reports
+		FROM matches m
+		JOIN tournaments t ON m.tournament_id = t.id
+		JOIN rooms r ON t.room_id = r.id
+		JOIN users p1 ON m.player1_id = p1.id
+		JOIN users p2 ON m.player2_id = p2.id
+		LEFT JOIN teams tm1 ON m.team1_id = tm1.id
+		LEFT JOIN teams tm2 ON m.team2_id = tm2.id
+		WHERE m.id = $1
+		FOR UPDATE OF m
+	`, matchID)
+	if err != nil {
+		return err
+	}
+
+	// Результат мог прийти, пока матч ожидал блокировки
+	if !row.DeadlinePassed || row.Expired ||
+		(row.Status != models.MatchStatusPending && row.Status != models.MatchStatusInProgress) {
+		return nil
+	}
+
+	match := row.Match
+
+	// Начатую серию и заявленный результат решает хост, а не правило срока
+	reason := ""
+	winnerID := 0
+	switch {
+	case row.Status == models.MatchStatusInProgress:
+		reason = "games_recorded"
+	case row.HasReports:
+		reason = "result_reported"
+	default:
+		winnerID = walkoverWinner(row.WalkoverRule, match, row.Player1Rating, row.Player2Rating)
+		if winnerID == 0 {
+			reason = "no_walkover"
+		}
+	}
+
+	if winnerID == 0 {
+		return h.expireMatchDeadline(tx, match, row.RoomID, row.HostID, reason)
+	}
+
+	match.IsWalkover = true
+	outcome, err := h.finishMatch(tx, match, row.RoomID, winnerID)
+	if err != nil {
+		return fmt.Errorf("finish match: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	h.Logger.Info("Walkover awarded",
+		"match_id", matchID,
+		"winner_id", winnerID,
+		"rule", row.WalkoverRule,
+	)
+
+	h.notifyMatchFinished(match, row.RoomID, outcome, nil)
+
+	return nil
+}
+
+// expireMatchDeadline отмечает просроченный матч без технической победы и
+// уведомляет хоста и участников. Повторно матч фоновой задачей не обрабатывается.
+func (h *TournamentHandlers) expireMatchDeadline(tx *sqlx.Tx, match models.Match, roomID, hostID int, reason string) error {
+	_, err := tx.Exec(`
+		UPDATE matches SET deadline_expired_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, match.ID)
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	h.Logger.Info("Match deadline expired without walkover",
+		"match_id", match.ID,
+		"reason", reason,
+	)
+
+	wsMsg := models.WSMessage{
+		Type: "match_deadline_expired",
+		Data: gin.H{
+			"room_id":       roomID,
+			"tournament_id": match.TournamentID,
+			"match_id":      match.ID,
+			"reason":        reason,
+		},
+	}
+	msgBytes, _ := json.Marshal(wsMsg)
+
+	h.Hub.BroadcastToRoom(roomID, msgBytes)
+	h.Hub.SendToUser(hostID, msgBytes)
+	h.Hub.SendToUser(match.Player1ID, msgBytes)
+	h.Hub.SendToUser(match.Player2ID, msgBytes)
+
+	return nil
+}
+
+// walkoverWinner определяет победителя просроченного матча. По правилу ready
+// побеждает единственный отметившийся готовым игрок; если готовы оба или никто,
+// победитель не определяется. По правилу rating побеждает игрок с большим
+// рейтингом (при равенстве - первый игрок, то есть более высокий посев).
+// 0 - техническая победа не присуждается.
+func walkoverWinner(rule string, match models.Match, player1Rating, player2Rating int) int {
+	switch rule {
+	case config.WalkoverRuleReady:
+		player1Ready := match.Player1ReadyAt != nil
+		player2Ready := match.Player2ReadyAt != nil
+		if player1Ready && !player2Ready {
+			return match.Player1ID
+		}
+		if player2Ready && !player1Ready {
+			return match.Player2ID
+		}
+		return 0
+	case config.WalkoverRuleRating:
+		if player2Rating > player1Rating {
+			return match.Player2ID
+		}
+		return match.Player1ID
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,579 @@
+// internal/handlers/draft.go
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"zzz-tournament/internal/models"
+	"zzz-tournament/internal/websocket"
+	"zzz-tournament/pkg/config"
+	"zzz-tournament/pkg/tournament"
+	"zzz-tournament/pkg/utils"
+	"zzz-tournament/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Ограничения времени на ход драфта в секундах
+const (
+	MinDraftTurnSeconds = 10
+	MaxDraftTurnSeconds = 300
+)
+
+// Ошибки драфта героев
+var (
+	errDraftNotActive   = errors.New("hero draft is not in progress")
+	errNotYourDraftTurn = errors.New("it is not your turn in the draft")
+	errHeroUnavailable  = errors.New("hero is not available: inactive, banned or already picked")
+)
+
+// DraftActionRequest структура запроса бана или пика героя
+type DraftActionRequest struct {
+	HeroID int `json:"hero_id" binding:"required"`
+}
+
+// DraftState текущее состояние драфта матча
+type DraftState struct {
+	MatchID         int                    `json:"match_id"`
+	TournamentID    int                    `json:"tournament_id"`
+	RoomID          int                    `json:"room_id"`
+	Status          string                 `json:"status"` // none, in_progress, completed
+	Format          tournament.DraftFormat `json:"format"`
+	TimeoutRule     string                 `json:"timeout_rule"`
+	Player1ID       int                    `json:"player1_id"`
+	Player2ID       int                    `json:"player2_id"`
+	CurrentTurn     *tournament.DraftTurn  `json:"current_turn,omitempty"`
+	CurrentPlayerID int                    `json:"current_player_id,omitempty"`
+	TurnDeadline    *time.Time             `json:"turn_deadline,omitempty"`
+	SecondsLeft     int                    `json:"seconds_left"`
+	Turns           []models.MatchHeroPick `json:"turns"`
+}
+
+// draftMatch матч с настройками драфта турнира
+type draftMatch struct {
+	ID                int        `db:"id"`
+	TournamentID      int        `db:"tournament_id"`
+	RoomID            int        `db:"room_id"`
+	Player1ID         int        `db:"player1_id"`
+	Player2ID         int        `db:"player2_id"`
+	Status            string     `db:"status"`
+	DraftStatus       string     `db:"draft_status"`
+	DraftTurn         int        `db:"draft_turn"`
+	DraftTurnDeadline *time.Time `db:"draft_turn_deadline"`
+	DraftBans         int        `db:"draft_bans"`
+	DraftPicks        int        `db:"draft_picks"`
+	DraftTurnSeconds  int        `db:"draft_turn_seconds"`
+	DraftTimeoutRule  string     `db:"draft_timeout_rule"`
+	SecondsLeft       int        `db:"seconds_left"`
+	TurnExpired       bool       `db:"turn_expired"`
+
+	Ruleset *models.Ruleset `db:"ruleset"`
+}
+
+// draftMatchQuery выборка матча для драфта, к которой добавляется условие
+const draftMatchQuery = `
+	SELECT m.id, m.tournament_id, t.room_id,
+	       COALESCE(m.player1_id, 0) as player1_id, COALESCE(m.player2_id, 0) as player2_id,
+	       m.status, m.draft_status, m.draft_turn, m.draft_turn_deadline,
+	       t.draft_bans, t.draft_picks, t.draft_turn_seconds, t.draft_timeout_rule, t.ruleset,
+	       COALESCE(GREATEST(EXTRACT(EPOCH FROM m.draft_turn_deadline - CURRENT_TIMESTAMP), 0), 0)::int as seconds_left,
+	       COALESCE(m.draft_turn_deadline <= CURRENT_TIMESTAMP, false) as turn_expired
+	FROM matches m
+	JOIN tournaments t ON m.tournament_id = t.id
+	WHERE m.id = $1`
+
+// format возвращает формат драфта турнира
+func (m draftMatch) format() tournament.DraftFormat {
+	return tournament.DraftFormat{Bans: m.DraftBans, Picks: m.DraftPicks}
+}
+
+// currentTurn возвращает текущий ход драфта и игрока, который его делает
+func (m draftMatch) currentTurn() (tournament.DraftTurn, int, bool) {
+	sequence := m.format().Sequence()
+	if m.DraftStatus != models.DraftStatusInProgress || m.DraftTurn < 1 || m.DraftTurn > len(sequence) {
+		return tournament.DraftTurn{}, 0, false
+	}
+
+	turn := sequence[m.DraftTurn-1]
+	if turn.Slot == 0 {
+		return turn, m.Player1ID, true
+	}
+	return turn, m.Player2ID, true
+}
+
+// GetMatchDraft получение состояния драфта матча
+func (h *TournamentHandlers) GetMatchDraft(c *gin.Context) {
+	tournamentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid tournament ID")
+		return
+	}
+
+	matchID, err := strconv.Atoi(c.Param("match_id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid match ID")
+		return
+	}
+
+	draft, err := h.getDraftState(h.DB, matchID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "Match not found")
+		} else {
+			utils.InternalErrorResponse(c, "Failed to get draft")
+		}
+		return
+	}
+
+	if draft.TournamentID != tournamentID {
+		utils.NotFoundResponse(c, "Match not found")
+		return
+	}
+
+	utils.SuccessResponse(c, draft)
+}
+
+// SubmitDraftAction бан или пик героя в свой ход драфта
+func (h *TournamentHandlers) SubmitDraftAction(c *gin.Context) {
+	tournamentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid tournament ID")
+		return
+	}
+
+	matchID, err := strconv.Atoi(c.Param("match_id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid match ID")
+		return
+	}
+
+	var req DraftActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	draft, err := h.draftAction(tournamentID, matchID, c.GetInt("user_id"), req.HeroID)
+	if err != nil {
+		var errs validator.ValidationErrors
+		switch {
+		case err == sql.ErrNoRows:
+			utils.NotFoundResponse(c, "Match not found")
+		case errors.Is(err, errDraftNotActive):
+			utils.BadRequestResponse(c, err.Error())
+		case errors.Is(err, errNotYourDraftTurn):
+			utils.ForbiddenResponse(c, err.Error())
+		case errors.Is(err, errHeroUnavailable):
+			utils.ConflictResponse(c, err.Error())
+		case errors.As(err, &errs):
+			utils.BadRequestResponse(c, errs.Error(), validationDetails(errs)...)
+		default:
+			utils.InternalErrorResponse(c, "Failed to submit draft action")
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, draft)
+}
+
+// HandleDraftMessage бан или пик через WebSocket:
+// {"type": "draft_action", "data": {"tournament_id": 1, "match_id": 2, "hero_id": 3}}
+func (h *TournamentHandlers) HandleDraftMessage(client *websocket.Client, data interface{}) (interface{}, error) {
+	dataMap, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid draft data")
+	}
+
+	tournamentID, ok := dataMap["tournament_id"].(float64)
+	if !ok {
+		return nil, errors.New("tournament_id is required")
+	}
+
+	matchID, ok := dataMap["match_id"].(float64)
+	if !ok {
+		return nil, errors.New("match_id is required")
+	}
+
+	heroID, ok := dataMap["hero_id"].(float64)
+	if !ok || heroID < 1 {
+		return nil, errors.New("hero_id is required")
+	}
+
+	draft, err := h.draftAction(int(tournamentID), int(matchID), client.UserID, int(heroID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("match not found")
+		}
+		var errs validator.ValidationErrors
+		if errors.Is(err, errDraftNotActive) || errors.Is(err, errNotYourDraftTurn) ||
+			errors.Is(err, errHeroUnavailable) || errors.As(err, &errs) {
+			return nil, err
+		}
+		h.Logger.Error("Failed to submit draft action", "match_id", int(matchID), "user_id", client.UserID, "error", err)
+		return nil, errors.New("failed to submit draft action")
+	}
+
+	return draft, nil
+}
+
+// ProcessDraftTimeouts завершает ходы драфта с истекшим временем по правилу
+// турнира. Вызывается фоновой задачей.
+func (h *TournamentHandlers) ProcessDraftTimeouts() error {
+	var matchIDs []int
+	err := h.DB.Select(&matchIDs, `
+		SELECT id FROM matches
+		WHERE draft_status = 'in_progress' AND draft_turn_deadline <= CURRENT_TIMESTAMP
+		  AND status IN ('pending', 'in_progress')
+		ORDER BY draft_turn_deadline
+	`)
+	if err != nil {
+		return err
+	}
+
+	for _, matchID := range matchIDs {
+		if err := h.timeoutDraftTurn(matchID); err != nil {
+			h.Logger.Error("Failed to process draft timeout", "match_id", matchID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// startMatchDraft начинает драфт, когда оба игрока отметились готовыми.
+// Возвращает nil, если драфт в турнире не проводится или уже начат.
+func (h *TournamentHandlers) startMatchDraft(matchID int) (*DraftState, error) {
+	result, err := h.DB.Exec(`
+		UPDATE matches m
+		SET draft_status = 'in_progress', draft_turn = 1,
+		    draft_turn_deadline = CURRENT_TIMESTAMP + t.draft_turn_seconds * INTERVAL '1 second',
+		    updated_at = CURRENT_TIMESTAMP
+		FROM tournaments t
+		WHERE m.tournament_id = t.id AND m.id = $1 AND t.draft_picks > 0
+		  AND m.draft_status = 'none' AND m.status IN ('pending', 'in_progress')
+		  AND m.player1_ready_at IS NOT NULL AND m.player2_ready_at IS NOT NULL
+	`, matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, nil
+	}
+
+	return h.broadcastDraft(matchID, "draft_started")
+}
+
+// draftAction выполняет ход игрока в драфте
+func (h *TournamentHandlers) draftAction(tournamentID, matchID, userID, heroID int) (*DraftState, error) {
+	tx, err := h.DB.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var match draftMatch
+	err = tx.Get(&match, draftMatchQuery+` AND m.tournament_id = $2 FOR UPDATE OF m`, matchID, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if match.Status != models.MatchStatusPending && match.Status != models.MatchStatusInProgress {
+		return nil, errDraftNotActive
+	}
+
+	turn, playerID, ok := match.currentTurn()
+	if !ok {
+		return nil, errDraftNotActive
+	}
+
+	if playerID != userID {
+		return nil, errNotYourDraftTurn
+	}
+
+	var available bool
+	err = tx.Get(&available, `
+		SELECT EXISTS (
+			SELECT 1 FROM heroes h
+			WHERE h.id = $1 AND h.is_active = true
+			  AND NOT EXISTS (SELECT 1 FROM match_hero_picks WHERE match_id = $2 AND hero_id = h.id)
+		)
+	`, heroID, matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !available {
+		return nil, errHeroUnavailable
+	}
+
+	if match.Ruleset != nil {
+		heroes, err := h.getHeroes(tx, pq.Int64Array{int64(heroID)})
+		if err != nil {
+			return nil, err
+		}
+
+		errs, err := h.checkDraftRuleset(tx, match, turn, playerID, heroes[heroID])
+		if err != nil {
+			return nil, err
+		}
+		if errs.HasErrors() {
+			return nil, errs
+		}
+	}
+
+	if err = h.recordDraftTurn(tx, match, turn, playerID, heroID, false); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return h.broadcastDraft(matchID, draftTurnAction(match, turn, heroID))
+}
+
+// timeoutDraftTurn завершает просроченный ход: по правилу auto_pick вместо пика
+// выбирается случайный доступный герой, бан и ход по правилу skip пропускаются
+func (h *TournamentHandlers) timeoutDraftTurn(matchID int) error {
+	tx, err := h.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var match draftMatch
+	if err = tx.Get(&match, draftMatchQuery+` FOR UPDATE OF m`, matchID); err != nil {
+		return err
+	}
+
+	// Ход мог быть сделан, а матч завершен или оспорен, пока матч ожидал блокировки
+	if !match.TurnExpired ||
+		(match.Status != models.MatchStatusPending && match.Status != models.MatchStatusInProgress) {
+		return nil
+	}
+
+	turn, playerID, ok := match.currentTurn()
+	if !ok {
+		return nil
+	}
+
+	heroID := 0
+	if turn.Action == tournament.DraftActionPick && match.DraftTimeoutRule == config.DraftTimeoutAutoPick {
+		var candidates []models.Hero
+		err = tx.Select(&candidates, `
+			SELECT h.id, h.name, h.element, h.rarity, h.role, h.is_active FROM heroes h
+			WHERE h.is_active = true
+			  AND NOT EXISTS (SELECT 1 FROM match_hero_picks WHERE match_id = $1 AND hero_id = h.id)
+			ORDER BY RANDOM()
+		`, matchID)
+		if err != nil {
+			return err
+		}
+
+		// Выбирается первый случайный герой, допустимый по правилам турнира
+		for _, hero := range candidates {
+			if match.Ruleset != nil {
+				errs, err := h.checkDraftRuleset(tx, match, turn, playerID, hero)
+				if err != nil {
+					return err
+				}
+				if errs.HasErrors() {
+					continue
+				}
+			}
+			heroID = hero.ID
+			break
+		}
+	}
+
+	if err = h.recordDraftTurn(tx, match, turn, playerID, heroID, true); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	_, err = h.broadcastDraft(matchID, draftTurnAction(match, turn, heroID))
+	return err
+}
+
+// recordDraftTurn сохраняет ход и переводит драфт к следующему ходу или завершает его
+func (h *TournamentHandlers) recordDraftTurn(tx *sqlx.Tx, match draftMatch, turn tournament.DraftTurn, playerID, heroID int, auto bool) error {
+	var hero *int
+	if heroID > 0 {
+		hero = &heroID
+	}
+
+	_, err := tx.Exec(`
+		INSERT INTO match_hero_picks (match_id, turn, user_id, hero_id, action, is_auto)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, match.ID, turn.Number, playerID, hero, turn.Action, auto)
+	if err != nil {
+		return err
+	}
+
+	if turn.Number == len(match.format().Sequence()) {
+		_, err = tx.Exec(`
+			UPDATE matches
+			SET draft_status = 'completed', draft_turn_deadline = NULL, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+		`, match.ID)
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE matches
+		SET draft_turn = draft_turn + 1,
+		    draft_turn_deadline = CURRENT_TIMESTAMP + $2 * INTERVAL '1 second',
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, match.ID, match.DraftTurnSeconds)
+	return err
+}
+
+// checkDraftRuleset проверяет героя по правилам турнира. Для пика лимиты
+// считаются вместе с уже выбранными игроком героями, если пики драфта и
+// составляют команду (их не больше размера команды).
+func (h *TournamentHandlers) checkDraftRuleset(tx *sqlx.Tx, match draftMatch, turn tournament.DraftTurn, playerID int, hero models.Hero) (validator.ValidationErrors, error) {
+	team := []models.Hero{hero}
+
+	if turn.Action == tournament.DraftActionPick &&
+		(match.Ruleset.TeamSize == 0 || match.DraftPicks <= match.Ruleset.TeamSize) {
+		var picked []models.Hero
+		err := tx.Select(&picked, `
+			SELECT h.id, h.name, h.element, h.rarity, h.role, h.is_active
+			FROM match_hero_picks hp
+			JOIN heroes h ON hp.hero_id = h.id
+			WHERE hp.match_id = $1 AND hp.user_id = $2 AND hp.action = 'pick'
+			ORDER BY hp.turn
+		`, match.ID, playerID)
+		if err != nil {
+			return nil, err
+		}
+		team = append(picked, hero)
+	}
+
+	return match.Ruleset.ValidatePicks("picks", team), nil
+}
+
+// draftTurnAction возвращает название события для сделанного хода
+func draftTurnAction(match draftMatch, turn tournament.DraftTurn, heroID int) string {
+	switch {
+	case turn.Number == len(match.format().Sequence()):
+		return "draft_completed"
+	case heroID == 0:
+		return "turn_skipped"
+	case turn.Action == tournament.DraftActionBan:
+		return "hero_banned"
+	default:
+		return "hero_picked"
+	}
+}
+
+// getDraftState собирает состояние драфта матча
+func (h *TournamentHandlers) getDraftState(q sqlx.Queryer, matchID int) (*DraftState, error) {
+	var match draftMatch
+	if err := sqlx.Get(q, &match, draftMatchQuery, matchID); err != nil {
+		return nil, err
+	}
+
+	draft := &DraftState{
+		MatchID:      match.ID,
+		TournamentID: match.TournamentID,
+		RoomID:       match.RoomID,
+		Status:       match.DraftStatus,
+		Format:       match.format(),
+		TimeoutRule:  match.DraftTimeoutRule,
+		Player1ID:    match.Player1ID,
+		Player2ID:    match.Player2ID,
+		Turns:        []models.MatchHeroPick{},
+	}
+
+	if turn, playerID, ok := match.currentTurn(); ok {
+		draft.CurrentTurn = &turn
+		draft.CurrentPlayerID = playerID
+		draft.TurnDeadline = match.DraftTurnDeadline
+		draft.SecondsLeft = match.SecondsLeft
+	}
+
+	err := sqlx.Select(q, &draft.Turns, `
+		SELECT hp.id, hp.match_id, hp.turn, hp.user_id, hp.hero_id, COALESCE(h.name, '') as hero_name,
+		       hp.action, hp.is_auto, hp.created_at
+		FROM match_hero_picks hp
+		LEFT JOIN heroes h ON hp.hero_id = h.id
+		WHERE hp.match_id = $1
+		ORDER BY hp.turn
+	`, matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	return draft, nil
+}
+
+// broadcastDraft отправляет состояние драфта в комнату турнира
+func (h *TournamentHandlers) broadcastDraft(matchID int, action string) (*DraftState, error) {
+	draft, err := h.getDraftState(h.DB, matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	wsMsg := models.WSMessage{
+		Type: models.WSTypeTournamentUpdate,
+		Data: gin.H{
+			"room_id":       draft.RoomID,
+			"tournament_id": draft.TournamentID,
+			"match_id":      matchID,
+			"stage":         "draft",
+			"action":        action,
+			"draft":         draft,
+		},
+	}
+	msgBytes, _ := json.Marshal(wsMsg)
+	h.Hub.BroadcastToRoom(draft.RoomID, msgBytes)
+
+	return draft, nil
+}
+
+// validateDraftHeroes проверяет, что в играх матча после драфта игроки
+// использовали только своих выбранных героев
+func (h *TournamentHandlers) validateDraftHeroes(match models.Match, games []GameReport) error {
+	var picks []models.MatchHeroPick
+	err := h.DB.Select(&picks, `
+		SELECT user_id, hero_id FROM match_hero_picks
+		WHERE match_id = $1 AND action = 'pick' AND hero_id IS NOT NULL
+	`, match.ID)
+	if err != nil {
+		return err
+	}
+
+	picked := make(map[int]map[int]bool, 2)
+	for _, pick := range picks {
+		if picked[pick.UserID] == nil {
+			picked[pick.UserID] = make(map[int]bool)
+		}
+		picked[pick.UserID][*pick.HeroID] = true
+	}
+
+	for _, game := range games {
+		for _, id := range game.Player1Heroes {
+			if !picked[match.Player1ID][id] {
+				return errors.New("player 1 used a hero that was not picked in the draft")
+			}
+		}
+		for _, id := range game.Player2Heroes {
+			if !picked[match.Player2ID][id] {
+				return errors.New("player 2 used a hero that was not picked in the draft")
+			}
+		}
+	}
+
+	return nil
+}
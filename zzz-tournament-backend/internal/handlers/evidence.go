@@ -0,0 +1,434 @@
+// internal/handlers/evidence.go
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"zzz-tournament/internal/models"
+	"zzz-tournament/internal/websocket"
+	"zzz-tournament/pkg/storage"
+	"zzz-tournament/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// MaxEvidenceFiles максимальное количество скриншотов одного пользователя к матчу или заходу
+const MaxEvidenceFiles = 5
+
+// EvidenceOptions настройки загрузки подтверждений результатов
+type EvidenceOptions struct {
+	Storage       storage.Storage
+	MaxUploadSize int64         // Максимальный размер файла в байтах
+	URLTTL        time.Duration // Срок действия подписанных ссылок на файлы
+}
+
+// EvidenceHandlers обработчики скриншотов-подтверждений результатов
+type EvidenceHandlers struct {
+	BaseHandlers
+	EvidenceOptions
+}
+
+// NewEvidenceHandlers создает новый экземпляр EvidenceHandlers
+func NewEvidenceHandlers(db *sqlx.DB, hub *websocket.Hub, logger *slog.Logger, opts EvidenceOptions) *EvidenceHandlers {
+	return &EvidenceHandlers{
+		BaseHandlers:    newBaseHandlers(db, hub, logger),
+		EvidenceOptions: opts,
+	}
+}
+
+// evidenceTarget матч или заход, к которому прикладываются скриншоты
+type evidenceTarget struct {
+	TournamentID int
+	RoomID       int
+	HostID       int
+	MatchID      *int
+	RunID        *int
+	Owners       []int // Участники матча или автор захода
+	Open         bool  // Результат еще не окончательный, загрузка разрешена
+}
+
+// UploadMatchEvidence загрузка скриншота к отчету о матче (участники матча)
+func (h *EvidenceHandlers) UploadMatchEvidence(c *gin.Context) {
+	target, ok := h.matchTarget(c)
+	if ok {
+		h.upload(c, target)
+	}
+}
+
+// GetMatchEvidence скриншоты матча (участники, хост комнаты и администраторы)
+func (h *EvidenceHandlers) GetMatchEvidence(c *gin.Context) {
+	target, ok := h.matchTarget(c)
+	if ok {
+		h.list(c, target)
+	}
+}
+
+// UploadRunEvidence загрузка скриншота к заходу (автор захода)
+func (h *EvidenceHandlers) UploadRunEvidence(c *gin.Context) {
+	target, ok := h.runTarget(c)
+	if ok {
+		h.upload(c, target)
+	}
+}
+
+// GetRunEvidence скриншоты захода (автор, хост комнаты и администраторы)
+func (h *EvidenceHandlers) GetRunEvidence(c *gin.Context) {
+	target, ok := h.runTarget(c)
+	if ok {
+		h.list(c, target)
+	}
+}
+
+// ServeFile отдача файла локального хранилища по подписанной ссылке. Ссылка
+// выдается только после проверки прав, поэтому авторизация не требуется.
+func (h *EvidenceHandlers) ServeFile(c *gin.Context) {
+	local, ok := h.Storage.(*storage.Local)
+	if !ok {
+		utils.NotFoundResponse(c, "File not found")
+		return
+	}
+
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if !local.Verify(key, c.Query("expires"), c.Query("signature")) {
+		utils.ForbiddenResponse(c, "Invalid or expired link")
+		return
+	}
+
+	var file models.EvidenceFile
+	err := h.DB.Get(&file, `
+		SELECT id, storage_key, content_type, size_bytes FROM evidence_files WHERE storage_key = $1
+	`, key)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "File not found")
+		} else {
+			utils.InternalErrorResponse(c, "Database error")
+		}
+		return
+	}
+
+	reader, err := h.Storage.Get(c.Request.Context(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			utils.NotFoundResponse(c, "File not found")
+		} else {
+			utils.InternalErrorResponse(c, "Failed to read file")
+		}
+		return
+	}
+	defer reader.Close()
+
+	c.DataFromReader(http.StatusOK, file.SizeBytes, file.ContentType, reader, map[string]string{
+		"Cache-Control":          "private, max-age=" + strconv.Itoa(int(h.URLTTL.Seconds())),
+		"Content-Disposition":    "inline",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// matchTarget загружает матч и проверяет параметры запроса
+func (h *EvidenceHandlers) matchTarget(c *gin.Context) (*evidenceTarget, bool) {
+	tournamentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid tournament ID")
+		return nil, false
+	}
+
+	matchID, err := strconv.Atoi(c.Param("match_id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid match ID")
+		return nil, false
+	}
+
+	var row struct {
+		RoomID    int    `db:"room_id"`
+		HostID    int    `db:"host_id"`
+		Player1ID int    `db:"player1_id"`
+		Player2ID int    `db:"player2_id"`
+		Status    string `db:"status"`
+	}
+	err = h.DB.Get(&row, `
+		SELECT t.room_id, r.host_id, COALESCE(m.player1_id, 0) as player1_id,
+		       COALESCE(m.player2_id, 0) as player2_id, m.status
+		FROM matches m
+		JOIN tournaments t ON m.tournament_id = t.id
+		JOIN rooms r ON t.room_id = r.id
+		WHERE m.id = $1 AND m.tournament_id = $2
+	`, matchID, tournamentID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "Match not found")
+		} else {
+			utils.InternalErrorResponse(c, "Database error")
+		}
+		return nil, false
+	}
+
+	return &evidenceTarget{
+		TournamentID: tournamentID,
+		RoomID:       row.RoomID,
+		HostID:       row.HostID,
+		MatchID:      &matchID,
+		Owners:       []int{row.Player1ID, row.Player2ID},
+		Open: row.Status == models.MatchStatusPending || row.Status == models.MatchStatusInProgress ||
+			row.Status == models.MatchStatusDisputed,
+	}, true
+}
+
+// runTarget загружает заход и проверяет параметры запроса
+func (h *EvidenceHandlers) runTarget(c *gin.Context) (*evidenceTarget, bool) {
+	tournamentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid tournament ID")
+		return nil, false
+	}
+
+	runID, err := strconv.Atoi(c.Param("run_id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid run ID")
+		return nil, false
+	}
+
+	var row struct {
+		RoomID           int    `db:"room_id"`
+		HostID           int    `db:"host_id"`
+		UserID           int    `db:"user_id"`
+		Status           string `db:"status"`
+		TournamentStatus string `db:"tournament_status"`
+	}
+	err = h.DB.Get(&row, `
+		SELECT t.room_id, r.host_id, tr.user_id, tr.status, t.status as tournament_status
+		FROM tournament_runs tr
+		JOIN tournaments t ON tr.tournament_id = t.id
+		JOIN rooms r ON t.room_id = r.id
+		WHERE tr.id = $1 AND tr.tournament_id = $2
+	`, runID, tournamentID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "Run not found")
+		} else {
+			utils.InternalErrorResponse(c, "Database error")
+		}
+		return nil, false
+	}
+
+	return &evidenceTarget{
+		TournamentID: tournamentID,
+		RoomID:       row.RoomID,
+		HostID:       row.HostID,
+		RunID:        &runID,
+		Owners:       []int{row.UserID},
+		Open:         row.Status == models.RunStatusPending && row.TournamentStatus == models.TournamentStatusStarted,
+	}, true
+}
+
+// upload проверяет и сохраняет скриншот. Формат определяется по содержимому
+// файла, имя и заголовки клиента не учитываются.
+func (h *EvidenceHandlers) upload(c *gin.Context, target *evidenceTarget) {
+	userID := c.GetInt("user_id")
+
+	if !target.isOwner(userID) {
+		utils.ForbiddenResponse(c, "Only participants can upload evidence")
+		return
+	}
+
+	if !target.Open {
+		utils.BadRequestResponse(c, "Result is already final")
+		return
+	}
+
+	// Запас на служебные части multipart-запроса
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.MaxUploadSize+64*1024)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			h.fileTooLarge(c)
+		} else {
+			utils.BadRequestResponse(c, "File is required")
+		}
+		return
+	}
+
+	if fileHeader.Size > h.MaxUploadSize {
+		h.fileTooLarge(c)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.BadRequestResponse(c, "Failed to read file")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, h.MaxUploadSize+1))
+	if err != nil {
+		utils.BadRequestResponse(c, "Failed to read file")
+		return
+	}
+	if int64(len(data)) > h.MaxUploadSize {
+		h.fileTooLarge(c)
+		return
+	}
+
+	image, err := storage.DetectImage(data)
+	if err != nil {
+		utils.ErrorResponseWithDetails(c, http.StatusUnsupportedMediaType, err.Error())
+		return
+	}
+
+	var count int
+	err = h.DB.Get(&count, `
+		SELECT COUNT(*) FROM evidence_files
+		WHERE uploader_id = $1 AND (match_id = $2 OR run_id = $3)
+	`, userID, target.MatchID, target.RunID)
+	if err != nil {
+		utils.InternalErrorResponse(c, "Database error")
+		return
+	}
+
+	if count >= MaxEvidenceFiles {
+		utils.BadRequestResponse(c, "Evidence limit reached: at most "+strconv.Itoa(MaxEvidenceFiles)+" files")
+		return
+	}
+
+	key := fmt.Sprintf("evidence/%d/%s.%s", target.TournamentID, uuid.NewString(), image.Extension)
+	if err := h.Storage.Put(c.Request.Context(), key, image.ContentType, data); err != nil {
+		h.Logger.Error("Failed to store evidence", "key", key, "error", err)
+		utils.InternalErrorResponse(c, "Failed to store file")
+		return
+	}
+
+	var originalName *string
+	if name := fileHeader.Filename; name != "" {
+		if len(name) > 255 {
+			name = name[:255]
+		}
+		originalName = &name
+	}
+
+	var evidence models.EvidenceFile
+	err = h.DB.Get(&evidence, `
+		INSERT INTO evidence_files (tournament_id, match_id, run_id, uploader_id, storage_key, content_type, size_bytes, original_name)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, tournament_id, match_id, run_id, uploader_id, storage_key, content_type, size_bytes, original_name, created_at
+	`, target.TournamentID, target.MatchID, target.RunID, userID, key, image.ContentType, len(data), originalName)
+
+	if err != nil {
+		// Файл без записи в базе недоступен, удаляем его
+		if delErr := h.Storage.Delete(c.Request.Context(), key); delErr != nil {
+			h.Logger.Error("Failed to delete orphaned evidence", "key", key, "error", delErr)
+		}
+		utils.InternalErrorResponse(c, "Failed to save evidence")
+		return
+	}
+
+	if err := h.sign(&evidence); err != nil {
+		h.Logger.Error("Failed to sign evidence url", "evidence_id", evidence.ID, "error", err)
+	}
+
+	h.Logger.Info("Evidence uploaded",
+		"evidence_id", evidence.ID,
+		"tournament_id", target.TournamentID,
+		"uploader_id", userID,
+		"content_type", image.ContentType,
+		"size", len(data),
+	)
+
+	// Комнате сообщаем только о факте загрузки: ссылки получают участники и хост через API
+	wsMsg := models.WSMessage{
+		Type: models.WSTypeTournamentUpdate,
+		Data: gin.H{
+			"room_id":       target.RoomID,
+			"tournament_id": target.TournamentID,
+			"match_id":      target.MatchID,
+			"run_id":        target.RunID,
+			"stage":         "evidence",
+			"action":        "evidence_uploaded",
+			"evidence_id":   evidence.ID,
+			"uploader_id":   userID,
+		},
+	}
+	msgBytes, _ := json.Marshal(wsMsg)
+	h.Hub.BroadcastToRoom(target.RoomID, msgBytes)
+
+	utils.CreatedResponse(c, evidence, "Evidence uploaded")
+}
+
+// list возвращает скриншоты с подписанными ссылками
+func (h *EvidenceHandlers) list(c *gin.Context, target *evidenceTarget) {
+	userID := c.GetInt("user_id")
+
+	if !target.isOwner(userID) && target.HostID != userID && !h.isAdmin(userID) {
+		utils.ForbiddenResponse(c, "Only participants, room host or administrators can view evidence")
+		return
+	}
+
+	files := []models.EvidenceFile{}
+	err := h.DB.Select(&files, `
+		SELECT ef.id, ef.tournament_id, ef.match_id, ef.run_id, ef.uploader_id, u.username as uploader,
+		       ef.storage_key, ef.content_type, ef.size_bytes, ef.original_name, ef.created_at
+		FROM evidence_files ef
+		JOIN users u ON ef.uploader_id = u.id
+		WHERE ef.match_id = $1 OR ef.run_id = $2
+		ORDER BY ef.created_at, ef.id
+	`, target.MatchID, target.RunID)
+
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to get evidence")
+		return
+	}
+
+	for i := range files {
+		if err := h.sign(&files[i]); err != nil {
+			h.Logger.Error("Failed to sign evidence url", "evidence_id", files[i].ID, "error", err)
+			utils.InternalErrorResponse(c, "Failed to sign evidence links")
+			return
+		}
+	}
+
+	utils.SuccessResponse(c, files)
+}
+
+// sign добавляет к файлу подписанную ссылку с ограниченным сроком действия
+func (h *EvidenceHandlers) sign(file *models.EvidenceFile) error {
+	url, err := h.Storage.SignedURL(file.StorageKey, h.URLTTL)
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(h.URLTTL)
+	file.URL = url
+	file.URLExpiresAt = &expiresAt
+	return nil
+}
+
+// fileTooLarge ответ на превышение размера файла
+func (h *EvidenceHandlers) fileTooLarge(c *gin.Context) {
+	utils.ErrorResponseWithDetails(c, http.StatusRequestEntityTooLarge,
+		"File is too large: maximum "+strconv.FormatInt(h.MaxUploadSize/(1024*1024), 10)+"MB")
+}
+
+// isOwner проверяет, является ли пользователь участником матча или автором захода
+func (t *evidenceTarget) isOwner(userID int) bool {
+	for _, id := range t.Owners {
+		if id != 0 && id == userID {
+			return true
+		}
+	}
+	return false
+}
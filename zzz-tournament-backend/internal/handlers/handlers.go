@@ -21,12 +21,19 @@ type Handlers struct {
 	Users       *UserHandlers
 	Heroes      *HeroHandlers
 	Rooms       *RoomHandlers
+	Teams       *TeamHandlers
 	Tournaments *TournamentHandlers
 	Chat        *ChatHandlers
+	CheckIn     *CheckInHandlers
+	Evidence    *EvidenceHandlers
+	Seasons     *SeasonHandlers
+	Ratings     *RatingHandlers
+	Matchmaking *MatchmakingHandlers
+	Moderation  *ModerationHandlers
 }
 
 // New создает новый экземпляр всех хендлеров
-func New(db *sqlx.DB, hub *websocket.Hub, logger *slog.Logger, authConfig *config.AuthConfig) *Handlers {
+func New(db *sqlx.DB, hub *websocket.Hub, logger *slog.Logger, authConfig *config.AuthConfig, tournamentConfig *config.TournamentConfig, ratingConfig *config.RatingConfig, matchmakingConfig *config.MatchmakingConfig, collusionConfig *config.CollusionConfig, evidence EvidenceOptions) *Handlers {
 	h := &Handlers{
 		DB:     db,
 		Hub:    hub,
@@ -36,11 +43,25 @@ func New(db *sqlx.DB, hub *websocket.Hub, logger *slog.Logger, authConfig *confi
 
 	// Инициализируем отдельные группы хендлеров
 	h.Auth = NewAuthHandlers(db, hub, logger, authConfig)
-	h.Users = NewUserHandlers(db, hub, logger)
+	h.Users = NewUserHandlers(db, hub, logger, ratingConfig)
 	h.Heroes = NewHeroHandlers(db, hub, logger)
 	h.Rooms = NewRoomHandlers(db, hub, logger)
-	h.Tournaments = NewTournamentHandlers(db, hub, logger)
+	h.Teams = NewTeamHandlers(db, hub, logger)
+	h.Tournaments = NewTournamentHandlers(db, hub, logger, tournamentConfig, ratingConfig)
 	h.Chat = NewChatHandlers(db, hub, logger)
+	h.CheckIn = NewCheckInHandlers(db, hub, logger, h.Chat)
+	h.Evidence = NewEvidenceHandlers(db, hub, logger, evidence)
+	h.Seasons = NewSeasonHandlers(db, hub, logger, ratingConfig)
+	h.Ratings = NewRatingHandlers(db, hub, logger, ratingConfig)
+	h.Matchmaking = NewMatchmakingHandlers(db, hub, logger, matchmakingConfig, h.Tournaments)
+	h.Moderation = NewModerationHandlers(db, hub, logger, collusionConfig)
+
+	// Сообщения WebSocket, которым нужен доступ к базе данных
+	hub.HandleMessage("check_in", h.CheckIn.HandleCheckInMessage)
+	hub.HandleMessage("draft_action", h.Tournaments.HandleDraftMessage)
+	hub.HandleMessage("ranked_queue_join", h.Matchmaking.HandleQueueJoin)
+	hub.HandleMessage("ranked_queue_leave", h.Matchmaking.HandleQueueLeave)
+	hub.HandleMessage("ranked_ready", h.Matchmaking.HandleReadyCheck)
 
 	return h
 }
@@ -60,3 +81,22 @@ func newBaseHandlers(db *sqlx.DB, hub *websocket.Hub, logger *slog.Logger) BaseH
 		Logger: logger,
 	}
 }
+
+// isAdmin проверяет, является ли пользователь администратором.
+// Пользователь с ID = 1 считается администратором, как и в AdminOnlyMiddleware.
+func (b *BaseHandlers) isAdmin(userID int) bool {
+	if userID == 1 {
+		return true
+	}
+
+	var isAdmin bool
+	err := b.DB.Get(&isAdmin, `SELECT COALESCE(is_admin, false) FROM users WHERE id = $1`, userID)
+	return err == nil && isAdmin
+}
+
+// adminIDs возвращает ID всех администраторов
+func (b *BaseHandlers) adminIDs() ([]int, error) {
+	var ids []int
+	err := b.DB.Select(&ids, `SELECT id FROM users WHERE is_admin = true OR id = 1 ORDER BY id`)
+	return ids, err
+}
@@ -0,0 +1,176 @@
+// internal/handlers/head_to_head.go
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"zzz-tournament/internal/models"
+	"zzz-tournament/pkg/rating"
+	"zzz-tournament/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Ограничения выборки личных встреч
+const (
+	DefaultHeadToHeadMatches = 10
+	MaxHeadToHeadMatches     = 50
+	MaxRivals                = 5
+)
+
+// HeadToHeadMatch завершенный матч между двумя игроками
+type HeadToHeadMatch struct {
+	MatchID        int       `json:"match_id" db:"match_id"`
+	TournamentID   int       `json:"tournament_id" db:"tournament_id"`
+	TournamentName string    `json:"tournament_name" db:"tournament_name"`
+	TournamentURL  string    `json:"tournament_url" db:"-"`
+	Round          int       `json:"round" db:"round"`
+	WinnerID       int       `json:"winner_id" db:"winner_id"`
+	IsWalkover     bool      `json:"is_walkover" db:"is_walkover"`
+	PlayedAt       time.Time `json:"played_at" db:"played_at"`
+}
+
+// HeadToHeadStreak текущая серия побед одного из игроков в личных встречах
+type HeadToHeadStreak struct {
+	UserID int `json:"user_id"`
+	Count  int `json:"count"`
+}
+
+// Rival частый соперник игрока
+type Rival struct {
+	UserID       int       `json:"user_id" db:"user_id"`
+	Username     string    `json:"username" db:"username"`
+	Matches      int       `json:"matches" db:"matches"`
+	Wins         int       `json:"wins" db:"wins"`
+	Losses       int       `json:"losses" db:"losses"`
+	LastPlayedAt time.Time `json:"last_played_at" db:"last_played_at"`
+}
+
+// headToHeadFilter условие завершенных матчей игроков; командные матчи не учитываются
+const headToHeadFilter = `m.status = 'finished' AND m.winner_id IS NOT NULL AND COALESCE(t.team_mode, false) = false`
+
+// GetHeadToHead статистика личных встреч двух игроков
+func (h *UserHandlers) GetHeadToHead(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid user ID")
+		return
+	}
+
+	otherID, err := strconv.Atoi(c.Param("other_id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid opponent ID")
+		return
+	}
+
+	if userID == otherID {
+		utils.BadRequestResponse(c, "Cannot compare user with themselves")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(DefaultHeadToHeadMatches)))
+	if err != nil || limit < 1 || limit > MaxHeadToHeadMatches {
+		utils.BadRequestResponse(c, "Limit must be between 1 and "+strconv.Itoa(MaxHeadToHeadMatches))
+		return
+	}
+
+	var users []models.User
+	err = h.DB.Select(&users, `
+		SELECT id, username, rating, wins, losses, created_at
+		FROM users WHERE id IN ($1, $2)
+	`, userID, otherID)
+	if err != nil {
+		utils.InternalErrorResponse(c, "Database error")
+		return
+	}
+
+	if len(users) != 2 {
+		utils.NotFoundResponse(c, "User not found")
+		return
+	}
+
+	user, other := users[0], users[1]
+	if user.ID != userID {
+		user, other = other, user
+	}
+
+	// Встреч двух игроков немного, поэтому выбираем их целиком: по ним считаются
+	// итоги, серия и последние матчи
+	meetings := []HeadToHeadMatch{}
+	err = h.DB.Select(&meetings, `
+		SELECT m.id as match_id, m.tournament_id, COALESCE(t.name, '') as tournament_name, m.round,
+		       m.winner_id, m.is_walkover, m.updated_at as played_at
+		FROM matches m
+		LEFT JOIN tournaments t ON m.tournament_id = t.id
+		WHERE `+headToHeadFilter+`
+		  AND ((m.player1_id = $1 AND m.player2_id = $2) OR (m.player1_id = $2 AND m.player2_id = $1))
+		ORDER BY m.updated_at DESC, m.id DESC
+	`, userID, otherID)
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to fetch head-to-head matches")
+		return
+	}
+
+	userWins, walkovers := 0, 0
+	for i := range meetings {
+		meetings[i].TournamentURL = "/api/v1/tournaments/" + strconv.Itoa(meetings[i].TournamentID)
+		if meetings[i].WinnerID == userID {
+			userWins++
+		}
+		if meetings[i].IsWalkover {
+			walkovers++
+		}
+	}
+
+	var streak *HeadToHeadStreak
+	if len(meetings) > 0 {
+		streak = &HeadToHeadStreak{UserID: meetings[0].WinnerID}
+		for _, match := range meetings {
+			if match.WinnerID != streak.UserID {
+				break
+			}
+			streak.Count++
+		}
+	}
+
+	recent := meetings
+	if len(recent) > limit {
+		recent = recent[:limit]
+	}
+
+	// Вероятность победы по Elo на текущих рейтингах
+	probability := rating.GetMatchProbability(user.Rating, other.Rating)
+
+	utils.SuccessResponse(c, gin.H{
+		"user":                     user,
+		"opponent":                 other,
+		"total_matches":            len(meetings),
+		"user_wins":                userWins,
+		"opponent_wins":            len(meetings) - userWins,
+		"walkovers":                walkovers,
+		"current_streak":           streak,
+		"win_probability":          probability,
+		"opponent_win_probability": 1 - probability,
+		"last_matches":             recent,
+	})
+}
+
+// getRivals возвращает самых частых соперников игрока
+func (h *UserHandlers) getRivals(userID int) ([]Rival, error) {
+	rivals := []Rival{}
+	err := h.DB.Select(&rivals, `
+		SELECT opp.id as user_id, opp.username, COUNT(*) as matches,
+		       COUNT(*) FILTER (WHERE m.winner_id = $1) as wins,
+		       COUNT(*) FILTER (WHERE m.winner_id = opp.id) as losses,
+		       MAX(m.updated_at) as last_played_at
+		FROM matches m
+		LEFT JOIN tournaments t ON m.tournament_id = t.id
+		JOIN users opp ON opp.id = CASE WHEN m.player1_id = $1 THEN m.player2_id ELSE m.player1_id END
+		WHERE `+headToHeadFilter+` AND (m.player1_id = $1 OR m.player2_id = $1)
+		GROUP BY opp.id, opp.username
+		ORDER BY matches DESC, last_played_at DESC
+		LIMIT $2
+	`, userID, MaxRivals)
+	return rivals, err
+}
@@ -4,6 +4,9 @@ package handlers
 import (
 	"strconv"
 	"strings"
+
+	"zzz-tournament/pkg/utils"
+	"zzz-tournament/pkg/validator"
 )
 
 // joinStrings объединяет строки с разделителем (только для не-SQL целей)
@@ -361,3 +364,16 @@ func sanitizeSQL(input string) string {
 
 	return result
 }
+
+// validationDetails преобразует ошибки валидации в детали ответа
+func validationDetails(errs validator.ValidationErrors) []utils.ErrorDetail {
+	details := make([]utils.ErrorDetail, len(errs))
+	for i, err := range errs {
+		details[i] = utils.ErrorDetail{
+			Field:   err.Field,
+			Code:    err.Code,
+			Message: err.Message,
+		}
+	}
+	return details
+}
@@ -0,0 +1,343 @@
+// internal/handlers/hero_stats.go
+package handlers
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"zzz-tournament/internal/models"
+)
+
+// Параметры расчета тиров и трендов героев
+const (
+	MinHeroStatsSample = 10 // Минимум команд с героем для расчета тира
+	TrendThreshold     = 2  // Изменение доли пиков (п.п.), после которого тренд считается растущим или падающим
+)
+
+// HeroStatsQuery параметры фильтрации статистики героев
+type HeroStatsQuery struct {
+	TournamentID int    `form:"tournament_id"`
+	From         string `form:"from"`       // Дата начала (YYYY-MM-DD)
+	To           string `form:"to"`         // Дата окончания включительно (YYYY-MM-DD)
+	MinRating    int    `form:"min_rating"` // Рейтинговая группа игроков
+	MaxRating    int    `form:"max_rating"`
+	Interval     string `form:"interval"` // Период тренда: day, week (по умолчанию), month
+}
+
+// HeroStats статистика героя. Доли считаются в процентах: пики - от команд с
+// указанными героями, баны - от драфтов, где игрок банил
+type HeroStats struct {
+	Hero        models.Hero       `json:"hero"`
+	Picks       int               `json:"total_picks"`
+	Wins        int               `json:"wins"`
+	Bans        int               `json:"total_bans"`
+	PickRate    float64           `json:"pick_rate"`
+	BanRate     float64           `json:"ban_rate"`
+	WinRate     float64           `json:"win_rate"`
+	Popularity  float64           `json:"popularity"` // Пики и баны вместе
+	Tier        string            `json:"tier"`       // S, A, B, C, D, Unknown
+	Trend       string            `json:"trend"`      // rising, falling, stable
+	TotalTeams  int               `json:"total_teams,omitempty"`
+	TotalDrafts int               `json:"total_drafts,omitempty"`
+	History     []HeroStatsPeriod `json:"history,omitempty"`
+}
+
+// HeroStatsPeriod статистика героя за период тренда
+type HeroStatsPeriod struct {
+	Period   time.Time `json:"period" db:"period"`
+	Teams    int       `json:"teams" db:"teams"`
+	Picks    int       `json:"picks" db:"picks"`
+	Wins     int       `json:"wins" db:"wins"`
+	PickRate float64   `json:"pick_rate"`
+	WinRate  float64   `json:"win_rate"`
+}
+
+// heroStatsFilter условия выборки по турниру, датам и рейтингу игрока
+type heroStatsFilter struct {
+	where string
+	args  []interface{}
+}
+
+// heroStatsResult статистика всех героев по фильтру
+type heroStatsResult struct {
+	TotalTeams  int
+	TotalDrafts int
+	Heroes      map[int]*HeroStats
+	History     map[int][]HeroStatsPeriod
+}
+
+// heroTeamsQuery составы команд в сыгранных играх: по строке на каждого игрока игры
+const heroTeamsQuery = `
+	SELECT m.tournament_id, mg.created_at, u.rating, mg.player1_heroes as heroes, mg.winner_id = m.player1_id as won
+	FROM match_games mg
+	JOIN matches m ON mg.match_id = m.id
+	JOIN users u ON m.player1_id = u.id
+	WHERE cardinality(mg.player1_heroes) > 0
+	UNION ALL
+	SELECT m.tournament_id, mg.created_at, u.rating, mg.player2_heroes, mg.winner_id = m.player2_id
+	FROM match_games mg
+	JOIN matches m ON mg.match_id = m.id
+	JOIN users u ON m.player2_id = u.id
+	WHERE cardinality(mg.player2_heroes) > 0`
+
+// heroBansQuery баны в драфтах матчей, включая пропущенные
+const heroBansQuery = `
+	SELECT m.tournament_id, hp.created_at, u.rating, hp.match_id, hp.user_id, hp.hero_id
+	FROM match_hero_picks hp
+	JOIN matches m ON hp.match_id = m.id
+	JOIN users u ON hp.user_id = u.id
+	WHERE hp.action = 'ban'`
+
+// filter проверяет параметры и строит условия выборки
+func (q *HeroStatsQuery) filter() (heroStatsFilter, error) {
+	var filter heroStatsFilter
+	conditions := []string{}
+
+	if q.Interval == "" {
+		q.Interval = "week"
+	}
+	if q.Interval != "day" && q.Interval != "week" && q.Interval != "month" {
+		return filter, errors.New("interval must be one of: day, week, month")
+	}
+
+	if q.TournamentID > 0 {
+		filter.args = append(filter.args, q.TournamentID)
+		conditions = append(conditions, "tournament_id = $"+strconv.Itoa(len(filter.args)))
+	}
+
+	if q.From != "" {
+		from, err := time.Parse("2006-01-02", q.From)
+		if err != nil {
+			return filter, errors.New("from must be a date in YYYY-MM-DD format")
+		}
+		filter.args = append(filter.args, from)
+		conditions = append(conditions, "created_at >= $"+strconv.Itoa(len(filter.args)))
+	}
+
+	if q.To != "" {
+		to, err := time.Parse("2006-01-02", q.To)
+		if err != nil {
+			return filter, errors.New("to must be a date in YYYY-MM-DD format")
+		}
+		filter.args = append(filter.args, to.AddDate(0, 0, 1))
+		conditions = append(conditions, "created_at < $"+strconv.Itoa(len(filter.args)))
+	}
+
+	if q.MinRating < 0 || q.MaxRating < 0 {
+		return filter, errors.New("rating bounds must not be negative")
+	}
+	if q.MaxRating > 0 && q.MinRating > q.MaxRating {
+		return filter, errors.New("min_rating must not exceed max_rating")
+	}
+
+	if q.MinRating > 0 {
+		filter.args = append(filter.args, q.MinRating)
+		conditions = append(conditions, "rating >= $"+strconv.Itoa(len(filter.args)))
+	}
+
+	if q.MaxRating > 0 {
+		filter.args = append(filter.args, q.MaxRating)
+		conditions = append(conditions, "rating <= $"+strconv.Itoa(len(filter.args)))
+	}
+
+	if len(conditions) > 0 {
+		filter.where = " WHERE " + joinStrings(conditions, " AND ")
+	}
+
+	return filter, nil
+}
+
+// collectHeroStats считает статистику всех героев по фильтру
+func (h *HeroHandlers) collectHeroStats(filter heroStatsFilter, interval string) (*heroStatsResult, error) {
+	result := &heroStatsResult{
+		Heroes:  make(map[int]*HeroStats),
+		History: make(map[int][]HeroStatsPeriod),
+	}
+
+	teams := "WITH teams AS (SELECT * FROM (" + heroTeamsQuery + ") t" + filter.where + ") "
+	bans := "WITH bans AS (SELECT * FROM (" + heroBansQuery + ") b" + filter.where + ") "
+
+	if err := h.DB.Get(&result.TotalTeams, teams+`SELECT COUNT(*) FROM teams`, filter.args...); err != nil {
+		return nil, err
+	}
+
+	if err := h.DB.Get(&result.TotalDrafts, bans+`SELECT COUNT(DISTINCT (match_id, user_id)) FROM bans`, filter.args...); err != nil {
+		return nil, err
+	}
+
+	var picks []struct {
+		HeroID int `db:"hero_id"`
+		Picks  int `db:"picks"`
+		Wins   int `db:"wins"`
+	}
+	err := h.DB.Select(&picks, teams+`
+		SELECT hero_id, COUNT(*) as picks, COUNT(*) FILTER (WHERE won) as wins
+		FROM teams, unnest(heroes) as hero_id
+		GROUP BY hero_id
+	`, filter.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range picks {
+		result.hero(row.HeroID).Picks = row.Picks
+		result.hero(row.HeroID).Wins = row.Wins
+	}
+
+	var banned []struct {
+		HeroID int `db:"hero_id"`
+		Bans   int `db:"bans"`
+	}
+	err = h.DB.Select(&banned, bans+`
+		SELECT hero_id, COUNT(*) as bans
+		FROM bans
+		WHERE hero_id IS NOT NULL
+		GROUP BY hero_id
+	`, filter.args...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range banned {
+		result.hero(row.HeroID).Bans = row.Bans
+	}
+
+	// Тренд популярности по периодам
+	intervalArg := "$" + strconv.Itoa(len(filter.args)+1)
+	args := append(append([]interface{}{}, filter.args...), interval)
+
+	var periods []HeroStatsPeriod
+	err = h.DB.Select(&periods, teams+`
+		SELECT date_trunc(`+intervalArg+`, created_at) as period, COUNT(*) as teams
+		FROM teams
+		GROUP BY 1
+		ORDER BY 1
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var periodPicks []struct {
+		Period time.Time `db:"period"`
+		HeroID int       `db:"hero_id"`
+		Picks  int       `db:"picks"`
+		Wins   int       `db:"wins"`
+	}
+	err = h.DB.Select(&periodPicks, teams+`
+		SELECT date_trunc(`+intervalArg+`, created_at) as period, hero_id,
+		       COUNT(*) as picks, COUNT(*) FILTER (WHERE won) as wins
+		FROM teams, unnest(heroes) as hero_id
+		GROUP BY 1, 2
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	byPeriod := make(map[int]map[time.Time]HeroStatsPeriod)
+	for _, row := range periodPicks {
+		if byPeriod[row.HeroID] == nil {
+			byPeriod[row.HeroID] = make(map[time.Time]HeroStatsPeriod)
+		}
+		byPeriod[row.HeroID][row.Period] = HeroStatsPeriod{Picks: row.Picks, Wins: row.Wins}
+	}
+
+	for heroID, stats := range result.Heroes {
+		history := make([]HeroStatsPeriod, 0, len(periods))
+		for _, period := range periods {
+			row := byPeriod[heroID][period.Period]
+			row.Period = period.Period
+			row.Teams = period.Teams
+			row.PickRate = percent(row.Picks, row.Teams)
+			row.WinRate = percent(row.Wins, row.Picks)
+			history = append(history, row)
+		}
+		result.History[heroID] = history
+
+		stats.finalize(result.TotalTeams, result.TotalDrafts, history)
+	}
+
+	return result, nil
+}
+
+// hero возвращает статистику героя, создавая ее при первом обращении
+func (r *heroStatsResult) hero(heroID int) *HeroStats {
+	stats, ok := r.Heroes[heroID]
+	if !ok {
+		stats = &HeroStats{}
+		r.Heroes[heroID] = stats
+	}
+	return stats
+}
+
+// finalize рассчитывает доли, тир и тренд героя
+func (s *HeroStats) finalize(totalTeams, totalDrafts int, history []HeroStatsPeriod) {
+	s.PickRate = percent(s.Picks, totalTeams)
+	s.BanRate = percent(s.Bans, totalDrafts)
+	s.WinRate = percent(s.Wins, s.Picks)
+	s.Popularity = math.Min(s.PickRate+s.BanRate, 100)
+	s.Tier = heroTier(s.Picks, s.WinRate, s.Popularity)
+	s.Trend = heroTrend(history)
+}
+
+// heroTier определяет тир героя по доле побед и популярности
+func heroTier(picks int, winRate, popularity float64) string {
+	switch {
+	case picks < MinHeroStatsSample:
+		return "Unknown"
+	case winRate >= 55 && popularity >= 15:
+		return "S"
+	case winRate >= 52:
+		return "A"
+	case winRate >= 48:
+		return "B"
+	case winRate >= 45:
+		return "C"
+	default:
+		return "D"
+	}
+}
+
+// tierRank порядок тиров для сортировки
+func tierRank(tier string) int {
+	switch tier {
+	case "S":
+		return 0
+	case "A":
+		return 1
+	case "B":
+		return 2
+	case "C":
+		return 3
+	case "D":
+		return 4
+	default:
+		return 5
+	}
+}
+
+// heroTrend сравнивает долю пиков героя в двух последних периодах
+func heroTrend(history []HeroStatsPeriod) string {
+	if len(history) < 2 {
+		return "stable"
+	}
+
+	diff := history[len(history)-1].PickRate - history[len(history)-2].PickRate
+	switch {
+	case diff >= TrendThreshold:
+		return "rising"
+	case diff <= -TrendThreshold:
+		return "falling"
+	default:
+		return "stable"
+	}
+}
+
+// percent возвращает долю в процентах с точностью до сотых
+func percent(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(total)*10000) / 100
+}
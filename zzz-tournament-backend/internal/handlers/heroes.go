@@ -4,6 +4,7 @@ package handlers
 import (
 	"database/sql"
 	"log/slog"
+	"sort"
 	"strconv"
 
 	"zzz-tournament/internal/models"
@@ -525,7 +526,7 @@ func (h *HeroHandlers) RestoreHero(c *gin.Context) {
 	utils.SuccessResponse(c, hero, "Hero restored successfully")
 }
 
-// GetHeroStats получение статистики героя
+// GetHeroStats получение статистики героя по сыгранным играм и драфтам
 func (h *HeroHandlers) GetHeroStats(c *gin.Context) {
 	heroID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -533,6 +534,18 @@ func (h *HeroHandlers) GetHeroStats(c *gin.Context) {
 		return
 	}
 
+	var query HeroStatsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	filter, err := query.filter()
+	if err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
 	// Проверяем существование героя
 	var hero models.Hero
 	err = h.DB.Get(&hero, `
@@ -549,17 +562,81 @@ func (h *HeroHandlers) GetHeroStats(c *gin.Context) {
 		return
 	}
 
-	// TODO: Добавить статистику когда будет таблица выбранных героев в матчах
-	stats := gin.H{
-		"hero":        hero,
-		"total_picks": 0,
-		"win_rate":    0.0,
-		"popularity":  0.0,
-		"ban_rate":    0.0,
-		"tier":        "Unknown",
+	stats, err := h.collectHeroStats(filter, query.Interval)
+	if err != nil {
+		h.Logger.Error("Failed to collect hero stats", "hero_id", heroID, "error", err)
+		utils.InternalErrorResponse(c, "Failed to fetch hero statistics")
+		return
+	}
+
+	heroStats := stats.Heroes[heroID]
+	if heroStats == nil {
+		heroStats = &HeroStats{}
+		heroStats.finalize(stats.TotalTeams, stats.TotalDrafts, nil)
+	}
+	heroStats.Hero = hero
+	heroStats.TotalTeams = stats.TotalTeams
+	heroStats.TotalDrafts = stats.TotalDrafts
+	heroStats.History = stats.History[heroID]
+
+	utils.SuccessResponse(c, heroStats, "Hero statistics fetched successfully")
+}
+
+// GetHeroesStats статистика всех героев (мета): сортировка по тиру и популярности
+func (h *HeroHandlers) GetHeroesStats(c *gin.Context) {
+	var query HeroStatsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
 	}
 
-	utils.SuccessResponse(c, stats, "Hero statistics fetched successfully")
+	filter, err := query.filter()
+	if err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	var heroes []models.Hero
+	err = h.DB.Select(&heroes, `
+		SELECT id, name, element, rarity, role, description, image_url, is_active
+		FROM heroes WHERE is_active = true
+		ORDER BY name
+	`)
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to fetch heroes")
+		return
+	}
+
+	stats, err := h.collectHeroStats(filter, query.Interval)
+	if err != nil {
+		h.Logger.Error("Failed to collect hero stats", "error", err)
+		utils.InternalErrorResponse(c, "Failed to fetch hero statistics")
+		return
+	}
+
+	result := make([]*HeroStats, 0, len(heroes))
+	for _, hero := range heroes {
+		heroStats := stats.Heroes[hero.ID]
+		if heroStats == nil {
+			heroStats = &HeroStats{}
+			heroStats.finalize(stats.TotalTeams, stats.TotalDrafts, nil)
+		}
+		heroStats.Hero = hero
+		result = append(result, heroStats)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if tierRank(result[i].Tier) != tierRank(result[j].Tier) {
+			return tierRank(result[i].Tier) < tierRank(result[j].Tier)
+		}
+		return result[i].Popularity > result[j].Popularity
+	})
+
+	utils.SuccessResponse(c, gin.H{
+		"heroes":       result,
+		"total_teams":  stats.TotalTeams,
+		"total_drafts": stats.TotalDrafts,
+	}, "Hero statistics fetched successfully")
 }
 
 // joinStrings определена в helpers.go
@@ -0,0 +1,345 @@
+// internal/handlers/matchmaking.go
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"zzz-tournament/internal/models"
+	"zzz-tournament/internal/websocket"
+	"zzz-tournament/pkg/config"
+	"zzz-tournament/pkg/tournament"
+	"zzz-tournament/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// MatchmakingHandlers обработчики очереди рейтинговых матчей 1v1
+type MatchmakingHandlers struct {
+	BaseHandlers
+	Config      *config.MatchmakingConfig
+	Tournaments *TournamentHandlers
+}
+
+// NewMatchmakingHandlers создает новый экземпляр MatchmakingHandlers
+func NewMatchmakingHandlers(db *sqlx.DB, hub *websocket.Hub, logger *slog.Logger, matchmakingConfig *config.MatchmakingConfig, tournaments *TournamentHandlers) *MatchmakingHandlers {
+	return &MatchmakingHandlers{
+		BaseHandlers: newBaseHandlers(db, hub, logger),
+		Config:       matchmakingConfig,
+		Tournaments:  tournaments,
+	}
+}
+
+// rankedMatch созданный рейтинговый матч
+type rankedMatch struct {
+	RoomID       int `json:"room_id"`
+	TournamentID int `json:"tournament_id"`
+	MatchID      int `json:"match_id"`
+}
+
+var errActiveRankedMatch = errors.New("finish your current ranked match first")
+
+// HandleQueueJoin ставит игрока в очередь рейтинговых матчей (сообщение ranked_queue_join)
+func (h *MatchmakingHandlers) HandleQueueJoin(client *websocket.Client, data interface{}) (interface{}, error) {
+	var user models.User
+	err := h.DB.Get(&user, `SELECT id, username, rating, wins, losses FROM users WHERE id = $1`, client.UserID)
+	if err != nil {
+		h.Logger.Error("Failed to load user for ranked queue", "user_id", client.UserID, "error", err)
+		return nil, errors.New("failed to join ranked queue")
+	}
+
+	var active bool
+	err = h.DB.Get(&active, `
+		SELECT EXISTS(
+			SELECT 1 FROM matches m
+			JOIN tournaments t ON m.tournament_id = t.id
+			WHERE t.ladder = $1 AND t.status = 'started' AND m.status <> 'finished'
+			  AND (m.player1_id = $2 OR m.player2_id = $2)
+		)
+	`, models.RatingTypeRanked, client.UserID)
+	if err != nil {
+		h.Logger.Error("Failed to check active ranked match", "user_id", client.UserID, "error", err)
+		return nil, errors.New("failed to join ranked queue")
+	}
+	if active {
+		return nil, errActiveRankedMatch
+	}
+
+	ladder, err := ladderRating(h.DB, &user, models.RatingTypeRanked)
+	if err != nil {
+		h.Logger.Error("Failed to load ranked rating", "user_id", client.UserID, "error", err)
+		return nil, errors.New("failed to join ranked queue")
+	}
+
+	entry := websocket.QueueEntry{
+		UserID:   user.ID,
+		Username: user.Username,
+		Rating:   ladder.Rating,
+		JoinedAt: time.Now(),
+	}
+	if err := h.Hub.Queue.Join(entry); err != nil {
+		return nil, err
+	}
+
+	return gin.H{
+		"queued":     true,
+		"rating":     entry.Rating,
+		"window":     h.Config.RatingWindow(0),
+		"queue_size": h.Hub.Queue.Len(),
+	}, nil
+}
+
+// HandleQueueLeave убирает игрока из очереди (сообщение ranked_queue_leave).
+// Выход во время проверки готовности считается отказом.
+func (h *MatchmakingHandlers) HandleQueueLeave(client *websocket.Client, data interface{}) (interface{}, error) {
+	if h.Hub.Queue.Leave(client.UserID) {
+		return gin.H{"queued": false}, nil
+	}
+
+	check, err := h.Hub.Queue.Ready(client.UserID, false)
+	if err != nil {
+		return nil, errors.New("not in ranked queue")
+	}
+
+	h.notifyReadyCancelled(check, client.UserID)
+	return gin.H{"queued": false}, nil
+}
+
+// HandleReadyCheck принимает ответ на проверку готовности (сообщение ranked_ready).
+// Когда оба игрока подтвердили готовность, создается рейтинговый матч.
+func (h *MatchmakingHandlers) HandleReadyCheck(client *websocket.Client, data interface{}) (interface{}, error) {
+	dataMap, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid ready check data")
+	}
+
+	accept, ok := dataMap["accept"].(bool)
+	if !ok {
+		return nil, errors.New("accept is required")
+	}
+
+	check, err := h.Hub.Queue.Ready(client.UserID, accept)
+	if err != nil {
+		return nil, err
+	}
+
+	switch check.Status {
+	case websocket.ReadyCheckCancelled:
+		h.notifyReadyCancelled(check, client.UserID)
+		return gin.H{"check_id": check.ID, "status": check.Status, "queued": false}, nil
+	case websocket.ReadyCheckConfirmed:
+		match, err := h.createRankedMatch(check)
+		if err != nil {
+			h.Logger.Error("Failed to create ranked match", "check_id", check.ID, "error", err)
+			// Оба игрока подтвердили готовность, поэтому возвращаются в очередь
+			h.Hub.Queue.Requeue(check.Players[0], check.Players[1])
+			h.notifyPlayers(check, "ranked_ready_cancelled", gin.H{
+				"check_id": check.ID,
+				"reason":   "match_creation_failed",
+				"requeued": true,
+			})
+			return nil, errors.New("failed to create ranked match")
+		}
+
+		h.notifyRankedMatchCreated(check, match)
+		return gin.H{"check_id": check.ID, "status": check.Status, "match": match}, nil
+	}
+
+	return gin.H{"check_id": check.ID, "status": check.Status, "accepted": true}, nil
+}
+
+// GetQueueStatus состояние игрока в очереди рейтинговых матчей
+func (h *MatchmakingHandlers) GetQueueStatus(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	result := gin.H{
+		"queued":     false,
+		"queue_size": h.Hub.Queue.Len(),
+	}
+
+	if entry, ok := h.Hub.Queue.Entry(userID); ok {
+		wait := time.Since(entry.JoinedAt)
+		result["queued"] = true
+		result["rating"] = entry.Rating
+		result["joined_at"] = entry.JoinedAt
+		result["wait_seconds"] = int(wait.Seconds())
+		result["window"] = h.Config.RatingWindow(wait)
+	}
+
+	if check, ok := h.Hub.Queue.ReadyCheckFor(userID); ok {
+		result["ready_check"] = gin.H{
+			"check_id": check.ID,
+			"opponent": check.Opponent(userID),
+			"accepted": check.Accepted[check.Slot(userID)],
+			"deadline": check.Deadline,
+		}
+	}
+
+	utils.SuccessResponse(c, result)
+}
+
+// ProcessQueue отменяет просроченные проверки готовности и подбирает новые пары.
+// Вызывается фоновой задачей.
+func (h *MatchmakingHandlers) ProcessQueue() {
+	now := time.Now()
+
+	for _, check := range h.Hub.Queue.Expire(now) {
+		h.notifyPlayers(check, "ranked_ready_cancelled", gin.H{
+			"check_id": check.ID,
+			"reason":   "timeout",
+		})
+	}
+
+	for _, check := range h.Hub.Queue.Pair(now, h.Config.RatingWindow, h.Config.ReadyTimeout) {
+		for _, player := range check.Players {
+			h.sendToUser(player.UserID, "ranked_match_found", gin.H{
+				"check_id":      check.ID,
+				"opponent":      check.Opponent(player.UserID),
+				"deadline":      check.Deadline,
+				"ready_timeout": int(h.Config.ReadyTimeout.Seconds()),
+			})
+		}
+
+		h.Logger.Info("Ranked players paired",
+			"check_id", check.ID,
+			"player1_id", check.Players[0].UserID,
+			"player2_id", check.Players[1].UserID,
+		)
+	}
+}
+
+// createRankedMatch создает для подтвержденной пары закрытую комнату и турнир
+// из одного матча в ладдере ranked. Результат матча отправляется и засчитывается
+// так же, как в обычном турнире.
+func (h *MatchmakingHandlers) createRankedMatch(check websocket.ReadyCheck) (rankedMatch, error) {
+	var match rankedMatch
+	p1, p2 := check.Players[0], check.Players[1]
+
+	tx, err := h.DB.Beginx()
+	if err != nil {
+		return match, err
+	}
+	defer tx.Rollback()
+
+	name := "Ranked: " + p1.Username + " vs " + p2.Username
+	err = tx.QueryRow(`
+		INSERT INTO rooms (name, host_id, max_players, current_count, status, is_private)
+		VALUES ($1, $2, 2, 2, $3, true)
+		RETURNING id
+	`, name, p1.UserID, models.RoomStatusInProgress).Scan(&match.RoomID)
+	if err != nil {
+		return match, err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO room_participants (room_id, user_id)
+		VALUES ($1, $2), ($1, $3)
+	`, match.RoomID, p1.UserID, p2.UserID)
+	if err != nil {
+		return match, err
+	}
+
+	bracket, err := tournament.GenerateBracket([]tournament.Player{
+		{ID: p1.UserID, Username: p1.Username, Rating: p1.Rating},
+		{ID: p2.UserID, Username: p2.Username, Rating: p2.Rating},
+	})
+	if err != nil {
+		return match, err
+	}
+
+	bracket.Series = &tournament.SeriesFormat{BestOf: 1}
+	bracket.Series.Apply(bracket.Matches)
+
+	err = tx.QueryRow(`
+		INSERT INTO tournaments (room_id, name, status, format, match_deadline_minutes, walkover_rule, ladder, created_at)
+		VALUES ($1, $2, 'started', $3, $4, $5, $6, CURRENT_TIMESTAMP)
+		RETURNING id
+	`, match.RoomID, name, models.TournamentFormatSingleElimination,
+		int(h.Tournaments.Config.MatchDeadline.Minutes()), h.Tournaments.Config.WalkoverRule,
+		models.RatingTypeRanked).Scan(&match.TournamentID)
+	if err != nil {
+		return match, err
+	}
+
+	bracket.TournamentID = match.TournamentID
+	bracketJSON, err := json.Marshal(bracket)
+	if err != nil {
+		return match, err
+	}
+
+	if _, err = tx.Exec(`UPDATE tournaments SET bracket = $1 WHERE id = $2`, bracketJSON, match.TournamentID); err != nil {
+		return match, err
+	}
+
+	if err = h.Tournaments.insertBracketMatches(tx, match.TournamentID, bracket.Matches, 0); err != nil {
+		return match, err
+	}
+
+	if err = h.Tournaments.setMatchDeadlines(tx, match.TournamentID); err != nil {
+		return match, err
+	}
+
+	err = tx.Get(&match.MatchID, `SELECT id FROM matches WHERE tournament_id = $1`, match.TournamentID)
+	if err != nil {
+		return match, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return match, err
+	}
+
+	h.Logger.Info("Ranked match created",
+		"tournament_id", match.TournamentID,
+		"match_id", match.MatchID,
+		"player1_id", p1.UserID,
+		"player2_id", p2.UserID,
+	)
+
+	return match, nil
+}
+
+// notifyRankedMatchCreated сообщает обоим игрокам о созданном матче
+func (h *MatchmakingHandlers) notifyRankedMatchCreated(check websocket.ReadyCheck, match rankedMatch) {
+	for _, player := range check.Players {
+		h.sendToUser(player.UserID, "ranked_match_created", gin.H{
+			"check_id":      check.ID,
+			"room_id":       match.RoomID,
+			"tournament_id": match.TournamentID,
+			"match_id":      match.MatchID,
+			"opponent":      check.Opponent(player.UserID),
+		})
+	}
+}
+
+// notifyReadyCancelled сообщает сопернику об отказе игрока от матча
+func (h *MatchmakingHandlers) notifyReadyCancelled(check websocket.ReadyCheck, declinedBy int) {
+	opponent := check.Opponent(declinedBy)
+	h.sendToUser(opponent.UserID, "ranked_ready_cancelled", gin.H{
+		"check_id": check.ID,
+		"reason":   "declined",
+		"requeued": check.Accepted[check.Slot(opponent.UserID)],
+	})
+}
+
+// notifyPlayers отправляет событие обоим игрокам пары с признаком возврата в очередь
+func (h *MatchmakingHandlers) notifyPlayers(check websocket.ReadyCheck, msgType string, data gin.H) {
+	for i, player := range check.Players {
+		payload := gin.H{"requeued": check.Accepted[i]}
+		for k, v := range data {
+			payload[k] = v
+		}
+		h.sendToUser(player.UserID, msgType, payload)
+	}
+}
+
+// sendToUser отправляет событие очереди всем подключениям пользователя
+func (h *MatchmakingHandlers) sendToUser(userID int, msgType string, data gin.H) {
+	msgBytes, err := json.Marshal(models.WSMessage{Type: msgType, Data: data})
+	if err != nil {
+		h.Logger.Error("Failed to marshal ranked queue event", "type", msgType, "error", err)
+		return
+	}
+	h.Hub.SendToUser(userID, msgBytes)
+}
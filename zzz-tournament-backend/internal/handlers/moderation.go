@@ -0,0 +1,620 @@
+// internal/handlers/moderation.go
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"sort"
+	"strconv"
+	"time"
+
+	"zzz-tournament/internal/models"
+	"zzz-tournament/internal/websocket"
+	"zzz-tournament/pkg/config"
+	"zzz-tournament/pkg/utils"
+	"zzz-tournament/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// События журнала безопасности, связанные с договорными результатами
+const (
+	SecurityEventCollusionSuspected = "collusion_suspected"
+	SecurityEventCollusionReviewed  = "collusion_flag_reviewed"
+)
+
+// collusionMatchFilter условие матчей, по которым ищутся договорные результаты:
+// завершенные матчи игроков, изменившие рейтинг (без технических побед)
+const collusionMatchFilter = headToHeadFilter + ` AND m.is_walkover = false`
+
+// ModerationHandlers обработчики поиска и проверки договорных результатов
+type ModerationHandlers struct {
+	BaseHandlers
+	Config *config.CollusionConfig
+}
+
+// NewModerationHandlers создает новый экземпляр ModerationHandlers
+func NewModerationHandlers(db *sqlx.DB, hub *websocket.Hub, logger *slog.Logger, collusionConfig *config.CollusionConfig) *ModerationHandlers {
+	return &ModerationHandlers{
+		BaseHandlers: newBaseHandlers(db, hub, logger),
+		Config:       collusionConfig,
+	}
+}
+
+// ReviewCollusionFlagRequest структура запроса решения по метке
+type ReviewCollusionFlagRequest struct {
+	Status string `json:"status" binding:"required"` // confirmed, dismissed
+	Note   string `json:"note" binding:"max=500"`
+}
+
+// CollusionFlagMatch матч, на основании которого поставлена метка
+type CollusionFlagMatch struct {
+	ID             int       `json:"id" db:"id"`
+	TournamentID   int       `json:"tournament_id" db:"tournament_id"`
+	TournamentName string    `json:"tournament_name" db:"tournament_name"`
+	Ladder         string    `json:"ladder" db:"ladder"`
+	Player1ID      int       `json:"player1_id" db:"player1_id"`
+	Player2ID      int       `json:"player2_id" db:"player2_id"`
+	WinnerID       int       `json:"winner_id" db:"winner_id"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	FinishedAt     time.Time `json:"finished_at" db:"finished_at"`
+}
+
+// collusionSuspect найденное подозрение до сохранения
+type collusionSuspect struct {
+	Pattern     string
+	UserID      int
+	PartnerID   int
+	MatchIDs    pq.Int64Array
+	LastMatchAt time.Time
+	Details     gin.H
+}
+
+// DetectCollusion ищет договорные результаты в матчах за последние WindowDays дней
+// и сохраняет новые метки. Возвращает количество новых меток. Вызывается фоновой задачей.
+func (h *ModerationHandlers) DetectCollusion() (int, error) {
+	suspects, err := h.detectPairPatterns()
+	if err != nil {
+		return 0, err
+	}
+
+	feeders, err := h.detectFeederAccounts()
+	if err != nil {
+		return 0, err
+	}
+	suspects = append(suspects, feeders...)
+
+	flagged := 0
+	for _, suspect := range suspects {
+		created, err := h.saveFlag(suspect)
+		if err != nil {
+			return flagged, err
+		}
+		if created {
+			flagged++
+		}
+	}
+
+	if flagged > 0 {
+		h.Logger.Warn("Suspected collusion flagged", "flags", flagged)
+		h.notifyAdmins(flagged)
+	}
+
+	return flagged, nil
+}
+
+// detectPairPatterns ищет подозрительные серии встреч одной пары игроков:
+// частые встречи в разных турнирах, результаты сразу после создания матча
+// и строгое чередование побед
+func (h *ModerationHandlers) detectPairPatterns() ([]collusionSuspect, error) {
+	var matches []CollusionFlagMatch
+	err := h.DB.Select(&matches, `
+		SELECT m.id, COALESCE(m.tournament_id, 0) as tournament_id, m.player1_id, m.player2_id,
+		       m.winner_id, m.created_at, m.updated_at as finished_at
+		FROM matches m
+		LEFT JOIN tournaments t ON m.tournament_id = t.id
+		WHERE `+collusionMatchFilter+`
+		  AND m.updated_at >= CURRENT_TIMESTAMP - $1 * INTERVAL '1 day'
+		ORDER BY m.updated_at, m.id
+	`, h.Config.WindowDays)
+	if err != nil {
+		return nil, err
+	}
+
+	type pair struct{ low, high int }
+	pairs := make(map[pair][]CollusionFlagMatch)
+	for _, m := range matches {
+		key := pair{m.Player1ID, m.Player2ID}
+		if key.low > key.high {
+			key.low, key.high = key.high, key.low
+		}
+		pairs[key] = append(pairs[key], m)
+	}
+
+	keys := make([]pair, 0, len(pairs))
+	for key := range pairs {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].low == keys[j].low {
+			return keys[i].high < keys[j].high
+		}
+		return keys[i].low < keys[j].low
+	})
+
+	var suspects []collusionSuspect
+	for _, key := range keys {
+		meetings := pairs[key]
+		suspect := func(pattern string, evidence []CollusionFlagMatch, details gin.H) collusionSuspect {
+			s := collusionSuspect{Pattern: pattern, UserID: key.low, PartnerID: key.high, Details: details}
+			for _, m := range evidence {
+				s.MatchIDs = append(s.MatchIDs, int64(m.ID))
+			}
+			s.LastMatchAt = evidence[len(evidence)-1].FinishedAt
+			details["matches"] = len(evidence)
+			details["user_wins"] = countWins(evidence, key.low)
+			details["partner_wins"] = countWins(evidence, key.high)
+			return s
+		}
+
+		// Частые встречи: каждая встреча в отдельном турнире, как у рейтинговых
+		// матчей из очереди, а не несколько матчей одной сетки
+		tournaments := make(map[int]bool)
+		for _, m := range meetings {
+			tournaments[m.TournamentID] = true
+		}
+		if len(tournaments) >= h.Config.RepeatedMatches {
+			suspects = append(suspects, suspect(models.CollusionPatternRepeatedPair, meetings, gin.H{
+				"tournaments": len(tournaments),
+				"window_days": h.Config.WindowDays,
+			}))
+		}
+
+		// Результат засчитан вскоре после создания матча
+		var fast []CollusionFlagMatch
+		for _, m := range meetings {
+			if m.FinishedAt.Sub(m.CreatedAt) <= h.Config.FastResult {
+				fast = append(fast, m)
+			}
+		}
+		if len(fast) >= h.Config.FastResultMatches {
+			suspects = append(suspects, suspect(models.CollusionPatternFastResult, fast, gin.H{
+				"threshold_seconds": int(h.Config.FastResult.Seconds()),
+			}))
+		}
+
+		// Самая длинная серия встреч со строгим чередованием побед
+		if run := longestAlternatingRun(meetings); len(run) >= h.Config.AlternatingMatches {
+			suspects = append(suspects, suspect(models.CollusionPatternAlternatingWins, run, gin.H{}))
+		}
+	}
+
+	return suspects, nil
+}
+
+// detectFeederAccounts ищет новые аккаунты, которые сыграли несколько матчей
+// только против одного игрока и все их проиграли
+func (h *ModerationHandlers) detectFeederAccounts() ([]collusionSuspect, error) {
+	var rows []struct {
+		UserID      int           `db:"user_id"`
+		PartnerID   int           `db:"partner_id"`
+		MatchIDs    pq.Int64Array `db:"match_ids"`
+		LastMatchAt time.Time     `db:"last_match_at"`
+		AccountAge  int           `db:"account_age_days"`
+	}
+	err := h.DB.Select(&rows, `
+		SELECT u.id as user_id,
+		       MIN(CASE WHEN m.player1_id = u.id THEN m.player2_id ELSE m.player1_id END) as partner_id,
+		       array_agg(m.id ORDER BY m.updated_at, m.id) as match_ids,
+		       MAX(m.updated_at) as last_match_at,
+		       EXTRACT(DAY FROM CURRENT_TIMESTAMP - u.created_at)::int as account_age_days
+		FROM users u
+		JOIN matches m ON m.player1_id = u.id OR m.player2_id = u.id
+		LEFT JOIN tournaments t ON m.tournament_id = t.id
+		WHERE u.created_at >= CURRENT_TIMESTAMP - $1 * INTERVAL '1 day'
+		  AND `+collusionMatchFilter+`
+		GROUP BY u.id, u.created_at
+		HAVING COUNT(DISTINCT CASE WHEN m.player1_id = u.id THEN m.player2_id ELSE m.player1_id END) = 1
+		   AND COUNT(*) FILTER (WHERE m.winner_id = u.id) = 0
+		   AND COUNT(*) >= $2
+		ORDER BY u.id
+	`, h.Config.NewAccountDays, h.Config.FeederLosses)
+	if err != nil {
+		return nil, err
+	}
+
+	suspects := make([]collusionSuspect, 0, len(rows))
+	for _, row := range rows {
+		suspects = append(suspects, collusionSuspect{
+			Pattern:     models.CollusionPatternFeederAccount,
+			UserID:      row.UserID,
+			PartnerID:   row.PartnerID,
+			MatchIDs:    row.MatchIDs,
+			LastMatchAt: row.LastMatchAt,
+			Details: gin.H{
+				"losses":           len(row.MatchIDs),
+				"account_age_days": row.AccountAge,
+			},
+		})
+	}
+
+	return suspects, nil
+}
+
+// saveFlag сохраняет метку и запись в журнале безопасности. Пока метка пары
+// открыта, в ней обновляются доказательства; после решения модератора метка
+// ставится заново, только если пара сыграла новые матчи.
+func (h *ModerationHandlers) saveFlag(s collusionSuspect) (bool, error) {
+	tx, err := h.DB.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	details, err := json.Marshal(s.Details)
+	if err != nil {
+		return false, err
+	}
+
+	var openID int
+	err = tx.Get(&openID, `
+		SELECT id FROM collusion_flags
+		WHERE pattern = $1 AND user_id = $2 AND partner_id = $3 AND status = 'open'
+		FOR UPDATE
+	`, s.Pattern, s.UserID, s.PartnerID)
+	if err == nil {
+		_, err = tx.Exec(`
+			UPDATE collusion_flags
+			SET match_ids = $1, last_match_at = $2, details = $3, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $4 AND last_match_at < $2
+		`, s.MatchIDs, s.LastMatchAt, details, openID)
+		if err != nil {
+			return false, err
+		}
+		return false, tx.Commit()
+	}
+	if err != sql.ErrNoRows {
+		return false, err
+	}
+
+	var reviewed bool
+	err = tx.Get(&reviewed, `
+		SELECT EXISTS(
+			SELECT 1 FROM collusion_flags
+			WHERE pattern = $1 AND user_id = $2 AND partner_id = $3 AND last_match_at >= $4
+		)
+	`, s.Pattern, s.UserID, s.PartnerID, s.LastMatchAt)
+	if err != nil {
+		return false, err
+	}
+	if reviewed {
+		return false, nil
+	}
+
+	eventDetails, err := json.Marshal(gin.H{
+		"pattern":    s.Pattern,
+		"partner_id": s.PartnerID,
+		"match_ids":  s.MatchIDs,
+		"details":    s.Details,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	var eventID int
+	err = tx.QueryRow(`
+		INSERT INTO security_events (user_id, event, details)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`, s.UserID, SecurityEventCollusionSuspected, eventDetails).Scan(&eventID)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO collusion_flags (pattern, user_id, partner_id, match_ids, last_match_at, details, security_event_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, s.Pattern, s.UserID, s.PartnerID, s.MatchIDs, s.LastMatchAt, details, eventID)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// notifyAdmins сообщает администраторам о новых метках
+func (h *ModerationHandlers) notifyAdmins(flagged int) {
+	adminIDs, err := h.adminIDs()
+	if err != nil {
+		h.Logger.Error("Failed to load administrators", "error", err)
+		return
+	}
+
+	msgBytes, _ := json.Marshal(models.WSMessage{
+		Type: "collusion_flagged",
+		Data: gin.H{"flags": flagged},
+	})
+	for _, id := range adminIDs {
+		h.Hub.SendToUser(id, msgBytes)
+	}
+}
+
+// RunCollusionScan запускает поиск договорных результатов вне расписания (только админ)
+func (h *ModerationHandlers) RunCollusionScan(c *gin.Context) {
+	flagged, err := h.DetectCollusion()
+	if err != nil {
+		h.Logger.Error("Failed to detect collusion", "error", err)
+		utils.InternalErrorResponse(c, "Failed to detect collusion")
+		return
+	}
+
+	utils.SuccessResponse(c, gin.H{"flagged": flagged}, "Collusion scan completed")
+}
+
+// GetCollusionFlags список меток для проверки (только админ)
+func (h *ModerationHandlers) GetCollusionFlags(c *gin.Context) {
+	page := getPageFromQuery(c, 1)
+	perPage := getPerPageFromQuery(c, 20)
+
+	if err := validator.ValidatePage(page); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	if err := validator.ValidatePerPage(perPage); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	where := "WHERE 1=1"
+	args := []interface{}{}
+
+	status := c.DefaultQuery("status", models.CollusionFlagOpen)
+	if status != "all" {
+		if !models.IsValidCollusionFlagStatus(status) {
+			utils.BadRequestResponse(c, "Invalid status, expected open, confirmed, dismissed or all")
+			return
+		}
+		args = append(args, status)
+		where += " AND f.status = $" + strconv.Itoa(len(args))
+	}
+
+	if pattern := c.Query("pattern"); pattern != "" {
+		if !models.IsValidCollusionPattern(pattern) {
+			utils.BadRequestResponse(c, "Invalid pattern")
+			return
+		}
+		args = append(args, pattern)
+		where += " AND f.pattern = $" + strconv.Itoa(len(args))
+	}
+
+	if userIDStr := c.Query("user_id"); userIDStr != "" {
+		userID, err := strconv.Atoi(userIDStr)
+		if err != nil {
+			utils.BadRequestResponse(c, "Invalid user ID")
+			return
+		}
+		args = append(args, userID)
+		where += " AND (f.user_id = $" + strconv.Itoa(len(args)) + " OR f.partner_id = $" + strconv.Itoa(len(args)) + ")"
+	}
+
+	var total int
+	if err := h.DB.Get(&total, `SELECT COUNT(*) FROM collusion_flags f `+where, args...); err != nil {
+		utils.InternalErrorResponse(c, "Failed to count flags")
+		return
+	}
+
+	args = append(args, perPage, (page-1)*perPage)
+	flags := []models.CollusionFlag{}
+	err := h.DB.Select(&flags, `
+		SELECT f.id, f.pattern, f.user_id, u.username, f.partner_id, p.username as partner_username,
+		       f.match_ids, f.last_match_at, f.details, f.security_event_id, f.status,
+		       f.reviewed_by, f.reviewed_at, f.review_note, f.created_at, f.updated_at
+		FROM collusion_flags f
+		JOIN users u ON f.user_id = u.id
+		JOIN users p ON f.partner_id = p.id
+		`+where+`
+		ORDER BY f.created_at DESC, f.id DESC
+		LIMIT $`+strconv.Itoa(len(args)-1)+` OFFSET $`+strconv.Itoa(len(args)), args...)
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to fetch flags")
+		return
+	}
+
+	pagination := utils.NewPaginationMeta(page, perPage, total)
+
+	utils.PaginatedSuccessResponse(c, flags, pagination, "Collusion flags fetched successfully")
+}
+
+// GetCollusionFlag метка с матчами и записью журнала безопасности (только админ)
+func (h *ModerationHandlers) GetCollusionFlag(c *gin.Context) {
+	flagID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid flag ID")
+		return
+	}
+
+	flag, err := h.getFlag(h.DB, flagID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "Flag not found")
+		} else {
+			utils.InternalErrorResponse(c, "Database error")
+		}
+		return
+	}
+
+	matches := []CollusionFlagMatch{}
+	err = h.DB.Select(&matches, `
+		SELECT m.id, COALESCE(m.tournament_id, 0) as tournament_id, COALESCE(t.name, '') as tournament_name,
+		       COALESCE(t.ladder, '') as ladder, m.player1_id, m.player2_id, COALESCE(m.winner_id, 0) as winner_id,
+		       m.created_at, m.updated_at as finished_at
+		FROM matches m
+		LEFT JOIN tournaments t ON m.tournament_id = t.id
+		WHERE m.id = ANY($1)
+		ORDER BY m.updated_at, m.id
+	`, flag.MatchIDs)
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to fetch flag matches")
+		return
+	}
+
+	var event *models.SecurityEvent
+	if flag.SecurityEventID != nil {
+		var row struct {
+			ID        int       `db:"id"`
+			UserID    *int      `db:"user_id"`
+			Event     string    `db:"event"`
+			Details   []byte    `db:"details"`
+			CreatedAt time.Time `db:"created_at"`
+		}
+		err = h.DB.Get(&row, `
+			SELECT id, user_id, event, details, created_at FROM security_events WHERE id = $1
+		`, *flag.SecurityEventID)
+		if err != nil && err != sql.ErrNoRows {
+			utils.InternalErrorResponse(c, "Failed to fetch security event")
+			return
+		}
+		if err == nil {
+			event = &models.SecurityEvent{ID: row.ID, UserID: row.UserID, Event: row.Event, CreatedAt: row.CreatedAt}
+			json.Unmarshal(row.Details, &event.Details)
+		}
+	}
+
+	utils.SuccessResponse(c, gin.H{
+		"flag":           flag,
+		"matches":        matches,
+		"security_event": event,
+	})
+}
+
+// ReviewCollusionFlag решение модератора по метке: подтвердить или отклонить (только админ)
+func (h *ModerationHandlers) ReviewCollusionFlag(c *gin.Context) {
+	flagID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid flag ID")
+		return
+	}
+
+	var req ReviewCollusionFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	if req.Status != models.CollusionFlagConfirmed && req.Status != models.CollusionFlagDismissed {
+		utils.BadRequestResponse(c, "Invalid status, expected confirmed or dismissed")
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	tx, err := h.DB.Beginx()
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.Get(&status, `SELECT status FROM collusion_flags WHERE id = $1 FOR UPDATE`, flagID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "Flag not found")
+		} else {
+			utils.InternalErrorResponse(c, "Database error")
+		}
+		return
+	}
+
+	if status != models.CollusionFlagOpen {
+		utils.ConflictResponse(c, "Flag has already been reviewed")
+		return
+	}
+
+	var note *string
+	if req.Note != "" {
+		note = &req.Note
+	}
+
+	_, err = tx.Exec(`
+		UPDATE collusion_flags
+		SET status = $1, reviewed_by = $2, reviewed_at = CURRENT_TIMESTAMP, review_note = $3,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4
+	`, req.Status, userID, note, flagID)
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to review flag")
+		return
+	}
+
+	eventDetails, _ := json.Marshal(gin.H{"flag_id": flagID, "status": req.Status})
+	_, err = tx.Exec(`
+		INSERT INTO security_events (user_id, event, client_ip, user_agent, details)
+		VALUES ($1, $2, $3, $4, $5)
+	`, userID, SecurityEventCollusionReviewed, c.ClientIP(), c.Request.UserAgent(), eventDetails)
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to log security event")
+		return
+	}
+
+	flag, err := h.getFlag(tx, flagID)
+	if err != nil {
+		utils.InternalErrorResponse(c, "Database error")
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		utils.InternalErrorResponse(c, "Failed to commit transaction")
+		return
+	}
+
+	h.Logger.Info("Collusion flag reviewed", "flag_id", flagID, "status", req.Status, "reviewed_by", userID)
+
+	utils.SuccessResponse(c, flag, "Flag reviewed successfully")
+}
+
+// getFlag возвращает метку с именами игроков
+func (h *ModerationHandlers) getFlag(q sqlx.Queryer, flagID int) (models.CollusionFlag, error) {
+	var flag models.CollusionFlag
+	err := sqlx.Get(q, &flag, `
+		SELECT f.id, f.pattern, f.user_id, u.username, f.partner_id, p.username as partner_username,
+		       f.match_ids, f.last_match_at, f.details, f.security_event_id, f.status,
+		       f.reviewed_by, f.reviewed_at, f.review_note, f.created_at, f.updated_at
+		FROM collusion_flags f
+		JOIN users u ON f.user_id = u.id
+		JOIN users p ON f.partner_id = p.id
+		WHERE f.id = $1
+	`, flagID)
+	return flag, err
+}
+
+// longestAlternatingRun возвращает самую длинную серию подряд идущих встреч,
+// в которой победитель меняется каждый матч
+func longestAlternatingRun(meetings []CollusionFlagMatch) []CollusionFlagMatch {
+	bestStart, bestLen := 0, 0
+	start := 0
+	for i := range meetings {
+		if i > 0 && meetings[i].WinnerID == meetings[i-1].WinnerID {
+			start = i
+		}
+		if i-start+1 > bestLen {
+			bestStart, bestLen = start, i-start+1
+		}
+	}
+	return meetings[bestStart : bestStart+bestLen]
+}
+
+// countWins считает победы игрока в матчах
+func countWins(matches []CollusionFlagMatch, userID int) int {
+	wins := 0
+	for _, m := range matches {
+		if m.WinnerID == userID {
+			wins++
+		}
+	}
+	return wins
+}
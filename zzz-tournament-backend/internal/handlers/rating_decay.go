@@ -0,0 +1,68 @@
+// internal/handlers/rating_decay.go
+package handlers
+
+import (
+	"zzz-tournament/internal/models"
+)
+
+// ProcessRatingDecay снижает рейтинг неактивных игроков во всех ладдерах.
+// Рейтинг выше порога уменьшается не чаще раза в день и не опускается ниже порога;
+// провизорные игроки не затрагиваются. Каждое снижение записывается в историю рейтинга.
+func (h *UserHandlers) ProcessRatingDecay() error {
+	if h.Config.DecayAmount == 0 {
+		return nil
+	}
+
+	tx, err := h.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var decayed []struct {
+		UserID int    `db:"user_id"`
+		Ladder string `db:"rating_type"`
+	}
+	err = tx.Select(&decayed, `
+		WITH decayed AS (
+			UPDATE user_ratings ur
+			SET rating = GREATEST(old.rating - $1::int, $2::int),
+			    rating_decayed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+			FROM user_ratings old
+			WHERE old.user_id = ur.user_id AND old.ladder = ur.ladder
+			  AND ur.rating > $2::int
+			  AND ur.rated_matches >= $3
+			  AND COALESCE(ur.rating_updated_at, ur.created_at) <= CURRENT_TIMESTAMP - $4 * INTERVAL '1 day'
+			  AND (ur.rating_decayed_at IS NULL OR ur.rating_decayed_at <= CURRENT_TIMESTAMP - INTERVAL '1 day')
+			RETURNING ur.user_id, ur.ladder, old.rating as rating_before, ur.rating as rating_after, ur.rating_deviation
+		)
+		INSERT INTO rating_history (user_id, rating_type, reason, rating_before, rating_after, delta, rating_deviation)
+		SELECT user_id, ladder, $5, rating_before, rating_after, rating_after - rating_before, rating_deviation
+		FROM decayed
+		RETURNING user_id, rating_type
+	`, h.Config.DecayAmount, h.Config.DecayThreshold, h.Config.PlacementMatches,
+		h.Config.DecayAfterDays, models.RatingReasonDecay)
+	if err != nil {
+		return err
+	}
+
+	// Турнирный ладдер дублируется в основной рейтинг пользователя
+	for _, d := range decayed {
+		if d.Ladder != models.RatingTypeTournament {
+			continue
+		}
+		if err = syncMainRating(tx, d.UserID); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	if len(decayed) > 0 {
+		h.Logger.Info("Rating decay applied", "ratings", len(decayed))
+	}
+
+	return nil
+}
@@ -0,0 +1,215 @@
+// internal/handlers/rating_history.go
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"zzz-tournament/internal/models"
+	"zzz-tournament/pkg/rating"
+	"zzz-tournament/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// RatingHistoryQuery параметры выборки истории рейтинга
+type RatingHistoryQuery struct {
+	From     string `form:"from"`     // Дата начала (YYYY-MM-DD)
+	To       string `form:"to"`       // Дата окончания включительно (YYYY-MM-DD)
+	Interval string `form:"interval"` // Группировка: day, week; без параметра - каждое изменение
+	Ladder   string `form:"ladder"`   // tournament (по умолчанию), ranked, casual
+}
+
+// RatingHistoryPeriod изменение рейтинга за день или неделю
+type RatingHistoryPeriod struct {
+	Period       time.Time `json:"period" db:"period"`
+	RatingBefore int       `json:"rating_before" db:"rating_before"` // Рейтинг на начало периода
+	Rating       int       `json:"rating" db:"rating"`               // Рейтинг на конец периода
+	MinRating    int       `json:"min_rating" db:"min_rating"`
+	MaxRating    int       `json:"max_rating" db:"max_rating"`
+	Delta        int       `json:"delta" db:"delta"`
+	Matches      int       `json:"matches" db:"matches"`
+}
+
+// ratingChange формирует запись истории рейтинга в ладдере по состояниям до и после матча
+func ratingChange(ladder string, userID, opponentID, matchID int, won bool, before, after rating.State) models.RatingHistory {
+	return models.RatingHistory{
+		UserID:          userID,
+		MatchID:         &matchID,
+		OpponentID:      &opponentID,
+		Won:             &won,
+		RatingType:      ladder,
+		Reason:          models.RatingReasonMatch,
+		RatingBefore:    before.Rating,
+		RatingAfter:     after.Rating,
+		Delta:           after.Rating - before.Rating,
+		RatingDeviation: after.Deviation,
+	}
+}
+
+// recordRatingChange записывает изменение рейтинга в историю в рамках транзакции
+func recordRatingChange(tx *sqlx.Tx, change models.RatingHistory) error {
+	_, err := tx.Exec(`
+		INSERT INTO rating_history (user_id, match_id, opponent_id, won, rating_type, reason,
+		                            rating_before, rating_after, delta, rating_deviation)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, change.UserID, change.MatchID, change.OpponentID, change.Won, change.RatingType, change.Reason,
+		change.RatingBefore, change.RatingAfter, change.Delta, change.RatingDeviation)
+	return err
+}
+
+// filter проверяет параметры и строит условия выборки
+func (q *RatingHistoryQuery) filter(userID int) (string, []interface{}, error) {
+	if q.Ladder == "" {
+		q.Ladder = models.RatingTypeTournament
+	}
+	if !models.IsValidLadder(q.Ladder) {
+		return "", nil, errors.New("ladder must be one of: tournament, ranked, casual")
+	}
+
+	args := []interface{}{userID, q.Ladder}
+	where := "rh.user_id = $1 AND rh.rating_type = $2"
+
+	if q.Interval != "" && q.Interval != "day" && q.Interval != "week" {
+		return "", nil, errors.New("interval must be one of: day, week")
+	}
+
+	if q.From != "" {
+		from, err := time.Parse("2006-01-02", q.From)
+		if err != nil {
+			return "", nil, errors.New("from must be a date in YYYY-MM-DD format")
+		}
+		args = append(args, from)
+		where += " AND rh.created_at >= $" + strconv.Itoa(len(args))
+	}
+
+	if q.To != "" {
+		to, err := time.Parse("2006-01-02", q.To)
+		if err != nil {
+			return "", nil, errors.New("to must be a date in YYYY-MM-DD format")
+		}
+		args = append(args, to.AddDate(0, 0, 1))
+		where += " AND rh.created_at < $" + strconv.Itoa(len(args))
+	}
+
+	return where, args, nil
+}
+
+// GetRatingHistory история рейтинга пользователя для графиков
+func (h *UserHandlers) GetRatingHistory(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid user ID")
+		return
+	}
+
+	var query RatingHistoryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters")
+		return
+	}
+
+	where, args, err := query.filter(userID)
+	if err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	var user models.User
+	err = h.DB.Get(&user, `SELECT id, username, rating, wins, losses, rated_matches FROM users WHERE id = $1`, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "User not found")
+		} else {
+			utils.InternalErrorResponse(c, "Database error")
+		}
+		return
+	}
+
+	current, err := ladderRating(h.DB, &user, query.Ladder)
+	if err != nil {
+		utils.InternalErrorResponse(c, "Database error")
+		return
+	}
+
+	// Пиковый рейтинг в ладдере за все время
+	peakRating := current.Rating
+	var peakAt *time.Time
+	var peak struct {
+		Rating    int       `db:"rating_after"`
+		CreatedAt time.Time `db:"created_at"`
+	}
+	err = h.DB.Get(&peak, `
+		SELECT rating_after, created_at FROM rating_history
+		WHERE user_id = $1 AND rating_type = $2
+		ORDER BY rating_after DESC, created_at ASC
+		LIMIT 1
+	`, userID, query.Ladder)
+	if err != nil && err != sql.ErrNoRows {
+		utils.InternalErrorResponse(c, "Failed to fetch peak rating")
+		return
+	}
+	if err == nil && peak.Rating >= peakRating {
+		peakRating = peak.Rating
+		peakAt = &peak.CreatedAt
+	}
+
+	provisional, placementLeft := h.placementStatus(current.RatedMatches)
+
+	response := gin.H{
+		"user_id":                user.ID,
+		"username":               user.Username,
+		"ladder":                 query.Ladder,
+		"rating":                 current.Rating,
+		"peak_rating":            peakRating,
+		"peak_at":                peakAt,
+		"provisional":            provisional,
+		"placement_matches_left": placementLeft,
+	}
+
+	if query.Interval == "" {
+		history := []models.RatingHistory{}
+		err = h.DB.Select(&history, `
+			SELECT rh.id, rh.user_id, rh.match_id, m.tournament_id, rh.opponent_id, rh.won, rh.rating_type, rh.reason,
+			       rh.rating_before, rh.rating_after, rh.delta, COALESCE(rh.rating_deviation, 0) as rating_deviation,
+			       rh.created_at
+			FROM rating_history rh
+			LEFT JOIN matches m ON rh.match_id = m.id
+			WHERE `+where+`
+			ORDER BY rh.created_at, rh.id
+		`, args...)
+		if err != nil {
+			utils.InternalErrorResponse(c, "Failed to fetch rating history")
+			return
+		}
+
+		response["history"] = history
+		utils.SuccessResponse(c, response)
+		return
+	}
+
+	args = append(args, query.Interval)
+	periods := []RatingHistoryPeriod{}
+	err = h.DB.Select(&periods, `
+		SELECT date_trunc($`+strconv.Itoa(len(args))+`, rh.created_at) as period,
+		       (array_agg(rh.rating_before ORDER BY rh.created_at, rh.id))[1] as rating_before,
+		       (array_agg(rh.rating_after ORDER BY rh.created_at DESC, rh.id DESC))[1] as rating,
+		       MIN(rh.rating_after) as min_rating, MAX(rh.rating_after) as max_rating,
+		       SUM(rh.delta) as delta, COUNT(*) FILTER (WHERE rh.reason = 'match') as matches
+		FROM rating_history rh
+		WHERE `+where+`
+		GROUP BY 1
+		ORDER BY 1
+	`, args...)
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to fetch rating history")
+		return
+	}
+
+	response["interval"] = query.Interval
+	response["history"] = periods
+	utils.SuccessResponse(c, response)
+}
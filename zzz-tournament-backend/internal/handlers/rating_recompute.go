@@ -0,0 +1,503 @@
+// internal/handlers/rating_recompute.go
+package handlers
+
+import (
+	"log/slog"
+	"math"
+	"sort"
+	"time"
+
+	"zzz-tournament/internal/models"
+	"zzz-tournament/internal/websocket"
+	"zzz-tournament/pkg/config"
+	"zzz-tournament/pkg/rating"
+	"zzz-tournament/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// RatingHandlers обработчики обслуживания рейтинга
+type RatingHandlers struct {
+	BaseHandlers
+	Config *config.RatingConfig
+	Engine rating.Engine
+}
+
+// NewRatingHandlers создает новый экземпляр RatingHandlers
+func NewRatingHandlers(db *sqlx.DB, hub *websocket.Hub, logger *slog.Logger, ratingConfig *config.RatingConfig) *RatingHandlers {
+	return &RatingHandlers{
+		BaseHandlers: newBaseHandlers(db, hub, logger),
+		Config:       ratingConfig,
+		Engine:       rating.NewEngine(ratingConfig.Engine, ratingConfig.IdlePeriod),
+	}
+}
+
+// RecomputeRatingsRequest структура запроса пересчета рейтингов
+type RecomputeRatingsRequest struct {
+	Commit bool `json:"commit"` // false - только отчет о различиях
+}
+
+// RatingRecomputeEntry различие текущего и пересчитанного рейтинга игрока в ладдере
+type RatingRecomputeEntry struct {
+	UserID    int    `json:"user_id"`
+	Username  string `json:"username"`
+	Ladder    string `json:"ladder"`
+	OldRating int    `json:"old_rating"`
+	NewRating int    `json:"new_rating"`
+	Delta     int    `json:"delta"`
+	OldWins   int    `json:"old_wins"`
+	NewWins   int    `json:"new_wins"`
+	OldLosses int    `json:"old_losses"`
+	NewLosses int    `json:"new_losses"`
+}
+
+// RatingRecomputeReport итоги пересчета рейтингов
+type RatingRecomputeReport struct {
+	Engine       string                 `json:"engine"`
+	Committed    bool                   `json:"committed"`
+	Matches      int                    `json:"matches"`   // Переиграно матчей, включая технические победы
+	Walkovers    int                    `json:"walkovers"` // Технические победы меняют только счетчики
+	SeasonResets int                    `json:"season_resets"`
+	DecayDropped int                    `json:"decay_dropped"` // Снижения за неактивность: остаются в истории, но не входят в новый рейтинг
+	Ratings      int                    `json:"ratings"`       // Всего рейтингов игроков в ладдерах
+	Changed      int                    `json:"changed"`
+	Entries      []RatingRecomputeEntry `json:"entries"` // Только изменившиеся рейтинги
+}
+
+// replayKey рейтинг игрока в ладдере
+type replayKey struct {
+	UserID int
+	Ladder string
+}
+
+// replayState рейтинговое состояние игрока при пересчете
+type replayState struct {
+	rating.State
+	Wins         int
+	Losses       int
+	RatedMatches int // Без технических побед
+	UpdatedAt    *time.Time
+}
+
+// replayMatch завершенный матч игроков для пересчета
+type replayMatch struct {
+	ID         int       `db:"id"`
+	Player1ID  int       `db:"player1_id"`
+	Player2ID  int       `db:"player2_id"`
+	WinnerID   int       `db:"winner_id"`
+	Ladder     string    `db:"ladder"`
+	IsWalkover bool      `db:"is_walkover"`
+	FinishedAt time.Time `db:"finished_at"`
+
+	// Результат пересчета
+	WinnerDelta  int            `db:"-"`
+	LoserDelta   int            `db:"-"`
+	WinnerBefore ratingSnapshot `db:"-"`
+	LoserBefore  ratingSnapshot `db:"-"`
+}
+
+// ratingReplay пересчет рейтингов по истории матчей
+type ratingReplay struct {
+	config  *config.RatingConfig
+	engine  rating.Engine
+	states  map[replayKey]*replayState
+	history []models.RatingHistory
+	report  RatingRecomputeReport
+}
+
+// RecomputeRatings пересчет всех рейтингов по истории матчей (только для админов)
+func (h *RatingHandlers) RecomputeRatings(c *gin.Context) {
+	var req RecomputeRatingsRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.BadRequestResponse(c, err.Error())
+			return
+		}
+	}
+
+	report, err := h.Recompute(req.Commit)
+	if err != nil {
+		h.Logger.Error("Failed to recompute ratings", "error", err)
+		utils.InternalErrorResponse(c, "Failed to recompute ratings")
+		return
+	}
+
+	if report.Committed {
+		h.Logger.Info("Ratings recomputed",
+			"admin_id", c.GetInt("user_id"), "matches", report.Matches, "changed", report.Changed)
+		utils.SuccessResponse(c, report, "Ratings recomputed successfully")
+		return
+	}
+
+	utils.SuccessResponse(c, report, "Dry run: ratings were not changed")
+}
+
+// Recompute переигрывает все завершенные матчи игроков в порядке фиксации результата
+// текущей рейтинговой системой, применяя сброс рейтинга на завершении сезонов.
+// Без commit возвращает только отчет о различиях. С commit перезаписывает рейтинги
+// в ладдерах, основной рейтинг пользователей, изменения рейтинга в матчах и историю
+// матчей, отмен и сезонных сбросов. Рейтинги команд не пересчитываются, а снижение
+// за неактивность не переигрывается: его записи остаются в истории, но новый
+// рейтинг их не учитывает. Их число возвращается в отчете.
+func (h *RatingHandlers) Recompute(commit bool) (*RatingRecomputeReport, error) {
+	tx, err := h.DB.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Новые результаты не должны появляться во время перезаписи рейтингов
+	if commit {
+		if _, err = tx.Exec(`LOCK TABLE matches IN SHARE MODE`); err != nil {
+			return nil, err
+		}
+	}
+
+	var matches []replayMatch
+	err = tx.Select(&matches, `
+		SELECT m.id, m.player1_id, m.player2_id, m.winner_id, COALESCE(t.ladder, 'tournament') as ladder,
+		       m.is_walkover, COALESCE(m.finished_at, m.updated_at) as finished_at
+		FROM matches m
+		LEFT JOIN tournaments t ON m.tournament_id = t.id
+		WHERE m.status = 'finished' AND m.winner_id IS NOT NULL
+		  AND m.player1_id IS NOT NULL AND m.player2_id IS NOT NULL
+		  AND COALESCE(t.team_mode, false) = false
+		ORDER BY COALESCE(m.finished_at, m.updated_at), m.id
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	var resets []time.Time
+	err = tx.Select(&resets, `
+		SELECT finished_at FROM seasons
+		WHERE status = 'finished' AND finished_at IS NOT NULL
+		ORDER BY finished_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	var decayDropped int
+	err = tx.Get(&decayDropped, `SELECT COUNT(*) FROM rating_history WHERE reason = 'decay'`)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []models.User
+	err = tx.Select(&users, `SELECT id, username, rating, wins, losses FROM users ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+
+	var ladders []models.UserRating
+	err = tx.Select(&ladders, `
+		SELECT user_id, ladder, rating, rating_deviation, wins, losses
+		FROM user_ratings WHERE ladder <> 'tournament'
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	replay := &ratingReplay{
+		config: h.Config,
+		engine: h.Engine,
+		states: make(map[replayKey]*replayState),
+	}
+	replay.report.Engine = h.Engine.Name()
+	replay.report.DecayDropped = decayDropped
+
+	// Турнирный рейтинг есть у всех пользователей и сбрасывается вместе с сезоном
+	for _, user := range users {
+		replay.state(user.ID, models.RatingTypeTournament)
+	}
+
+	for i := range matches {
+		for len(resets) > 0 && !resets[0].After(matches[i].FinishedAt) {
+			replay.seasonReset(resets[0])
+			resets = resets[1:]
+		}
+		replay.play(&matches[i])
+	}
+	for _, at := range resets {
+		replay.seasonReset(at)
+	}
+
+	replay.diff(users, ladders)
+
+	if !commit {
+		return &replay.report, nil
+	}
+
+	if err = replay.save(tx, matches); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	replay.report.Committed = true
+	return &replay.report, nil
+}
+
+// state возвращает состояние игрока в ладдере, создавая начальное
+func (r *ratingReplay) state(userID int, ladder string) *replayState {
+	key := replayKey{UserID: userID, Ladder: ladder}
+	state, ok := r.states[key]
+	if !ok {
+		state = &replayState{State: rating.State{
+			Rating:     rating.DefaultRating,
+			Deviation:  rating.DefaultDeviation,
+			Volatility: rating.DefaultVolatility,
+		}}
+		r.states[key] = state
+	}
+	return state
+}
+
+// play применяет результат матча так же, как updatePlayerRatings
+func (r *ratingReplay) play(match *replayMatch) {
+	loserID := match.Player1ID
+	if match.WinnerID == match.Player1ID {
+		loserID = match.Player2ID
+	}
+
+	winner := r.state(match.WinnerID, match.Ladder)
+	loser := r.state(loserID, match.Ladder)
+	r.report.Matches++
+
+	if match.IsWalkover {
+		winner.Wins++
+		loser.Losses++
+		r.report.Walkovers++
+		return
+	}
+
+	winnerBefore := r.before(winner, match.FinishedAt)
+	loserBefore := r.before(loser, match.FinishedAt)
+	newWinner, newLoser := r.engine.Rate(winnerBefore, loserBefore)
+
+	match.WinnerDelta = newWinner.Rating - winnerBefore.Rating
+	match.LoserDelta = newLoser.Rating - loserBefore.Rating
+	match.WinnerBefore = winner.snapshot()
+	match.LoserBefore = loser.snapshot()
+
+	winnerChange := ratingChange(match.Ladder, match.WinnerID, loserID, match.ID, true, winnerBefore, newWinner)
+	winnerChange.CreatedAt = match.FinishedAt
+	loserChange := ratingChange(match.Ladder, loserID, match.WinnerID, match.ID, false, loserBefore, newLoser)
+	loserChange.CreatedAt = match.FinishedAt
+	r.history = append(r.history, winnerChange, loserChange)
+
+	winner.Wins++
+	winner.RatedMatches++
+	winner.apply(newWinner, match.FinishedAt)
+	loser.Losses++
+	loser.RatedMatches++
+	loser.apply(newLoser, match.FinishedAt)
+}
+
+// before возвращает состояние игрока перед матчем, как его загружает loadRatingState
+func (r *ratingReplay) before(state *replayState, at time.Time) rating.State {
+	before := state.State
+	before.GamesPlayed = state.RatedMatches
+	if state.UpdatedAt != nil {
+		before.Idle = at.Sub(*state.UpdatedAt)
+	}
+	return before
+}
+
+// snapshot возвращает состояние игрока перед матчем для отмены результата
+func (s *replayState) snapshot() ratingSnapshot {
+	state := *s
+	return ratingSnapshot{
+		Rating:     &state.Rating,
+		Deviation:  &state.Deviation,
+		Volatility: &state.Volatility,
+		UpdatedAt:  state.UpdatedAt,
+	}
+}
+
+// apply сохраняет рейтинг игрока после матча
+func (s *replayState) apply(after rating.State, at time.Time) {
+	s.Rating = after.Rating
+	s.Deviation = after.Deviation
+	s.Volatility = after.Volatility
+	s.UpdatedAt = &at
+}
+
+// seasonReset сдвигает все рейтинги к среднему, как при завершении сезона
+func (r *ratingReplay) seasonReset(at time.Time) {
+	r.report.SeasonResets++
+
+	keys := r.sortedKeys()
+	for _, key := range keys {
+		state := r.states[key]
+		reset := rating.SoftReset(state.State, r.config.SeasonResetMean,
+			r.config.SeasonResetFactor, r.config.SeasonResetDeviation)
+
+		if reset.Rating != state.Rating {
+			r.history = append(r.history, models.RatingHistory{
+				UserID:          key.UserID,
+				RatingType:      key.Ladder,
+				Reason:          models.RatingReasonSeasonReset,
+				RatingBefore:    state.Rating,
+				RatingAfter:     reset.Rating,
+				Delta:           reset.Rating - state.Rating,
+				RatingDeviation: reset.Deviation,
+				CreatedAt:       at,
+			})
+		}
+
+		state.Rating = reset.Rating
+		state.Deviation = reset.Deviation
+	}
+}
+
+// diff сравнивает пересчитанные рейтинги с текущими
+func (r *ratingReplay) diff(users []models.User, ladders []models.UserRating) {
+	usernames := make(map[int]string, len(users))
+	old := make(map[replayKey]models.UserRating, len(users)+len(ladders))
+
+	for _, user := range users {
+		usernames[user.ID] = user.Username
+		old[replayKey{UserID: user.ID, Ladder: models.RatingTypeTournament}] = models.UserRating{
+			Rating: user.Rating, Wins: user.Wins, Losses: user.Losses,
+		}
+	}
+	for _, ladder := range ladders {
+		old[replayKey{UserID: ladder.UserID, Ladder: ladder.Ladder}] = ladder
+	}
+
+	// Рейтинги ладдеров, в которых не осталось матчей, будут удалены
+	for key := range old {
+		if _, ok := r.states[key]; !ok && key.Ladder != models.RatingTypeTournament {
+			r.state(key.UserID, key.Ladder)
+		}
+	}
+
+	r.report.Ratings = len(r.states)
+	r.report.Entries = []RatingRecomputeEntry{}
+
+	for _, key := range r.sortedKeys() {
+		state := r.states[key]
+		before, ok := old[key]
+		if !ok {
+			before = models.UserRating{Rating: rating.DefaultRating}
+		}
+
+		if before.Rating == state.Rating && before.Wins == state.Wins && before.Losses == state.Losses {
+			continue
+		}
+
+		r.report.Entries = append(r.report.Entries, RatingRecomputeEntry{
+			UserID:    key.UserID,
+			Username:  usernames[key.UserID],
+			Ladder:    key.Ladder,
+			OldRating: before.Rating,
+			NewRating: state.Rating,
+			Delta:     state.Rating - before.Rating,
+			OldWins:   before.Wins,
+			NewWins:   state.Wins,
+			OldLosses: before.Losses,
+			NewLosses: state.Losses,
+		})
+	}
+	r.report.Changed = len(r.report.Entries)
+
+	// Сначала самые большие изменения
+	sort.SliceStable(r.report.Entries, func(i, j int) bool {
+		return math.Abs(float64(r.report.Entries[i].Delta)) > math.Abs(float64(r.report.Entries[j].Delta))
+	})
+}
+
+// save записывает пересчитанные рейтинги и историю
+func (r *ratingReplay) save(tx *sqlx.Tx, matches []replayMatch) error {
+	if _, err := tx.Exec(`DELETE FROM user_ratings`); err != nil {
+		return err
+	}
+
+	for _, key := range r.sortedKeys() {
+		state := r.states[key]
+		// Ладдеры без матчей после пересчета не хранятся, кроме турнирного
+		if key.Ladder != models.RatingTypeTournament && state.Wins+state.Losses == 0 {
+			continue
+		}
+
+		_, err := tx.Exec(`
+			INSERT INTO user_ratings (user_id, ladder, rating, rating_deviation, rating_volatility,
+			                          wins, losses, rated_matches, rating_updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		`, key.UserID, key.Ladder, state.Rating, state.Deviation, state.Volatility,
+			state.Wins, state.Losses, state.RatedMatches, state.UpdatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := tx.Exec(`
+		UPDATE users u
+		SET rating = ur.rating, rating_deviation = ur.rating_deviation, rating_volatility = ur.rating_volatility,
+		    wins = ur.wins, losses = ur.losses, rated_matches = ur.rated_matches,
+		    rating_updated_at = ur.rating_updated_at, updated_at = CURRENT_TIMESTAMP
+		FROM user_ratings ur
+		WHERE ur.user_id = u.id AND ur.ladder = 'tournament'
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Изменения рейтинга и состояние перед матчем нужны для отмены результатов
+	for _, match := range matches {
+		winner, loser := match.WinnerBefore, match.LoserBefore
+		_, err = tx.Exec(`
+			UPDATE matches
+			SET winner_rating_delta = $1, loser_rating_delta = $2,
+			    winner_rating_before = $3, winner_deviation_before = $4,
+			    winner_volatility_before = $5, winner_rated_at_before = $6,
+			    loser_rating_before = $7, loser_deviation_before = $8,
+			    loser_volatility_before = $9, loser_rated_at_before = $10
+			WHERE id = $11
+		`, match.WinnerDelta, match.LoserDelta,
+			winner.Rating, winner.Deviation, winner.Volatility, winner.UpdatedAt,
+			loser.Rating, loser.Deviation, loser.Volatility, loser.UpdatedAt, match.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Снижение за неактивность не переигрывается, его записи сохраняются
+	if _, err = tx.Exec(`DELETE FROM rating_history WHERE reason <> 'decay'`); err != nil {
+		return err
+	}
+
+	for _, change := range r.history {
+		_, err = tx.Exec(`
+			INSERT INTO rating_history (user_id, match_id, opponent_id, won, rating_type, reason,
+			                            rating_before, rating_after, delta, rating_deviation, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		`, change.UserID, change.MatchID, change.OpponentID, change.Won, change.RatingType, change.Reason,
+			change.RatingBefore, change.RatingAfter, change.Delta, change.RatingDeviation, change.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sortedKeys возвращает рейтинги в стабильном порядке
+func (r *ratingReplay) sortedKeys() []replayKey {
+	keys := make([]replayKey, 0, len(r.states))
+	for key := range r.states {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].UserID != keys[j].UserID {
+			return keys[i].UserID < keys[j].UserID
+		}
+		return keys[i].Ladder < keys[j].Ladder
+	})
+	return keys
+}
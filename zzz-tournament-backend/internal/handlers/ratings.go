@@ -0,0 +1,263 @@
+// internal/handlers/ratings.go
+package handlers
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+
+	"zzz-tournament/internal/models"
+	"zzz-tournament/pkg/rating"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ratingTarget участники матча, чей рейтинг меняется по его итогам
+type ratingTarget struct {
+	Table    string // users - игроки в ладдере Ladder, teams - команды
+	Ladder   string
+	WinnerID int
+	LoserID  int
+}
+
+// ratingRow возвращает таблицу и условие строки рейтинга участника.
+// Условие использует параметры начиная с $first.
+func (t ratingTarget) ratingRow(id, first int) (string, string, []interface{}) {
+	if t.Table == "teams" {
+		return "teams", "id = $" + strconv.Itoa(first), []interface{}{id}
+	}
+	return "user_ratings", "user_id = $" + strconv.Itoa(first) + " AND ladder = $" + strconv.Itoa(first+1),
+		[]interface{}{id, t.Ladder}
+}
+
+// ensureUserRating создает рейтинг игрока в ладдере, если он еще не играл в нем.
+// Турнирный ладдер начинается с основного рейтинга пользователя.
+func ensureUserRating(tx *sqlx.Tx, userID int, ladder string) error {
+	if ladder == models.RatingTypeTournament {
+		_, err := tx.Exec(`
+			INSERT INTO user_ratings (user_id, ladder, rating, rating_deviation, rating_volatility,
+			                          wins, losses, rated_matches, rating_updated_at)
+			SELECT id, 'tournament', rating, rating_deviation, rating_volatility, wins, losses, rated_matches, rating_updated_at
+			FROM users WHERE id = $1
+			ON CONFLICT (user_id, ladder) DO NOTHING
+		`, userID)
+		return err
+	}
+
+	_, err := tx.Exec(`
+		INSERT INTO user_ratings (user_id, ladder) VALUES ($1, $2)
+		ON CONFLICT (user_id, ladder) DO NOTHING
+	`, userID, ladder)
+	return err
+}
+
+// syncMainRating копирует рейтинг турнирного ладдера в основной рейтинг пользователя
+func syncMainRating(tx *sqlx.Tx, userID int) error {
+	_, err := tx.Exec(`
+		UPDATE users u
+		SET rating = ur.rating, rating_deviation = ur.rating_deviation, rating_volatility = ur.rating_volatility,
+		    wins = ur.wins, losses = ur.losses, rated_matches = ur.rated_matches,
+		    rating_updated_at = ur.rating_updated_at, updated_at = CURRENT_TIMESTAMP
+		FROM user_ratings ur
+		WHERE ur.user_id = u.id AND ur.ladder = 'tournament' AND u.id = $1
+	`, userID)
+	return err
+}
+
+// loadRatingState загружает рейтинговое состояние игрока в ладдере или команды.
+// Сыгранными считаются только рейтинговые матчи, время без матчей считается в базе данных.
+func loadRatingState(tx *sqlx.Tx, target ratingTarget, id int) (rating.State, error) {
+	var state rating.State
+	var idleSeconds float64
+
+	if target.Table == "users" {
+		if err := ensureUserRating(tx, id, target.Ladder); err != nil {
+			return state, err
+		}
+	}
+
+	table, where, args := target.ratingRow(id, 1)
+	err := tx.QueryRow(`
+		SELECT rating, rating_deviation, rating_volatility, rated_matches,
+		       COALESCE(EXTRACT(EPOCH FROM CURRENT_TIMESTAMP - rating_updated_at), 0)
+		FROM `+table+` WHERE `+where, args...).
+		Scan(&state.Rating, &state.Deviation, &state.Volatility, &state.GamesPlayed, &idleSeconds)
+	if err != nil {
+		return state, err
+	}
+
+	state.Idle = time.Duration(idleSeconds * float64(time.Second))
+	return state, nil
+}
+
+// saveRatingResult засчитывает участнику победу или поражение и, если передано
+// новое состояние, сохраняет рейтинг и засчитывает рейтинговый матч. Турнирный
+// ладдер игрока дублируется в users.
+func saveRatingResult(tx *sqlx.Tx, target ratingTarget, id int, won bool, state *rating.State) error {
+	if target.Table == "users" {
+		if err := ensureUserRating(tx, id, target.Ladder); err != nil {
+			return err
+		}
+	}
+
+	set := "losses = losses + 1"
+	if won {
+		set = "wins = wins + 1"
+	}
+
+	var args []interface{}
+	if state != nil {
+		set += ", rated_matches = rated_matches + 1, rating = $1, rating_deviation = $2, rating_volatility = $3, rating_updated_at = CURRENT_TIMESTAMP"
+		args = append(args, state.Rating, state.Deviation, state.Volatility)
+	}
+
+	table, where, rowArgs := target.ratingRow(id, len(args)+1)
+	args = append(args, rowArgs...)
+
+	_, err := tx.Exec(`
+		UPDATE `+table+` SET `+set+`, updated_at = CURRENT_TIMESTAMP WHERE `+where, args...)
+	if err != nil {
+		return err
+	}
+
+	if target.Table == "users" && target.Ladder == models.RatingTypeTournament {
+		return syncMainRating(tx, id)
+	}
+	return nil
+}
+
+// ratingSnapshot рейтинговое состояние участника перед матчем. Rating равен nil,
+// если состояние не сохранялось: техническая победа или матч, сыгранный до
+// появления снимков.
+type ratingSnapshot struct {
+	Rating     *int
+	Deviation  *float64
+	Volatility *float64
+	UpdatedAt  *time.Time
+}
+
+// saveRatingSnapshot запоминает в матче рейтинговое состояние участника перед
+// матчем. side - winner или loser.
+func saveRatingSnapshot(tx *sqlx.Tx, target ratingTarget, matchID, id int, side string) error {
+	table, where, args := target.ratingRow(id, 2)
+	_, err := tx.Exec(`
+		UPDATE matches
+		SET `+side+`_rating_before = r.rating, `+side+`_deviation_before = r.rating_deviation,
+		    `+side+`_volatility_before = r.rating_volatility, `+side+`_rated_at_before = r.rating_updated_at
+		FROM (SELECT rating, rating_deviation, rating_volatility, rating_updated_at FROM `+table+` WHERE `+where+`) r
+		WHERE matches.id = $1
+	`, append([]interface{}{matchID}, args...)...)
+	return err
+}
+
+// ratingUntouchedSince проверяет, что после матча рейтинг игрока в ладдере не
+// менялся: в истории нет более поздних записей, кроме матчей, результат которых
+// уже отменен, и самих этих отмен
+func ratingUntouchedSince(tx *sqlx.Tx, userID int, ladder string, matchID int) (bool, error) {
+	var untouched bool
+	err := tx.Get(&untouched, `
+		SELECT COALESCE((
+			SELECT NOT EXISTS (
+				SELECT 1 FROM rating_history later
+				WHERE later.user_id = h.user_id AND later.rating_type = h.rating_type AND later.id > h.id
+				  AND NOT (later.reason = 'match' AND EXISTS (
+				      SELECT 1 FROM rating_history r
+				      WHERE r.user_id = h.user_id AND r.rating_type = h.rating_type
+				        AND r.match_id = later.match_id AND r.reason = 'revert' AND r.id > later.id
+				  ))
+				  AND NOT (later.reason = 'revert' AND EXISTS (
+				      SELECT 1 FROM rating_history m
+				      WHERE m.user_id = h.user_id AND m.rating_type = h.rating_type
+				        AND m.match_id = later.match_id AND m.reason = 'match' AND m.id > h.id AND m.id < later.id
+				  ))
+			)
+			FROM rating_history h
+			WHERE h.user_id = $1 AND h.rating_type = $2 AND h.match_id = $3 AND h.reason = 'match'
+			ORDER BY h.id DESC
+			LIMIT 1
+		), false)
+	`, userID, ladder, matchID)
+	return untouched, err
+}
+
+// revertRatingResult откатывает результат матча участника: рейтинг уменьшается
+// ровно на примененное изменение, поэтому более поздние матчи, снижение за
+// неактивность и сезонный сброс сохраняются. Отклонение и волатильность
+// возвращаются из снимка перед матчем, только если рейтинг игрока с тех пор не
+// менялся; иначе, как и у команд без истории рейтинга, они остаются текущими.
+// rated - матч менял рейтинг, то есть не был технической победой.
+// Возвращает рейтинг до и после отката.
+func revertRatingResult(tx *sqlx.Tx, target ratingTarget, matchID, id int, won, rated bool, delta int, snapshot ratingSnapshot) (rating.State, rating.State, error) {
+	var before, after rating.State
+
+	table, where, args := target.ratingRow(id, 1)
+	err := tx.QueryRow(`
+		SELECT rating, rating_deviation FROM `+table+` WHERE `+where+` FOR UPDATE
+	`, args...).Scan(&before.Rating, &before.Deviation)
+	if err != nil {
+		return before, after, err
+	}
+
+	restore := false
+	if snapshot.Rating != nil && target.Table == "users" {
+		if restore, err = ratingUntouchedSince(tx, id, target.Ladder, matchID); err != nil {
+			return before, after, err
+		}
+	}
+
+	set := "rating = rating - $1, losses = GREATEST(losses - 1, 0)"
+	if won {
+		set = "rating = rating - $1, wins = GREATEST(wins - 1, 0)"
+	}
+	if rated {
+		set += ", rated_matches = GREATEST(rated_matches - 1, 0)"
+	}
+
+	values := []interface{}{delta}
+	if restore {
+		set += ", rating_deviation = $2, rating_volatility = $3, rating_updated_at = $4"
+		values = append(values, snapshot.Deviation, snapshot.Volatility, snapshot.UpdatedAt)
+	}
+
+	table, where, args = target.ratingRow(id, len(values)+1)
+	err = tx.QueryRow(`
+		UPDATE `+table+`
+		SET `+set+`, updated_at = CURRENT_TIMESTAMP
+		WHERE `+where+`
+		RETURNING rating, rating_deviation
+	`, append(values, args...)...).Scan(&after.Rating, &after.Deviation)
+	if err != nil {
+		return before, after, err
+	}
+
+	if target.Table == "users" && target.Ladder == models.RatingTypeTournament {
+		return before, after, syncMainRating(tx, id)
+	}
+	return before, after, nil
+}
+
+// ladderRating возвращает рейтинг пользователя в ладдере. Если пользователь
+// еще не играл в ладдере, возвращается начальный рейтинг, а для турнирного
+// ладдера - основной рейтинг пользователя.
+func ladderRating(q sqlx.Queryer, user *models.User, ladder string) (models.UserRating, error) {
+	result := models.UserRating{
+		UserID:          user.ID,
+		Ladder:          ladder,
+		Rating:          rating.DefaultRating,
+		RatingDeviation: rating.DefaultDeviation,
+	}
+	if ladder == models.RatingTypeTournament {
+		result.Rating, result.Wins, result.Losses = user.Rating, user.Wins, user.Losses
+		result.RatedMatches = user.RatedMatches
+	}
+
+	err := sqlx.Get(q, &result, `
+		SELECT user_id, ladder, rating, rating_deviation, wins, losses, rated_matches, rating_updated_at
+		FROM user_ratings WHERE user_id = $1 AND ladder = $2
+	`, user.ID, ladder)
+	if err == sql.ErrNoRows {
+		return result, nil
+	}
+
+	return result, err
+}
@@ -36,6 +36,11 @@ type CreateRoomRequest struct {
 	MaxPlayers  int    `json:"max_players" binding:"required,min=2,max=64"`
 	IsPrivate   bool   `json:"is_private"`
 	Password    string `json:"password"`
+
+	// Командный режим: участниками комнаты являются капитаны команд
+	TeamMode bool `json:"team_mode"`
+	TeamSize int  `json:"team_size"`
+	TeamID   int  `json:"team_id"`
 }
 
 // UpdateRoomRequest структура запроса обновления комнаты
@@ -50,6 +55,7 @@ type UpdateRoomRequest struct {
 // JoinRoomRequest структура запроса присоединения к комнате
 type JoinRoomRequest struct {
 	Password string `json:"password"`
+	TeamID   int    `json:"team_id"`
 }
 
 // KickPlayerRequest структура запроса исключения игрока
@@ -178,7 +184,7 @@ func (h *RoomHandlers) GetRooms(c *gin.Context) {
 	// Основной запрос
 	mainQuery := `
 		SELECT r.id, r.name, r.description, r.host_id, r.max_players, r.current_count, 
-		       r.status, r.is_private, r.created_at, r.updated_at,
+		       r.status, r.is_private, r.team_mode, r.team_size, r.created_at, r.updated_at,
 		       u.username as host_username, u.rating as host_rating
 		FROM rooms r
 		JOIN users u ON r.host_id = u.id ` +
@@ -228,7 +234,7 @@ func (h *RoomHandlers) GetRoom(c *gin.Context) {
 	var room models.Room
 	err = h.DB.Get(&room, `
 		SELECT id, name, description, host_id, max_players, current_count, 
-		       status, is_private, created_at, updated_at
+		       status, is_private, team_mode, team_size, created_at, updated_at
 		FROM rooms WHERE id = $1
 	`, roomID)
 
@@ -292,7 +298,7 @@ func (h *RoomHandlers) CreateRoom(c *gin.Context) {
 		SELECT COUNT(*) 
 		FROM room_participants rp
 		JOIN rooms r ON rp.room_id = r.id
-		WHERE rp.user_id = $1 AND r.status IN ('waiting', 'in_progress')
+		WHERE rp.user_id = $1 AND r.status IN ('waiting', 'check_in', 'in_progress')
 	`, userID)
 
 	if err != nil {
@@ -311,6 +317,39 @@ func (h *RoomHandlers) CreateRoom(c *gin.Context) {
 		return
 	}
 
+	// Командный режим: хост может сразу зарегистрировать свою команду
+	var teamSize, teamID *int
+	if req.TeamMode {
+		if req.TeamSize == 0 {
+			req.TeamSize = DefaultTeamSize
+		}
+		if req.TeamSize < 1 || req.TeamSize > models.MaxTeamPlayers {
+			utils.BadRequestResponse(c, "Team size must be between 1 and "+strconv.Itoa(models.MaxTeamPlayers))
+			return
+		}
+		teamSize = &req.TeamSize
+
+		if req.TeamID != 0 {
+			reason, err := checkTeamEligible(h.DB, req.TeamID, userID, req.TeamSize)
+			if err != nil {
+				if err == sql.ErrNoRows {
+					utils.NotFoundResponse(c, "Team not found")
+				} else {
+					utils.InternalErrorResponse(c, "Database error")
+				}
+				return
+			}
+			if reason != "" {
+				utils.BadRequestResponse(c, reason)
+				return
+			}
+			teamID = &req.TeamID
+		}
+	} else if req.TeamID != 0 {
+		utils.BadRequestResponse(c, "Team can only be registered in team mode room")
+		return
+	}
+
 	// Начинаем транзакцию
 	tx, err := h.DB.Beginx()
 	if err != nil {
@@ -322,10 +361,10 @@ func (h *RoomHandlers) CreateRoom(c *gin.Context) {
 	// Создаем комнату
 	var roomID int
 	err = tx.QueryRow(`
-		INSERT INTO rooms (name, description, host_id, max_players, is_private, password)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO rooms (name, description, host_id, max_players, is_private, password, team_mode, team_size)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id
-	`, req.Name, req.Description, userID, req.MaxPlayers, req.IsPrivate, req.Password).Scan(&roomID)
+	`, req.Name, req.Description, userID, req.MaxPlayers, req.IsPrivate, req.Password, req.TeamMode, teamSize).Scan(&roomID)
 
 	if err != nil {
 		utils.InternalErrorResponse(c, "Failed to create room")
@@ -334,9 +373,9 @@ func (h *RoomHandlers) CreateRoom(c *gin.Context) {
 
 	// Добавляем хоста как участника
 	_, err = tx.Exec(`
-		INSERT INTO room_participants (room_id, user_id)
-		VALUES ($1, $2)
-	`, roomID, userID)
+		INSERT INTO room_participants (room_id, user_id, team_id)
+		VALUES ($1, $2, $3)
+	`, roomID, userID, teamID)
 
 	if err != nil {
 		utils.InternalErrorResponse(c, "Failed to add host to room")
@@ -353,7 +392,7 @@ func (h *RoomHandlers) CreateRoom(c *gin.Context) {
 	var room models.Room
 	err = h.DB.Get(&room, `
 		SELECT id, name, description, host_id, max_players, current_count, 
-		       status, is_private, created_at, updated_at
+		       status, is_private, team_mode, team_size, created_at, updated_at
 		FROM rooms WHERE id = $1
 	`, roomID)
 
@@ -503,7 +542,7 @@ func (h *RoomHandlers) UpdateRoom(c *gin.Context) {
 	var room models.Room
 	err = h.DB.Get(&room, `
 		SELECT id, name, description, host_id, max_players, current_count, 
-		       status, is_private, created_at, updated_at
+		       status, is_private, team_mode, team_size, created_at, updated_at
 		FROM rooms WHERE id = $1
 	`, roomID)
 
@@ -603,7 +642,7 @@ func (h *RoomHandlers) JoinRoom(c *gin.Context) {
 		SELECT COUNT(*) 
 		FROM room_participants rp
 		JOIN rooms r ON rp.room_id = r.id
-		WHERE rp.user_id = $1 AND r.status IN ('waiting', 'in_progress')
+		WHERE rp.user_id = $1 AND r.status IN ('waiting', 'check_in', 'in_progress')
 	`, userID)
 
 	if err != nil {
@@ -619,7 +658,7 @@ func (h *RoomHandlers) JoinRoom(c *gin.Context) {
 	// Получаем информацию о комнате
 	var room models.Room
 	err = h.DB.Get(&room, `
-		SELECT id, max_players, current_count, status, is_private, password
+		SELECT id, max_players, current_count, status, is_private, password, team_mode, team_size
 		FROM rooms WHERE id = $1
 	`, roomID)
 
@@ -658,6 +697,44 @@ func (h *RoomHandlers) JoinRoom(c *gin.Context) {
 		return
 	}
 
+	// В командную комнату капитан входит со своей командой
+	var teamID *int
+	if room.TeamMode {
+		if req.TeamID == 0 {
+			utils.BadRequestResponse(c, "Team ID is required to join team mode room")
+			return
+		}
+
+		teamSize := DefaultTeamSize
+		if room.TeamSize != nil {
+			teamSize = *room.TeamSize
+		}
+
+		reason, err := checkTeamEligible(h.DB, req.TeamID, userID, teamSize)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				utils.NotFoundResponse(c, "Team not found")
+			} else {
+				utils.InternalErrorResponse(c, "Database error")
+			}
+			return
+		}
+		if reason != "" {
+			utils.BadRequestResponse(c, reason)
+			return
+		}
+
+		err = h.DB.Get(&exists, `
+			SELECT EXISTS(SELECT 1 FROM room_participants WHERE room_id = $1 AND team_id = $2)
+		`, roomID, req.TeamID)
+		if err != nil || exists {
+			utils.ConflictResponse(c, "Team is already in room")
+			return
+		}
+
+		teamID = &req.TeamID
+	}
+
 	// Начинаем транзакцию
 	tx, err := h.DB.Beginx()
 	if err != nil {
@@ -668,9 +745,9 @@ func (h *RoomHandlers) JoinRoom(c *gin.Context) {
 
 	// Добавляем пользователя в комнату
 	_, err = tx.Exec(`
-		INSERT INTO room_participants (room_id, user_id)
-		VALUES ($1, $2)
-	`, roomID, userID)
+		INSERT INTO room_participants (room_id, user_id, team_id)
+		VALUES ($1, $2, $3)
+	`, roomID, userID, teamID)
 
 	if err != nil {
 		utils.InternalErrorResponse(c, "Failed to join room")
@@ -707,6 +784,7 @@ func (h *RoomHandlers) JoinRoom(c *gin.Context) {
 			"room_id": roomID,
 			"action":  "user_joined",
 			"user":    user,
+			"team_id": teamID,
 		},
 	}
 	msgBytes, _ := json.Marshal(wsMsg)
@@ -1078,12 +1156,13 @@ func (h *RoomHandlers) GetRoomParticipants(c *gin.Context) {
 		models.User
 		JoinedAt time.Time `db:"joined_at" json:"joined_at"`
 		IsHost   bool      `json:"is_host"`
+		TeamID   *int      `db:"team_id" json:"team_id,omitempty"`
 	}
 
 	var participants []ParticipantInfo
 	err = h.DB.Select(&participants, `
 		SELECT u.id, u.username, u.rating, u.wins, u.losses, u.created_at,
-		       rp.joined_at, (u.id = r.host_id) as is_host
+		       rp.joined_at, (u.id = r.host_id) as is_host, rp.team_id
 		FROM users u
 		JOIN room_participants rp ON u.id = rp.user_id
 		JOIN rooms r ON rp.room_id = r.id
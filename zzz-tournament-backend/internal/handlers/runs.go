@@ -0,0 +1,680 @@
+// internal/handlers/runs.go
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"zzz-tournament/internal/models"
+	"zzz-tournament/pkg/tournament"
+	"zzz-tournament/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// Ограничения турниров на очки или время
+const (
+	MaxRunsPerPlayer    = 20           // Заходов одного участника за турнир
+	MaxRunWindowMinutes = 30 * 24 * 60 // Максимальная длительность окна приема заходов (30 дней)
+)
+
+// SubmitRunRequest структура запроса отправки захода
+type SubmitRunRequest struct {
+	Score       *int64 `json:"score,omitempty" binding:"omitempty,min=0"`              // Счет (обязателен при score_type = score)
+	ClearTimeMs *int64 `json:"clear_time_ms,omitempty" binding:"omitempty,min=1"`      // Время прохождения (обязательно при score_type = time)
+	Heroes      []int  `json:"heroes" binding:"required,min=1,max=5"`                  // Команда героев
+	EvidenceURL string `json:"evidence_url,omitempty" binding:"omitempty,url,max=500"` // Ссылка на запись захода; скриншоты загружаются отдельно
+	Notes       string `json:"notes,omitempty" binding:"max=500"`
+}
+
+// RejectRunRequest структура запроса отклонения захода
+type RejectRunRequest struct {
+	Reason string `json:"reason" binding:"required,min=3,max=500"` // Причина отклонения
+}
+
+// GetRunsQuery параметры фильтрации заходов
+type GetRunsQuery struct {
+	Status string `form:"status"` // pending, verified, rejected
+	UserID int    `form:"user_id"`
+}
+
+// LeaderboardEntry лучший подтвержденный заход участника
+type LeaderboardEntry struct {
+	Rank        int           `json:"rank"` // Участники с равным результатом делят место
+	UserID      int           `json:"user_id"`
+	Username    string        `json:"username"`
+	RunID       int           `json:"run_id"`
+	Score       *int64        `json:"score,omitempty"`
+	ClearTimeMs *int64        `json:"clear_time_ms,omitempty"`
+	Heroes      pq.Int64Array `json:"heroes"`
+	SubmittedAt time.Time     `json:"submitted_at"`
+}
+
+// Leaderboard таблица турнира на очки или время
+type Leaderboard struct {
+	TournamentID int                `json:"tournament_id"`
+	Status       string             `json:"status"`
+	ScoreType    string             `json:"score_type"`
+	RunsOpenAt   *time.Time         `json:"runs_open_at"`
+	RunsCloseAt  *time.Time         `json:"runs_close_at"`
+	PendingRuns  int                `json:"pending_runs"` // Заходы, ожидающие проверки хостом
+	Entries      []LeaderboardEntry `json:"entries"`
+}
+
+// scoreAttack турнир на очки или время с состоянием окна приема заходов
+type scoreAttack struct {
+	ID          int             `db:"id"`
+	RoomID      int             `db:"room_id"`
+	HostID      int             `db:"host_id"`
+	Status      string          `db:"status"`
+	Format      string          `db:"format"`
+	ScoreType   string          `db:"score_type"`
+	RunsOpenAt  *time.Time      `db:"runs_open_at"`
+	RunsCloseAt *time.Time      `db:"runs_close_at"`
+	RunsOpen    bool            `db:"runs_open"`   // Окно приема заходов открыто
+	RunsClosed  bool            `db:"runs_closed"` // Окно приема заходов закрыто
+	Ruleset     *models.Ruleset `db:"ruleset"`
+	BracketJSON []byte          `db:"bracket"`
+}
+
+// scoreAttackQuery загружает турнир вместе с хостом комнаты. Время окна
+// сравнивается в базе, чтобы не зависеть от часового пояса сервера.
+const scoreAttackQuery = `
+	SELECT t.id, t.room_id, r.host_id, t.status, t.format, COALESCE(t.score_type, '') as score_type,
+	       t.runs_open_at, t.runs_close_at, t.ruleset, t.bracket,
+	       COALESCE(t.runs_open_at <= CURRENT_TIMESTAMP AND t.runs_close_at > CURRENT_TIMESTAMP, false) as runs_open,
+	       COALESCE(t.runs_close_at <= CURRENT_TIMESTAMP, false) as runs_closed
+	FROM tournaments t
+	JOIN rooms r ON t.room_id = r.id
+	WHERE t.id = $1`
+
+// errNotScoreAttack ошибка обращения к заходам турнира другого формата
+var errNotScoreAttack = errors.New("tournament is not a score attack tournament")
+
+// SubmitRun отправка захода участником турнира на очки или время
+func (h *TournamentHandlers) SubmitRun(c *gin.Context) {
+	tournamentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid tournament ID")
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	var req SubmitRunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	t, err := h.getScoreAttack(h.DB, tournamentID)
+	if err != nil {
+		h.scoreAttackError(c, err)
+		return
+	}
+
+	if t.Status != models.TournamentStatusStarted || !t.RunsOpen {
+		utils.BadRequestResponse(c, "Runs are not accepted at this time")
+		return
+	}
+
+	if !t.isParticipant(userID) {
+		utils.ForbiddenResponse(c, "Only tournament participants can submit runs")
+		return
+	}
+
+	if t.ScoreType == tournament.ScoreTypeScore && req.Score == nil {
+		utils.BadRequestResponse(c, "Score is required for this tournament")
+		return
+	}
+	if t.ScoreType == tournament.ScoreTypeTime && req.ClearTimeMs == nil {
+		utils.BadRequestResponse(c, "Clear time is required for this tournament")
+		return
+	}
+
+	ids := make(pq.Int64Array, len(req.Heroes))
+	for i, id := range req.Heroes {
+		ids[i] = int64(id)
+	}
+
+	heroes, err := h.getHeroes(h.DB, ids)
+	if err != nil {
+		utils.InternalErrorResponse(c, "Database error")
+		return
+	}
+
+	team := make([]models.Hero, len(req.Heroes))
+	for i, id := range req.Heroes {
+		hero, ok := heroes[id]
+		if !ok {
+			utils.BadRequestResponse(c, "Unknown hero "+strconv.Itoa(id))
+			return
+		}
+		team[i] = hero
+	}
+
+	// Без правил турнира состав проверяется только на повторы героев
+	ruleset := t.Ruleset
+	if ruleset == nil {
+		ruleset = &models.Ruleset{}
+	}
+	if errs := ruleset.ValidateTeam("heroes", team); errs.HasErrors() {
+		utils.BadRequestResponse(c, errs.Error(), validationDetails(errs)...)
+		return
+	}
+
+	var runCount int
+	err = h.DB.Get(&runCount, `
+		SELECT COUNT(*) FROM tournament_runs WHERE tournament_id = $1 AND user_id = $2
+	`, tournamentID, userID)
+	if err != nil {
+		utils.InternalErrorResponse(c, "Database error")
+		return
+	}
+
+	if runCount >= MaxRunsPerPlayer {
+		utils.BadRequestResponse(c, "Run limit reached: at most "+strconv.Itoa(MaxRunsPerPlayer)+" runs per player")
+		return
+	}
+
+	var notes, evidenceURL *string
+	if req.Notes != "" {
+		notes = &req.Notes
+	}
+	if req.EvidenceURL != "" {
+		evidenceURL = &req.EvidenceURL
+	}
+
+	var run models.TournamentRun
+	err = h.DB.Get(&run, `
+		INSERT INTO tournament_runs (tournament_id, user_id, score, clear_time_ms, heroes, evidence_url, notes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, tournament_id, user_id, score, clear_time_ms, heroes, evidence_url, notes, status, created_at, updated_at
+	`, tournamentID, userID, req.Score, req.ClearTimeMs, ids, evidenceURL, notes)
+
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to submit run")
+		return
+	}
+
+	h.Logger.Info("Run submitted",
+		"tournament_id", tournamentID,
+		"run_id", run.ID,
+		"user_id", userID,
+	)
+
+	h.broadcastRuns(t, "run_submitted", run, false)
+
+	utils.CreatedResponse(c, run, "Run submitted for verification")
+}
+
+// GetRuns получение заходов турнира
+func (h *TournamentHandlers) GetRuns(c *gin.Context) {
+	tournamentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid tournament ID")
+		return
+	}
+
+	var query GetRunsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.BadRequestResponse(c, "Invalid query parameters")
+		return
+	}
+
+	if query.Status != "" && !models.IsValidRunStatus(query.Status) {
+		utils.BadRequestResponse(c, "Invalid run status")
+		return
+	}
+
+	if _, err := h.getScoreAttack(h.DB, tournamentID); err != nil {
+		h.scoreAttackError(c, err)
+		return
+	}
+
+	where := "tr.tournament_id = $1"
+	args := []interface{}{tournamentID}
+
+	if query.Status != "" {
+		args = append(args, query.Status)
+		where += " AND tr.status = $" + strconv.Itoa(len(args))
+	}
+
+	if query.UserID > 0 {
+		args = append(args, query.UserID)
+		where += " AND tr.user_id = $" + strconv.Itoa(len(args))
+	}
+
+	runs := []models.TournamentRun{}
+	err = h.DB.Select(&runs, `
+		SELECT tr.id, tr.tournament_id, tr.user_id, u.username, tr.score, tr.clear_time_ms, tr.heroes,
+		       tr.evidence_url, tr.notes, tr.status, tr.reviewed_by, tr.review_reason, tr.reviewed_at,
+		       tr.created_at, tr.updated_at
+		FROM tournament_runs tr
+		JOIN users u ON tr.user_id = u.id
+		WHERE `+where+`
+		ORDER BY tr.created_at DESC, tr.id DESC
+	`, args...)
+
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to get runs")
+		return
+	}
+
+	utils.SuccessResponse(c, runs)
+}
+
+// VerifyRun подтверждение захода (хост комнаты или администратор)
+func (h *TournamentHandlers) VerifyRun(c *gin.Context) {
+	h.reviewRun(c, models.RunStatusVerified, "")
+}
+
+// RejectRun отклонение захода с указанием причины (хост комнаты или администратор)
+func (h *TournamentHandlers) RejectRun(c *gin.Context) {
+	var req RejectRunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	h.reviewRun(c, models.RunStatusRejected, req.Reason)
+}
+
+// GetLeaderboard таблица лучших подтвержденных заходов участников
+func (h *TournamentHandlers) GetLeaderboard(c *gin.Context) {
+	tournamentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid tournament ID")
+		return
+	}
+
+	t, err := h.getScoreAttack(h.DB, tournamentID)
+	if err != nil {
+		h.scoreAttackError(c, err)
+		return
+	}
+
+	leaderboard, _, err := h.buildLeaderboard(h.DB, t)
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to build leaderboard")
+		return
+	}
+
+	utils.SuccessResponse(c, leaderboard)
+}
+
+// FinalizeScoreAttacks завершает турниры на очки или время, окно приема
+// заходов которых закрылось и все заходы проверены. Вызывается фоновой задачей.
+func (h *TournamentHandlers) FinalizeScoreAttacks() error {
+	var ids []int
+	err := h.DB.Select(&ids, `
+		SELECT t.id
+		FROM tournaments t
+		WHERE t.format = 'score_attack' AND t.status = 'started' AND t.runs_close_at <= CURRENT_TIMESTAMP
+		  AND NOT EXISTS (SELECT 1 FROM tournament_runs WHERE tournament_id = t.id AND status = 'pending')
+		ORDER BY t.runs_close_at
+	`)
+	if err != nil {
+		return err
+	}
+
+	for _, tournamentID := range ids {
+		if err := h.finishScoreAttack(tournamentID); err != nil {
+			h.Logger.Error("Failed to finish score attack tournament", "tournament_id", tournamentID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// reviewRun меняет статус проверки захода. Решение можно пересмотреть, пока
+// турнир не завершен.
+func (h *TournamentHandlers) reviewRun(c *gin.Context, status, reason string) {
+	tournamentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid tournament ID")
+		return
+	}
+
+	runID, err := strconv.Atoi(c.Param("run_id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid run ID")
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	t, err := h.getScoreAttack(h.DB, tournamentID)
+	if err != nil {
+		h.scoreAttackError(c, err)
+		return
+	}
+
+	if t.HostID != userID && !h.isAdmin(userID) {
+		utils.ForbiddenResponse(c, "Only room host or administrator can review runs")
+		return
+	}
+
+	if t.Status != models.TournamentStatusStarted {
+		utils.BadRequestResponse(c, "Tournament is not in progress")
+		return
+	}
+
+	var reviewReason *string
+	if reason != "" {
+		reviewReason = &reason
+	}
+
+	var run models.TournamentRun
+	err = h.DB.Get(&run, `
+		UPDATE tournament_runs
+		SET status = $1, review_reason = $2, reviewed_by = $3, reviewed_at = CURRENT_TIMESTAMP,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4 AND tournament_id = $5
+		RETURNING id, tournament_id, user_id, score, clear_time_ms, heroes, evidence_url, notes, status,
+		          reviewed_by, review_reason, reviewed_at, created_at, updated_at
+	`, status, reviewReason, userID, runID, tournamentID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "Run not found")
+		} else {
+			utils.InternalErrorResponse(c, "Failed to review run")
+		}
+		return
+	}
+
+	h.Logger.Info("Run reviewed",
+		"tournament_id", tournamentID,
+		"run_id", runID,
+		"status", status,
+		"reviewed_by", userID,
+	)
+
+	leaderboard := h.broadcastRuns(t, "run_"+status, run, true)
+
+	utils.SuccessResponse(c, gin.H{
+		"run":         run,
+		"leaderboard": leaderboard,
+	})
+}
+
+// finishScoreAttack подводит итоги турнира по таблице заходов
+func (h *TournamentHandlers) finishScoreAttack(tournamentID int) error {
+	tx, err := h.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Блокируем турнир, чтобы проверка захода не разошлась с итогами
+	if _, err = tx.Exec(`SELECT id FROM tournaments WHERE id = $1 FOR UPDATE`, tournamentID); err != nil {
+		return err
+	}
+
+	t, err := h.getScoreAttack(tx, tournamentID)
+	if err != nil {
+		return err
+	}
+
+	leaderboard, ranked, err := h.buildLeaderboard(tx, t)
+	if err != nil {
+		return err
+	}
+
+	// Турнир могли завершить или отменить, пока он ожидал блокировки
+	if t.Status != models.TournamentStatusStarted || !t.RunsClosed || leaderboard.PendingRuns > 0 {
+		return nil
+	}
+
+	var bracket tournament.Bracket
+	if err := json.Unmarshal(t.BracketJSON, &bracket); err != nil {
+		return fmt.Errorf("parse bracket: %w", err)
+	}
+
+	var winnerID *int
+	if len(ranked) > 0 {
+		winnerID = &ranked[0].PlayerID
+	}
+
+	_, err = tx.Exec(`
+		UPDATE tournaments
+		SET status = 'finished', winner_id = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`, winnerID, tournamentID)
+	if err != nil {
+		return fmt.Errorf("finish tournament: %w", err)
+	}
+
+	if _, err = tx.Exec(`DELETE FROM tournament_placements WHERE tournament_id = $1`, tournamentID); err != nil {
+		return fmt.Errorf("delete placements: %w", err)
+	}
+
+	for _, placement := range tournament.ScoreAttackPlacements(bracket.Players, ranked) {
+		_, err = tx.Exec(`
+			INSERT INTO tournament_placements (tournament_id, user_id, place, place_to)
+			VALUES ($1, $2, $3, $4)
+		`, tournamentID, placement.PlayerID, placement.Place, placement.PlaceTo)
+
+		if err != nil {
+			return fmt.Errorf("save placements: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(`
+		UPDATE rooms SET status = 'finished', updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, t.RoomID)
+	if err != nil {
+		return fmt.Errorf("update room status: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	h.Logger.Info("Score attack tournament finished",
+		"tournament_id", tournamentID,
+		"ranked_players", len(ranked),
+	)
+
+	leaderboard.Status = models.TournamentStatusFinished
+	wsMsg := models.WSMessage{
+		Type: "tournament_finished",
+		Data: gin.H{
+			"room_id":       t.RoomID,
+			"tournament_id": tournamentID,
+			"winner_id":     winnerID,
+			"leaderboard":   leaderboard,
+		},
+	}
+	msgBytes, _ := json.Marshal(wsMsg)
+	h.Hub.BroadcastToRoom(t.RoomID, msgBytes)
+
+	return nil
+}
+
+// getScoreAttack загружает турнир на очки или время
+func (h *TournamentHandlers) getScoreAttack(q sqlx.Queryer, tournamentID int) (*scoreAttack, error) {
+	var t scoreAttack
+	if err := sqlx.Get(q, &t, scoreAttackQuery, tournamentID); err != nil {
+		return nil, err
+	}
+
+	if t.Format != models.TournamentFormatScoreAttack {
+		return nil, errNotScoreAttack
+	}
+
+	return &t, nil
+}
+
+// scoreAttackError отвечает клиенту по ошибке загрузки турнира
+func (h *TournamentHandlers) scoreAttackError(c *gin.Context, err error) {
+	switch {
+	case err == sql.ErrNoRows:
+		utils.NotFoundResponse(c, "Tournament not found")
+	case errors.Is(err, errNotScoreAttack):
+		utils.BadRequestResponse(c, err.Error())
+	default:
+		utils.InternalErrorResponse(c, "Database error")
+	}
+}
+
+// isParticipant проверяет, входил ли пользователь в состав турнира на момент старта
+func (t *scoreAttack) isParticipant(userID int) bool {
+	var bracket tournament.Bracket
+	if err := json.Unmarshal(t.BracketJSON, &bracket); err != nil {
+		return false
+	}
+
+	for _, player := range bracket.Players {
+		if player.ID == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// getLeaderboard строит таблицу турнира на очки или время по ID
+func (h *TournamentHandlers) getLeaderboard(tournamentID int) (*Leaderboard, error) {
+	t, err := h.getScoreAttack(h.DB, tournamentID)
+	if err != nil {
+		return nil, err
+	}
+
+	leaderboard, _, err := h.buildLeaderboard(h.DB, t)
+	return leaderboard, err
+}
+
+// buildLeaderboard строит таблицу по подтвержденным заходам
+func (h *TournamentHandlers) buildLeaderboard(q sqlx.Queryer, t *scoreAttack) (*Leaderboard, []tournament.RankedRun, error) {
+	leaderboard := &Leaderboard{
+		TournamentID: t.ID,
+		Status:       t.Status,
+		ScoreType:    t.ScoreType,
+		RunsOpenAt:   t.RunsOpenAt,
+		RunsCloseAt:  t.RunsCloseAt,
+		Entries:      []LeaderboardEntry{},
+	}
+
+	err := sqlx.Get(q, &leaderboard.PendingRuns, `
+		SELECT COUNT(*) FROM tournament_runs WHERE tournament_id = $1 AND status = 'pending'
+	`, t.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var runs []models.TournamentRun
+	err = sqlx.Select(q, &runs, `
+		SELECT tr.id, tr.user_id, u.username, tr.score, tr.clear_time_ms, tr.heroes, tr.created_at
+		FROM tournament_runs tr
+		JOIN users u ON tr.user_id = u.id
+		WHERE tr.tournament_id = $1 AND tr.status = 'verified'
+	`, t.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byID := make(map[int]models.TournamentRun, len(runs))
+	candidates := make([]tournament.Run, 0, len(runs))
+	for _, run := range runs {
+		byID[run.ID] = run
+
+		candidate := tournament.Run{ID: run.ID, PlayerID: run.UserID, SubmittedAt: run.CreatedAt}
+		if run.Score != nil {
+			candidate.Score = *run.Score
+		}
+		if run.ClearTimeMs != nil {
+			candidate.ClearTimeMs = *run.ClearTimeMs
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	ranked := tournament.RankRuns(t.ScoreType, candidates)
+	for _, r := range ranked {
+		run := byID[r.ID]
+		leaderboard.Entries = append(leaderboard.Entries, LeaderboardEntry{
+			Rank:        r.Rank,
+			UserID:      run.UserID,
+			Username:    run.Username,
+			RunID:       run.ID,
+			Score:       run.Score,
+			ClearTimeMs: run.ClearTimeMs,
+			Heroes:      run.Heroes,
+			SubmittedAt: run.CreatedAt,
+		})
+	}
+
+	return leaderboard, ranked, nil
+}
+
+// broadcastRuns сообщает комнате о новом или проверенном заходе. Если заход
+// проверен, вместе с ним отправляется обновленная таблица, а автор захода
+// получает личное уведомление.
+func (h *TournamentHandlers) broadcastRuns(t *scoreAttack, action string, run models.TournamentRun, reviewed bool) *Leaderboard {
+	data := gin.H{
+		"room_id":       t.RoomID,
+		"tournament_id": t.ID,
+		"stage":         "runs",
+		"action":        action,
+		"run":           run,
+	}
+
+	var leaderboard *Leaderboard
+	if reviewed {
+		var err error
+		leaderboard, _, err = h.buildLeaderboard(h.DB, t)
+		if err != nil {
+			h.Logger.Error("Failed to build leaderboard", "tournament_id", t.ID, "error", err)
+		} else {
+			data["leaderboard"] = leaderboard
+		}
+	}
+
+	wsMsg := models.WSMessage{
+		Type: models.WSTypeTournamentUpdate,
+		Data: data,
+	}
+	msgBytes, _ := json.Marshal(wsMsg)
+	h.Hub.BroadcastToRoom(t.RoomID, msgBytes)
+
+	if reviewed {
+		h.Hub.SendToUser(run.UserID, msgBytes)
+	}
+
+	return leaderboard
+}
+
+// validateRunWindow проверяет тип результата и окно приема заходов турнира на
+// очки или время. Если начало не указано, прием заходов открывается сразу.
+func validateRunWindow(req *StartTournamentRequest) error {
+	if req.ScoreType == "" {
+		req.ScoreType = tournament.ScoreTypeScore
+	}
+	if !tournament.IsValidScoreType(req.ScoreType) {
+		return errors.New("score type must be one of: score, time")
+	}
+
+	now := time.Now()
+	if req.RunsOpenAt == nil {
+		req.RunsOpenAt = &now
+	}
+
+	if req.RunsCloseAt == nil {
+		return errors.New("runs_close_at is required for score attack tournaments")
+	}
+	if !req.RunsCloseAt.After(now) || !req.RunsCloseAt.After(*req.RunsOpenAt) {
+		return errors.New("runs_close_at must be in the future and after runs_open_at")
+	}
+	if req.RunsCloseAt.Sub(*req.RunsOpenAt) > MaxRunWindowMinutes*time.Minute {
+		return errors.New("run window must not exceed " + strconv.Itoa(MaxRunWindowMinutes/(24*60)) + " days")
+	}
+
+	return nil
+}
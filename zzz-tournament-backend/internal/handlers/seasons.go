@@ -0,0 +1,568 @@
+// internal/handlers/seasons.go
+package handlers
+
+import (
+	"database/sql"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"zzz-tournament/internal/models"
+	"zzz-tournament/internal/websocket"
+	"zzz-tournament/pkg/config"
+	"zzz-tournament/pkg/rating"
+	"zzz-tournament/pkg/utils"
+	"zzz-tournament/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// SeasonHandlers обработчики соревновательных сезонов
+type SeasonHandlers struct {
+	BaseHandlers
+	Config *config.RatingConfig
+}
+
+// NewSeasonHandlers создает новый экземпляр SeasonHandlers
+func NewSeasonHandlers(db *sqlx.DB, hub *websocket.Hub, logger *slog.Logger, ratingConfig *config.RatingConfig) *SeasonHandlers {
+	return &SeasonHandlers{
+		BaseHandlers: newBaseHandlers(db, hub, logger),
+		Config:       ratingConfig,
+	}
+}
+
+// CreateSeasonRequest структура запроса создания сезона
+type CreateSeasonRequest struct {
+	Name     string    `json:"name" binding:"required,min=1,max=100"`
+	StartsAt time.Time `json:"starts_at" binding:"required"`
+	EndsAt   time.Time `json:"ends_at" binding:"required"`
+}
+
+// UpdateSeasonRequest структура запроса изменения сезона.
+// У идущего сезона можно изменить только название и время окончания.
+type UpdateSeasonRequest struct {
+	Name     *string    `json:"name,omitempty" binding:"omitempty,min=1,max=100"`
+	StartsAt *time.Time `json:"starts_at,omitempty"`
+	EndsAt   *time.Time `json:"ends_at,omitempty"`
+}
+
+// SeasonBadge значок наивысшего тира, достигнутого за сезон
+type SeasonBadge struct {
+	Tier  string `json:"tier"`
+	Color string `json:"color"`
+}
+
+// UserSeasonEntry итоги сезона для пользователя
+type UserSeasonEntry struct {
+	models.SeasonStanding
+	SeasonName string      `json:"season_name" db:"season_name"`
+	StartsAt   time.Time   `json:"starts_at" db:"starts_at"`
+	EndsAt     time.Time   `json:"ends_at" db:"ends_at"`
+	Badge      SeasonBadge `json:"badge" db:"-"`
+}
+
+const seasonColumns = `id, name, starts_at, ends_at, status, created_by, finished_at, created_at, updated_at`
+
+// GetSeasons получение списка сезонов
+func (h *SeasonHandlers) GetSeasons(c *gin.Context) {
+	seasons := []models.Season{}
+	err := h.DB.Select(&seasons, `SELECT `+seasonColumns+` FROM seasons ORDER BY starts_at DESC`)
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to fetch seasons")
+		return
+	}
+
+	utils.SuccessResponse(c, seasons)
+}
+
+// GetCurrentSeason получение текущего сезона
+func (h *SeasonHandlers) GetCurrentSeason(c *gin.Context) {
+	var season models.Season
+	err := h.DB.Get(&season, `SELECT `+seasonColumns+` FROM seasons WHERE status = 'active'`)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "No active season")
+		} else {
+			utils.InternalErrorResponse(c, "Database error")
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, season)
+}
+
+// CreateSeason создание сезона (только для админов)
+func (h *SeasonHandlers) CreateSeason(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	var req CreateSeasonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	if !req.EndsAt.After(req.StartsAt) {
+		utils.BadRequestResponse(c, "ends_at must be after starts_at")
+		return
+	}
+
+	if !req.EndsAt.After(time.Now()) {
+		utils.BadRequestResponse(c, "ends_at must be in the future")
+		return
+	}
+
+	overlaps, err := h.seasonOverlaps(0, req.StartsAt, req.EndsAt)
+	if err != nil {
+		utils.InternalErrorResponse(c, "Database error")
+		return
+	}
+	if overlaps {
+		utils.ConflictResponse(c, "Season overlaps with another season")
+		return
+	}
+
+	var season models.Season
+	err = h.DB.Get(&season, `
+		INSERT INTO seasons (name, starts_at, ends_at, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+seasonColumns, req.Name, req.StartsAt, req.EndsAt, userID)
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to create season")
+		return
+	}
+
+	utils.CreatedResponse(c, season, "Season created successfully")
+}
+
+// UpdateSeason изменение сезона (только для админов)
+func (h *SeasonHandlers) UpdateSeason(c *gin.Context) {
+	seasonID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid season ID")
+		return
+	}
+
+	var req UpdateSeasonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	season, err := h.getSeason(seasonID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "Season not found")
+		} else {
+			utils.InternalErrorResponse(c, "Database error")
+		}
+		return
+	}
+
+	if season.IsFinished() {
+		utils.BadRequestResponse(c, "Finished season cannot be changed")
+		return
+	}
+
+	if req.StartsAt != nil && season.Status == models.SeasonStatusActive {
+		utils.BadRequestResponse(c, "Start of an active season cannot be changed")
+		return
+	}
+
+	if req.Name != nil {
+		season.Name = *req.Name
+	}
+	if req.StartsAt != nil {
+		season.StartsAt = *req.StartsAt
+	}
+	if req.EndsAt != nil {
+		season.EndsAt = *req.EndsAt
+	}
+
+	if !season.EndsAt.After(season.StartsAt) {
+		utils.BadRequestResponse(c, "ends_at must be after starts_at")
+		return
+	}
+
+	if req.EndsAt != nil && !season.EndsAt.After(time.Now()) {
+		utils.BadRequestResponse(c, "ends_at must be in the future")
+		return
+	}
+
+	overlaps, err := h.seasonOverlaps(seasonID, season.StartsAt, season.EndsAt)
+	if err != nil {
+		utils.InternalErrorResponse(c, "Database error")
+		return
+	}
+	if overlaps {
+		utils.ConflictResponse(c, "Season overlaps with another season")
+		return
+	}
+
+	err = h.DB.Get(&season, `
+		UPDATE seasons SET name = $1, starts_at = $2, ends_at = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4 AND status <> 'finished'
+		RETURNING `+seasonColumns, season.Name, season.StartsAt, season.EndsAt, seasonID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.BadRequestResponse(c, "Finished season cannot be changed")
+		} else {
+			utils.InternalErrorResponse(c, "Failed to update season")
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, season, "Season updated successfully")
+}
+
+// DeleteSeason удаление запланированного сезона (только для админов)
+func (h *SeasonHandlers) DeleteSeason(c *gin.Context) {
+	seasonID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid season ID")
+		return
+	}
+
+	result, err := h.DB.Exec(`DELETE FROM seasons WHERE id = $1 AND status = 'scheduled'`, seasonID)
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to delete season")
+		return
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		utils.NotFoundResponse(c, "Scheduled season not found")
+		return
+	}
+
+	utils.SuccessResponse(c, nil, "Season deleted successfully")
+}
+
+// GetSeasonLeaderboard итоговая таблица завершенного сезона
+func (h *SeasonHandlers) GetSeasonLeaderboard(c *gin.Context) {
+	seasonID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid season ID")
+		return
+	}
+
+	page := getPageFromQuery(c, 1)
+	perPage := getPerPageFromQuery(c, 50)
+
+	if err := validator.ValidatePage(page); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	if err := validator.ValidatePerPage(perPage); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	ladder := c.DefaultQuery("ladder", models.RatingTypeTournament)
+	if !models.IsValidLadder(ladder) {
+		utils.BadRequestResponse(c, "Invalid ladder, expected tournament, ranked or casual")
+		return
+	}
+
+	season, err := h.getSeason(seasonID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "Season not found")
+		} else {
+			utils.InternalErrorResponse(c, "Database error")
+		}
+		return
+	}
+
+	if !season.IsFinished() {
+		utils.BadRequestResponse(c, "Season is not finished yet")
+		return
+	}
+
+	var total int
+	err = h.DB.Get(&total, `
+		SELECT COUNT(*) FROM season_standings WHERE season_id = $1 AND ladder = $2
+	`, seasonID, ladder)
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to count standings")
+		return
+	}
+
+	standings := []models.SeasonStanding{}
+	err = h.DB.Select(&standings, `
+		SELECT ss.season_id, ss.user_id, u.username, ss.ladder, ss.rank, ss.rating, ss.tier,
+		       ss.peak_rating, ss.peak_tier, ss.wins, ss.losses
+		FROM season_standings ss
+		JOIN users u ON ss.user_id = u.id
+		WHERE ss.season_id = $1 AND ss.ladder = $2
+		ORDER BY ss.rank
+		LIMIT $3 OFFSET $4
+	`, seasonID, ladder, perPage, (page-1)*perPage)
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to fetch season leaderboard")
+		return
+	}
+
+	pagination := utils.NewPaginationMeta(page, perPage, total)
+
+	utils.PaginatedSuccessResponse(c, standings, pagination, "Season leaderboard fetched successfully")
+}
+
+// GetUserSeasons история сезонов пользователя со значками наивысшего тира
+func (h *SeasonHandlers) GetUserSeasons(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid user ID")
+		return
+	}
+
+	var username string
+	err = h.DB.Get(&username, `SELECT username FROM users WHERE id = $1`, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "User not found")
+		} else {
+			utils.InternalErrorResponse(c, "Database error")
+		}
+		return
+	}
+
+	seasons := []UserSeasonEntry{}
+	err = h.DB.Select(&seasons, `
+		SELECT ss.season_id, ss.user_id, ss.ladder, ss.rank, ss.rating, ss.tier,
+		       ss.peak_rating, ss.peak_tier, ss.wins, ss.losses,
+		       s.name as season_name, s.starts_at, s.ends_at
+		FROM season_standings ss
+		JOIN seasons s ON ss.season_id = s.id
+		WHERE ss.user_id = $1
+		ORDER BY s.starts_at DESC, ss.ladder
+	`, userID)
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to fetch user seasons")
+		return
+	}
+
+	for i := range seasons {
+		seasons[i].Badge = SeasonBadge{
+			Tier:  seasons[i].PeakTier,
+			Color: rating.GetRatingColor(seasons[i].PeakRating),
+		}
+	}
+
+	utils.SuccessResponse(c, gin.H{
+		"user_id":  userID,
+		"username": username,
+		"seasons":  seasons,
+	})
+}
+
+// ProcessSeasons завершает истекший сезон и запускает следующий.
+// Вызывается периодически из фонового обработчика.
+func (h *SeasonHandlers) ProcessSeasons() error {
+	var expired []int
+	err := h.DB.Select(&expired, `
+		SELECT id FROM seasons WHERE status = 'active' AND ends_at <= CURRENT_TIMESTAMP
+	`)
+	if err != nil {
+		return err
+	}
+
+	for _, seasonID := range expired {
+		if err := h.finishSeason(seasonID); err != nil {
+			h.Logger.Error("Failed to finish season", "season_id", seasonID, "error", err)
+			return err
+		}
+	}
+
+	// Запускаем ближайший запланированный сезон, если ни один сезон не идет
+	var started []int
+	err = h.DB.Select(&started, `
+		UPDATE seasons SET status = 'active', updated_at = CURRENT_TIMESTAMP
+		WHERE id = (
+			SELECT id FROM seasons
+			WHERE status = 'scheduled' AND starts_at <= CURRENT_TIMESTAMP
+			ORDER BY starts_at
+			LIMIT 1
+		) AND NOT EXISTS (SELECT 1 FROM seasons WHERE status = 'active')
+		RETURNING id
+	`)
+	if err != nil {
+		return err
+	}
+
+	for _, seasonID := range started {
+		h.Logger.Info("Season started", "season_id", seasonID)
+	}
+
+	return nil
+}
+
+// finishSeason архивирует итоговые места сезона и частично сбрасывает рейтинги
+func (h *SeasonHandlers) finishSeason(seasonID int) error {
+	tx, err := h.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var season models.Season
+	err = tx.Get(&season, `
+		SELECT `+seasonColumns+` FROM seasons
+		WHERE id = $1 AND status = 'active'
+		FOR UPDATE
+	`, seasonID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+
+	archived, err := h.archiveStandings(tx, &season)
+	if err != nil {
+		return err
+	}
+
+	reset, err := h.resetRatings(tx)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE seasons
+		SET status = 'finished', finished_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, seasonID)
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+
+	h.Logger.Info("Season finished", "season_id", seasonID, "standings", archived, "ratings_reset", reset)
+	return nil
+}
+
+// archiveStandings сохраняет итоговые места игроков, сыгравших в сезоне хотя бы
+// один рейтинговый матч. Места и тиры считаются отдельно для каждого ладдера.
+func (h *SeasonHandlers) archiveStandings(tx *sqlx.Tx, season *models.Season) (int, error) {
+	var rows []models.SeasonStanding
+	err := tx.Select(&rows, `
+		SELECT ur.user_id, ur.ladder, ur.rating,
+		       GREATEST(COALESCE(MAX(rh.rating_after) FILTER (WHERE rh.reason = 'match'), ur.rating), ur.rating) as peak_rating,
+		       GREATEST(COUNT(*) FILTER (WHERE rh.reason = 'match' AND rh.won)
+		              - COUNT(*) FILTER (WHERE rh.reason = 'revert' AND rh.won), 0) as wins,
+		       GREATEST(COUNT(*) FILTER (WHERE rh.reason = 'match' AND NOT rh.won)
+		              - COUNT(*) FILTER (WHERE rh.reason = 'revert' AND NOT rh.won), 0) as losses
+		FROM user_ratings ur
+		JOIN rating_history rh ON rh.user_id = ur.user_id AND rh.rating_type = ur.ladder
+		WHERE rh.reason IN ('match', 'revert') AND rh.created_at >= $1
+		GROUP BY ur.user_id, ur.ladder, ur.rating
+		ORDER BY ur.ladder, ur.rating DESC, wins DESC, ur.user_id
+	`, season.StartsAt)
+	if err != nil {
+		return 0, err
+	}
+
+	archived := 0
+	rank := 0
+	ladder := ""
+	for _, row := range rows {
+		// Матчи сезона могли быть полностью отменены
+		if row.Wins+row.Losses == 0 {
+			continue
+		}
+
+		if row.Ladder != ladder {
+			ladder = row.Ladder
+			rank = 0
+		}
+		rank++
+
+		_, err = tx.Exec(`
+			INSERT INTO season_standings (season_id, user_id, ladder, rank, rating, tier,
+			                              peak_rating, peak_tier, wins, losses)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`, season.ID, row.UserID, row.Ladder, rank, row.Rating, rating.GetRatingTier(row.Rating),
+			row.PeakRating, rating.GetRatingTier(row.PeakRating), row.Wins, row.Losses)
+		if err != nil {
+			return archived, err
+		}
+		archived++
+	}
+
+	return archived, nil
+}
+
+// resetRatings сдвигает рейтинги всех ладдеров к среднему и увеличивает
+// отклонение Glicko-2 до минимального значения. Каждое изменение записывается
+// в историю рейтинга, турнирный ладдер дублируется в users.
+func (h *SeasonHandlers) resetRatings(tx *sqlx.Tx) (int, error) {
+	// Турнирный рейтинг игроков без строки в user_ratings тоже сбрасывается
+	_, err := tx.Exec(`
+		INSERT INTO user_ratings (user_id, ladder, rating, rating_deviation, rating_volatility,
+		                          wins, losses, rated_matches, rating_updated_at)
+		SELECT id, 'tournament', rating, rating_deviation, rating_volatility, wins, losses, rated_matches, rating_updated_at
+		FROM users
+		ON CONFLICT (user_id, ladder) DO NOTHING
+	`)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tx.Exec(`
+		WITH reset AS (
+			UPDATE user_ratings ur
+			SET rating = $1::int + ROUND((old.rating - $1::int) * $2::float8)::int,
+			    rating_deviation = GREATEST(old.rating_deviation, $3::float8),
+			    updated_at = CURRENT_TIMESTAMP
+			FROM user_ratings old
+			WHERE old.user_id = ur.user_id AND old.ladder = ur.ladder
+			RETURNING ur.user_id, ur.ladder, old.rating as rating_before, ur.rating as rating_after, ur.rating_deviation
+		)
+		INSERT INTO rating_history (user_id, rating_type, reason, rating_before, rating_after, delta, rating_deviation)
+		SELECT user_id, ladder, $4, rating_before, rating_after, rating_after - rating_before, rating_deviation
+		FROM reset
+		WHERE rating_after <> rating_before
+	`, h.Config.SeasonResetMean, h.Config.SeasonResetFactor, h.Config.SeasonResetDeviation, models.RatingReasonSeasonReset)
+	if err != nil {
+		return 0, err
+	}
+
+	// Основной рейтинг копируется целиком, как в syncMainRating
+	_, err = tx.Exec(`
+		UPDATE users u
+		SET rating = ur.rating, rating_deviation = ur.rating_deviation, rating_volatility = ur.rating_volatility,
+		    wins = ur.wins, losses = ur.losses, rated_matches = ur.rated_matches,
+		    rating_updated_at = ur.rating_updated_at, updated_at = CURRENT_TIMESTAMP
+		FROM user_ratings ur
+		WHERE ur.user_id = u.id AND ur.ladder = 'tournament'
+	`)
+	if err != nil {
+		return 0, err
+	}
+
+	reset, _ := result.RowsAffected()
+	return int(reset), nil
+}
+
+// getSeason получает сезон по ID
+func (h *SeasonHandlers) getSeason(seasonID int) (models.Season, error) {
+	var season models.Season
+	err := h.DB.Get(&season, `SELECT `+seasonColumns+` FROM seasons WHERE id = $1`, seasonID)
+	return season, err
+}
+
+// seasonOverlaps проверяет, пересекается ли период с другими сезонами
+func (h *SeasonHandlers) seasonOverlaps(seasonID int, startsAt, endsAt time.Time) (bool, error) {
+	var overlaps bool
+	err := h.DB.Get(&overlaps, `
+		SELECT EXISTS(
+			SELECT 1 FROM seasons
+			WHERE id <> $1 AND starts_at < $3 AND ends_at > $2
+		)
+	`, seasonID, startsAt, endsAt)
+	return overlaps, err
+}
@@ -0,0 +1,163 @@
+// internal/handlers/team_tournaments.go
+package handlers
+
+import (
+	"strconv"
+
+	"zzz-tournament/internal/models"
+	"zzz-tournament/pkg/tournament"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// DefaultTeamSize минимальное количество основных игроков в команде по умолчанию (отряд 3v3)
+const DefaultTeamSize = 3
+
+// checkTeamEligible проверяет, что пользователь - капитан команды и в ее основном
+// составе достаточно игроков. Возвращает причину отказа для клиента или
+// пустую строку, если команда может участвовать.
+func checkTeamEligible(q sqlx.Queryer, teamID, userID, teamSize int) (string, error) {
+	var team struct {
+		CaptainID int `db:"captain_id"`
+		Players   int `db:"players"`
+	}
+	err := sqlx.Get(q, &team, `
+		SELECT t.captain_id,
+		       (SELECT COUNT(*) FROM team_members tm WHERE tm.team_id = t.id AND tm.role = 'player') as players
+		FROM teams t WHERE t.id = $1
+	`, teamID)
+	if err != nil {
+		return "", err
+	}
+
+	if team.CaptainID != userID {
+		return "Only team captain can register the team", nil
+	}
+
+	if team.Players < teamSize {
+		return "Team needs at least " + strconv.Itoa(teamSize) + " main players", nil
+	}
+
+	return "", nil
+}
+
+// loadRoomTeams загружает команды, зарегистрированные капитанами в комнате.
+// Если проводился check-in, в турнир попадают только подтвердившие участие.
+func (h *TournamentHandlers) loadRoomTeams(q sqlx.Queryer, roomID int) ([]tournament.Team, error) {
+	var rows []struct {
+		ID        int    `db:"id"`
+		Name      string `db:"name"`
+		Rating    int    `db:"rating"`
+		CaptainID int    `db:"captain_id"`
+	}
+	err := sqlx.Select(q, &rows, `
+		SELECT t.id, t.name, t.rating, t.captain_id
+		FROM teams t
+		JOIN room_participants rp ON rp.team_id = t.id AND rp.user_id = t.captain_id
+		JOIN rooms r ON rp.room_id = r.id
+		WHERE rp.room_id = $1 AND (r.checkin_deadline IS NULL OR rp.checked_in_at IS NOT NULL)
+		ORDER BY rp.joined_at
+	`, roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	teams := make([]tournament.Team, len(rows))
+	index := make(map[int]int, len(rows))
+	ids := make(pq.Int64Array, len(rows))
+	for i, row := range rows {
+		teams[i] = tournament.Team{
+			ID:        row.ID,
+			Name:      row.Name,
+			Rating:    row.Rating,
+			CaptainID: row.CaptainID,
+		}
+		index[row.ID] = i
+		ids[i] = int64(row.ID)
+	}
+
+	var members []models.TeamMember
+	err = sqlx.Select(q, &members, `
+		SELECT team_id, user_id, role FROM team_members
+		WHERE team_id = ANY($1)
+		ORDER BY joined_at
+	`, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, member := range members {
+		team := &teams[index[member.TeamID]]
+		if member.Role == models.TeamRoleSubstitute {
+			team.Substitutes = append(team.Substitutes, member.UserID)
+		} else {
+			team.Players = append(team.Players, member.UserID)
+		}
+	}
+
+	return teams, nil
+}
+
+// saveTournamentTeams фиксирует составы команд на момент запуска турнира
+func (h *TournamentHandlers) saveTournamentTeams(tx *sqlx.Tx, tournamentID int, teams []tournament.Team) error {
+	for _, team := range teams {
+		_, err := tx.Exec(`
+			INSERT INTO tournament_teams (tournament_id, team_id, captain_id, name, players, substitutes)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, tournamentID, team.ID, team.CaptainID, team.Name, pq.Array(team.Players), pq.Array(team.Substitutes))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncMatchTeams проставляет матчам командного турнира команды по капитанам,
+// занимающим слоты игроков. Для одиночных турниров ничего не меняет.
+func (h *TournamentHandlers) syncMatchTeams(tx *sqlx.Tx, tournamentID int) error {
+	_, err := tx.Exec(`
+		UPDATE matches m
+		SET team1_id = (SELECT team_id FROM tournament_teams WHERE tournament_id = m.tournament_id AND captain_id = m.player1_id),
+		    team2_id = (SELECT team_id FROM tournament_teams WHERE tournament_id = m.tournament_id AND captain_id = m.player2_id),
+		    winner_team_id = (SELECT team_id FROM tournament_teams WHERE tournament_id = m.tournament_id AND captain_id = m.winner_id)
+		FROM tournaments t
+		WHERE t.id = m.tournament_id AND t.id = $1 AND t.team_mode
+	`, tournamentID)
+	return err
+}
+
+// ratingSubjects определяет, чей рейтинг меняется по итогам матча: в командном
+// турнире - рейтинг команд капитанов, иначе - рейтинг самих игроков в ладдере турнира.
+func (h *TournamentHandlers) ratingSubjects(tx *sqlx.Tx, tournamentID, winnerID, loserID int) (ratingTarget, error) {
+	var t struct {
+		TeamMode bool   `db:"team_mode"`
+		Ladder   string `db:"ladder"`
+	}
+	if err := tx.Get(&t, `SELECT team_mode, ladder FROM tournaments WHERE id = $1`, tournamentID); err != nil {
+		return ratingTarget{}, err
+	}
+
+	target := ratingTarget{
+		Table:    "users",
+		Ladder:   t.Ladder,
+		WinnerID: winnerID,
+		LoserID:  loserID,
+	}
+
+	if !t.TeamMode {
+		return target, nil
+	}
+
+	target.Table = "teams"
+	err := tx.QueryRow(`
+		SELECT
+			(SELECT team_id FROM tournament_teams WHERE tournament_id = $1 AND captain_id = $2),
+			(SELECT team_id FROM tournament_teams WHERE tournament_id = $1 AND captain_id = $3)
+	`, tournamentID, winnerID, loserID).Scan(&target.WinnerID, &target.LoserID)
+	if err != nil {
+		return ratingTarget{}, err
+	}
+
+	return target, nil
+}
@@ -0,0 +1,711 @@
+// internal/handlers/teams.go
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"zzz-tournament/internal/models"
+	"zzz-tournament/internal/websocket"
+	"zzz-tournament/pkg/utils"
+	"zzz-tournament/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// TeamHandlers обработчики команд
+type TeamHandlers struct {
+	BaseHandlers
+}
+
+// NewTeamHandlers создает новый экземпляр TeamHandlers
+func NewTeamHandlers(db *sqlx.DB, hub *websocket.Hub, logger *slog.Logger) *TeamHandlers {
+	return &TeamHandlers{
+		BaseHandlers: newBaseHandlers(db, hub, logger),
+	}
+}
+
+// CreateTeamRequest структура запроса создания команды
+type CreateTeamRequest struct {
+	Name string `json:"name" binding:"required"`
+	Tag  string `json:"tag,omitempty"`
+}
+
+// UpdateTeamRequest структура запроса обновления команды
+type UpdateTeamRequest struct {
+	Name string  `json:"name,omitempty"`
+	Tag  *string `json:"tag,omitempty"`
+}
+
+// AddTeamMemberRequest структура запроса добавления игрока в состав
+type AddTeamMemberRequest struct {
+	UserID int    `json:"user_id" binding:"required"`
+	Role   string `json:"role,omitempty"` // player (по умолчанию), substitute
+}
+
+// UpdateTeamMemberRequest структура запроса смены роли игрока в составе
+type UpdateTeamMemberRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// TransferCaptainRequest структура запроса передачи капитанства
+type TransferCaptainRequest struct {
+	UserID int `json:"user_id" binding:"required"`
+}
+
+// GetTeams получение списка команд по рейтингу с поиском по названию
+func (h *TeamHandlers) GetTeams(c *gin.Context) {
+	page := getPageFromQuery(c, 1)
+	perPage := getPerPageFromQuery(c, 20)
+
+	if err := validator.ValidatePage(page); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	if err := validator.ValidatePerPage(perPage); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	where := ""
+	args := []interface{}{}
+	if search := strings.TrimSpace(c.Query("q")); search != "" {
+		args = append(args, "%"+search+"%")
+		where = "WHERE name ILIKE $1 OR tag ILIKE $1"
+	}
+
+	var total int
+	if err := h.DB.Get(&total, "SELECT COUNT(*) FROM teams "+where, args...); err != nil {
+		utils.InternalErrorResponse(c, "Failed to count teams")
+		return
+	}
+
+	args = append(args, perPage, (page-1)*perPage)
+	teams := []models.Team{}
+	err := h.DB.Select(&teams, `
+		SELECT id, name, tag, captain_id, rating, wins, losses, created_at, updated_at
+		FROM teams `+where+`
+		ORDER BY rating DESC, wins DESC, name ASC
+		LIMIT $`+strconv.Itoa(len(args)-1)+` OFFSET $`+strconv.Itoa(len(args)), args...)
+
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to fetch teams")
+		return
+	}
+
+	pagination := utils.NewPaginationMeta(page, perPage, total)
+
+	utils.PaginatedSuccessResponse(c, teams, pagination, "Teams fetched successfully")
+}
+
+// GetTeam получение команды с составом
+func (h *TeamHandlers) GetTeam(c *gin.Context) {
+	teamID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid team ID")
+		return
+	}
+
+	team, err := h.getTeam(h.DB, teamID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "Team not found")
+		} else {
+			utils.InternalErrorResponse(c, "Database error")
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, team)
+}
+
+// CreateTeam создание команды. Создатель становится капитаном и основным игроком.
+func (h *TeamHandlers) CreateTeam(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	var req CreateTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	req.Tag = strings.TrimSpace(req.Tag)
+	if errs := validateTeamFields(req.Name, req.Tag); errs.HasErrors() {
+		utils.BadRequestResponse(c, errs.Error(), validationDetails(errs)...)
+		return
+	}
+
+	var inTeam bool
+	err := h.DB.Get(&inTeam, `SELECT EXISTS(SELECT 1 FROM team_members WHERE user_id = $1)`, userID)
+	if err != nil {
+		utils.InternalErrorResponse(c, "Database error")
+		return
+	}
+
+	if inTeam {
+		utils.ConflictResponse(c, "You are already in a team")
+		return
+	}
+
+	if taken, err := h.teamNameTaken(req.Name, 0); err != nil {
+		utils.InternalErrorResponse(c, "Database error")
+		return
+	} else if taken {
+		utils.ConflictResponse(c, "Team name is already taken")
+		return
+	}
+
+	tx, err := h.DB.Beginx()
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback()
+
+	var teamID int
+	err = tx.QueryRow(`
+		INSERT INTO teams (name, tag, captain_id)
+		VALUES ($1, NULLIF($2, ''), $3)
+		RETURNING id
+	`, req.Name, req.Tag, userID).Scan(&teamID)
+
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to create team")
+		return
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO team_members (team_id, user_id, role) VALUES ($1, $2, $3)
+	`, teamID, userID, models.TeamRolePlayer)
+
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to add captain to team")
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		utils.InternalErrorResponse(c, "Failed to commit transaction")
+		return
+	}
+
+	team, err := h.getTeam(h.DB, teamID)
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to fetch created team")
+		return
+	}
+
+	h.Logger.Info("Team created", "team_id", teamID, "captain_id", userID)
+
+	utils.CreatedResponse(c, team, "Team created successfully")
+}
+
+// UpdateTeam изменение названия и тега команды (капитан)
+func (h *TeamHandlers) UpdateTeam(c *gin.Context) {
+	team, ok := h.captainTeam(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	name := team.Name
+	if req.Name != "" {
+		name = strings.TrimSpace(req.Name)
+	}
+	tag := ""
+	if team.Tag != nil {
+		tag = *team.Tag
+	}
+	if req.Tag != nil {
+		tag = strings.TrimSpace(*req.Tag)
+	}
+
+	if errs := validateTeamFields(name, tag); errs.HasErrors() {
+		utils.BadRequestResponse(c, errs.Error(), validationDetails(errs)...)
+		return
+	}
+
+	if taken, err := h.teamNameTaken(name, team.ID); err != nil {
+		utils.InternalErrorResponse(c, "Database error")
+		return
+	} else if taken {
+		utils.ConflictResponse(c, "Team name is already taken")
+		return
+	}
+
+	_, err := h.DB.Exec(`
+		UPDATE teams SET name = $1, tag = NULLIF($2, ''), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`, name, tag, team.ID)
+
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to update team")
+		return
+	}
+
+	h.respondTeam(c, team.ID, "Team updated successfully")
+}
+
+// DeleteTeam расформирование команды (капитан или администратор)
+func (h *TeamHandlers) DeleteTeam(c *gin.Context) {
+	teamID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid team ID")
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	var captainID int
+	err = h.DB.Get(&captainID, `SELECT captain_id FROM teams WHERE id = $1`, teamID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "Team not found")
+		} else {
+			utils.InternalErrorResponse(c, "Database error")
+		}
+		return
+	}
+
+	if captainID != userID && !h.isAdmin(userID) {
+		utils.ForbiddenResponse(c, "Only team captain or administrator can disband the team")
+		return
+	}
+
+	if registered, err := h.inActiveRoom(teamID); err != nil {
+		utils.InternalErrorResponse(c, "Database error")
+		return
+	} else if registered {
+		utils.ConflictResponse(c, "Team is registered in an active room")
+		return
+	}
+
+	if _, err = h.DB.Exec(`DELETE FROM teams WHERE id = $1`, teamID); err != nil {
+		utils.InternalErrorResponse(c, "Failed to delete team")
+		return
+	}
+
+	h.Logger.Info("Team disbanded", "team_id", teamID, "deleted_by", userID)
+
+	utils.SuccessResponse(c, gin.H{
+		"message": "Team disbanded successfully",
+	})
+}
+
+// AddTeamMember добавление игрока в состав (капитан)
+func (h *TeamHandlers) AddTeamMember(c *gin.Context) {
+	team, ok := h.captainTeam(c)
+	if !ok {
+		return
+	}
+
+	var req AddTeamMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	if req.Role == "" {
+		req.Role = models.TeamRolePlayer
+	}
+	if !models.IsValidTeamRole(req.Role) {
+		utils.BadRequestResponse(c, "Invalid team role")
+		return
+	}
+
+	var exists bool
+	err := h.DB.Get(&exists, `SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)`, req.UserID)
+	if err != nil {
+		utils.InternalErrorResponse(c, "Database error")
+		return
+	}
+	if !exists {
+		utils.NotFoundResponse(c, "User not found")
+		return
+	}
+
+	var inTeam bool
+	err = h.DB.Get(&inTeam, `SELECT EXISTS(SELECT 1 FROM team_members WHERE user_id = $1)`, req.UserID)
+	if err != nil {
+		utils.InternalErrorResponse(c, "Database error")
+		return
+	}
+	if inTeam {
+		utils.ConflictResponse(c, "User is already in a team")
+		return
+	}
+
+	if err := checkRosterLimit(team, req.Role); err != "" {
+		utils.BadRequestResponse(c, err)
+		return
+	}
+
+	_, err = h.DB.Exec(`
+		INSERT INTO team_members (team_id, user_id, role) VALUES ($1, $2, $3)
+	`, team.ID, req.UserID, req.Role)
+
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to add team member")
+		return
+	}
+
+	h.notifyRoster(team.ID, req.UserID, "member_added")
+	h.respondTeam(c, team.ID, "Team member added successfully")
+}
+
+// UpdateTeamMember перевод игрока в основной состав или в запасные (капитан)
+func (h *TeamHandlers) UpdateTeamMember(c *gin.Context) {
+	team, ok := h.captainTeam(c)
+	if !ok {
+		return
+	}
+
+	memberID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid user ID")
+		return
+	}
+
+	var req UpdateTeamMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	if !models.IsValidTeamRole(req.Role) {
+		utils.BadRequestResponse(c, "Invalid team role")
+		return
+	}
+
+	member := findTeamMember(team, memberID)
+	if member == nil {
+		utils.NotFoundResponse(c, "User is not in this team")
+		return
+	}
+
+	if member.Role == req.Role {
+		h.respondTeam(c, team.ID, "Team member updated successfully")
+		return
+	}
+
+	if memberID == team.CaptainID && req.Role == models.TeamRoleSubstitute {
+		utils.BadRequestResponse(c, "Captain must be in the main roster")
+		return
+	}
+
+	if err := checkRosterLimit(team, req.Role); err != "" {
+		utils.BadRequestResponse(c, err)
+		return
+	}
+
+	_, err = h.DB.Exec(`
+		UPDATE team_members SET role = $1 WHERE team_id = $2 AND user_id = $3
+	`, req.Role, team.ID, memberID)
+
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to update team member")
+		return
+	}
+
+	h.notifyRoster(team.ID, memberID, "member_updated")
+	h.respondTeam(c, team.ID, "Team member updated successfully")
+}
+
+// RemoveTeamMember исключение игрока капитаном или выход игрока из команды.
+// Капитан не может покинуть команду, не передав капитанство.
+func (h *TeamHandlers) RemoveTeamMember(c *gin.Context) {
+	teamID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid team ID")
+		return
+	}
+
+	memberID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid user ID")
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	var captainID int
+	err = h.DB.Get(&captainID, `SELECT captain_id FROM teams WHERE id = $1`, teamID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "Team not found")
+		} else {
+			utils.InternalErrorResponse(c, "Database error")
+		}
+		return
+	}
+
+	if userID != captainID && userID != memberID {
+		utils.ForbiddenResponse(c, "Only team captain can remove other players")
+		return
+	}
+
+	if memberID == captainID {
+		utils.BadRequestResponse(c, "Captain cannot leave the team, transfer the captaincy or disband the team")
+		return
+	}
+
+	result, err := h.DB.Exec(`
+		DELETE FROM team_members WHERE team_id = $1 AND user_id = $2
+	`, teamID, memberID)
+
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to remove team member")
+		return
+	}
+
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		utils.NotFoundResponse(c, "User is not in this team")
+		return
+	}
+
+	h.notifyRoster(teamID, memberID, "member_removed")
+
+	utils.SuccessResponse(c, gin.H{
+		"message": "Team member removed successfully",
+	})
+}
+
+// TransferCaptain передача капитанства основному игроку команды (капитан).
+// Капитан представляет команду в комнате, поэтому пока команда
+// зарегистрирована в активной комнате, капитан не меняется.
+func (h *TeamHandlers) TransferCaptain(c *gin.Context) {
+	team, ok := h.captainTeam(c)
+	if !ok {
+		return
+	}
+
+	var req TransferCaptainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	member := findTeamMember(team, req.UserID)
+	if member == nil {
+		utils.NotFoundResponse(c, "User is not in this team")
+		return
+	}
+
+	if member.Role != models.TeamRolePlayer {
+		utils.BadRequestResponse(c, "Captain must be in the main roster")
+		return
+	}
+
+	if req.UserID == team.CaptainID {
+		utils.BadRequestResponse(c, "User is already the captain")
+		return
+	}
+
+	if registered, err := h.inActiveRoom(team.ID); err != nil {
+		utils.InternalErrorResponse(c, "Database error")
+		return
+	} else if registered {
+		utils.ConflictResponse(c, "Team is registered in an active room")
+		return
+	}
+
+	var inRoom bool
+	err := h.DB.Get(&inRoom, `
+		SELECT EXISTS(
+			SELECT 1 FROM room_participants rp
+			JOIN rooms r ON rp.room_id = r.id
+			WHERE rp.user_id = $1 AND r.status IN ('waiting', 'check_in', 'in_progress')
+		)
+	`, req.UserID)
+	if err != nil {
+		utils.InternalErrorResponse(c, "Database error")
+		return
+	}
+	if inRoom {
+		utils.ConflictResponse(c, "New captain is already in an active room")
+		return
+	}
+
+	_, err = h.DB.Exec(`
+		UPDATE teams SET captain_id = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2
+	`, req.UserID, team.ID)
+
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to transfer captaincy")
+		return
+	}
+
+	h.Logger.Info("Team captain changed", "team_id", team.ID, "old_captain_id", team.CaptainID, "new_captain_id", req.UserID)
+
+	h.notifyRoster(team.ID, req.UserID, "captain_changed")
+	h.respondTeam(c, team.ID, "Captaincy transferred successfully")
+}
+
+// Вспомогательные функции
+
+// getTeam загружает команду с капитаном и составом
+func (h *TeamHandlers) getTeam(q sqlx.Queryer, teamID int) (*models.Team, error) {
+	var team models.Team
+	err := sqlx.Get(q, &team, `
+		SELECT id, name, tag, captain_id, rating, wins, losses, created_at, updated_at
+		FROM teams WHERE id = $1
+	`, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = sqlx.Select(q, &team.Members, `
+		SELECT tm.team_id, tm.user_id, u.username, u.rating, tm.role,
+		       (tm.user_id = t.captain_id) as is_captain, tm.joined_at
+		FROM team_members tm
+		JOIN teams t ON tm.team_id = t.id
+		JOIN users u ON tm.user_id = u.id
+		WHERE tm.team_id = $1
+		ORDER BY is_captain DESC, tm.role, tm.joined_at
+	`, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, member := range team.Members {
+		if member.IsCaptain {
+			team.Captain = &models.User{ID: member.UserID, Username: member.Username, Rating: member.Rating}
+		}
+	}
+
+	return &team, nil
+}
+
+// captainTeam загружает команду из параметров запроса и проверяет, что пользователь - ее капитан
+func (h *TeamHandlers) captainTeam(c *gin.Context) (*models.Team, bool) {
+	teamID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid team ID")
+		return nil, false
+	}
+
+	team, err := h.getTeam(h.DB, teamID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "Team not found")
+		} else {
+			utils.InternalErrorResponse(c, "Database error")
+		}
+		return nil, false
+	}
+
+	if !team.IsCaptain(c.GetInt("user_id")) {
+		utils.ForbiddenResponse(c, "Only team captain can manage the team")
+		return nil, false
+	}
+
+	return team, true
+}
+
+// respondTeam отвечает актуальным состоянием команды
+func (h *TeamHandlers) respondTeam(c *gin.Context, teamID int, message string) {
+	team, err := h.getTeam(h.DB, teamID)
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to fetch team")
+		return
+	}
+
+	utils.SuccessResponse(c, team, message)
+}
+
+// teamNameTaken проверяет, занято ли название другой командой
+func (h *TeamHandlers) teamNameTaken(name string, exceptID int) (bool, error) {
+	var taken bool
+	err := h.DB.Get(&taken, `
+		SELECT EXISTS(SELECT 1 FROM teams WHERE LOWER(name) = LOWER($1) AND id <> $2)
+	`, name, exceptID)
+	return taken, err
+}
+
+// inActiveRoom проверяет, зарегистрирована ли команда в комнате, где еще идет турнир или набор
+func (h *TeamHandlers) inActiveRoom(teamID int) (bool, error) {
+	var registered bool
+	err := h.DB.Get(&registered, `
+		SELECT EXISTS(
+			SELECT 1 FROM room_participants rp
+			JOIN rooms r ON rp.room_id = r.id
+			WHERE rp.team_id = $1 AND r.status IN ('waiting', 'check_in', 'in_progress')
+		)
+	`, teamID)
+	return registered, err
+}
+
+// notifyRoster сообщает игрокам команды об изменении состава
+func (h *TeamHandlers) notifyRoster(teamID, userID int, action string) {
+	var members []int
+	if err := h.DB.Select(&members, `SELECT user_id FROM team_members WHERE team_id = $1`, teamID); err != nil {
+		return
+	}
+
+	wsMsg := models.WSMessage{
+		Type: "team_updated",
+		Data: gin.H{
+			"team_id": teamID,
+			"action":  action,
+			"user_id": userID,
+		},
+	}
+	msgBytes, _ := json.Marshal(wsMsg)
+
+	// Исключенный игрок тоже получает уведомление
+	recipients := append(members, userID)
+	sent := make(map[int]bool, len(recipients))
+	for _, id := range recipients {
+		if !sent[id] {
+			sent[id] = true
+			h.Hub.SendToUser(id, msgBytes)
+		}
+	}
+}
+
+// validateTeamFields проверяет название и тег команды
+func validateTeamFields(name, tag string) validator.ValidationErrors {
+	var errs validator.ValidationErrors
+
+	if err := validator.ValidateTextLength(name, "name", models.MinTeamNameLength, models.MaxTeamNameLength); err != nil {
+		errs = append(errs, *err)
+	}
+	if tag != "" {
+		if err := validator.ValidateTextLength(tag, "tag", 1, models.MaxTeamTagLength); err != nil {
+			errs = append(errs, *err)
+		}
+	}
+
+	return errs
+}
+
+// checkRosterLimit проверяет, есть ли в составе место для игрока с указанной ролью
+func checkRosterLimit(team *models.Team, role string) string {
+	if role == models.TeamRoleSubstitute && len(team.Substitutes()) >= models.MaxTeamSubstitutes {
+		return "Team can have at most " + strconv.Itoa(models.MaxTeamSubstitutes) + " substitutes"
+	}
+	if role == models.TeamRolePlayer && len(team.Players()) >= models.MaxTeamPlayers {
+		return "Team can have at most " + strconv.Itoa(models.MaxTeamPlayers) + " main players"
+	}
+	return ""
+}
+
+// findTeamMember ищет игрока в составе команды
+func findTeamMember(team *models.Team, userID int) *models.TeamMember {
+	for i := range team.Members {
+		if team.Members[i].UserID == userID {
+			return &team.Members[i]
+		}
+	}
+	return nil
+}
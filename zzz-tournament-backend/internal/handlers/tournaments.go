@@ -4,42 +4,123 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"strconv"
 	"time"
 
 	"zzz-tournament/internal/models"
 	"zzz-tournament/internal/websocket"
+	"zzz-tournament/pkg/config"
 	"zzz-tournament/pkg/rating"
 	"zzz-tournament/pkg/tournament"
 	"zzz-tournament/pkg/utils"
+	"zzz-tournament/pkg/validator"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 // TournamentHandlers обработчики турниров
 type TournamentHandlers struct {
 	BaseHandlers
+	Config *config.TournamentConfig
+	Rating rating.Engine
 }
 
 // NewTournamentHandlers создает новый экземпляр TournamentHandlers
-func NewTournamentHandlers(db *sqlx.DB, hub *websocket.Hub, logger *slog.Logger) *TournamentHandlers {
+func NewTournamentHandlers(db *sqlx.DB, hub *websocket.Hub, logger *slog.Logger, tournamentConfig *config.TournamentConfig, ratingConfig *config.RatingConfig) *TournamentHandlers {
 	return &TournamentHandlers{
 		BaseHandlers: newBaseHandlers(db, hub, logger),
+		Config:       tournamentConfig,
+		Rating:       rating.NewEngine(ratingConfig.Engine, ratingConfig.IdlePeriod),
 	}
 }
 
 // StartTournamentRequest структура запроса запуска турнира
 type StartTournamentRequest struct {
-	Name   string `json:"name,omitempty"`
-	Seeded bool   `json:"seeded"` // Использовать посевную сетку
+	Name            string `json:"name,omitempty"`
+	Format          string `json:"format,omitempty"`            // single_elimination (по умолчанию), double_elimination, round_robin, groups_playoffs, swiss, score_attack
+	Seeded          bool   `json:"seeded"`                      // Использовать посевную сетку
+	BracketReset    bool   `json:"bracket_reset"`               // Повторный гранд-финал в double elimination
+	ThirdPlaceMatch bool   `json:"third_place_match"`           // Матч за третье место (single elimination и плей-офф)
+	Groups          int    `json:"groups,omitempty"`            // Количество групп в groups_playoffs
+	AdvancePerGroup int    `json:"advance_per_group,omitempty"` // Сколько игроков из группы выходят в плей-офф
+	SwissRounds     int    `json:"swiss_rounds,omitempty"`      // Количество туров швейцарской системы (по умолчанию log2 от числа игроков)
+
+	BestOf        int         `json:"best_of,omitempty"`          // Игр в серии по умолчанию (1, 3, 5 ...)
+	BestOfByRound map[int]int `json:"best_of_by_round,omitempty"` // Игр в серии по номеру раунда
+	FinalsBestOf  int         `json:"finals_best_of,omitempty"`   // Игр в серии финальных матчей
+
+	MatchDeadlineMinutes *int   `json:"match_deadline_minutes,omitempty"` // Время на матч (0 - без срока, по умолчанию из конфигурации)
+	WalkoverRule         string `json:"walkover_rule,omitempty"`          // ready, rating, none (по умолчанию из конфигурации)
+
+	DraftBans        int    `json:"draft_bans,omitempty"`         // Банов у каждого игрока
+	DraftPicks       int    `json:"draft_picks,omitempty"`        // Пиков у каждого игрока (0 - без драфта)
+	DraftTurnSeconds int    `json:"draft_turn_seconds,omitempty"` // Время на ход драфта (по умолчанию из конфигурации)
+	DraftTimeoutRule string `json:"draft_timeout_rule,omitempty"` // auto_pick, skip (по умолчанию из конфигурации)
+
+	Ruleset *models.Ruleset `json:"ruleset,omitempty"` // Правила составов команд
+
+	ScoreType   string     `json:"score_type,omitempty"`    // score (по умолчанию), time - для score_attack
+	RunsOpenAt  *time.Time `json:"runs_open_at,omitempty"`  // Начало приема заходов (по умолчанию сразу)
+	RunsCloseAt *time.Time `json:"runs_close_at,omitempty"` // Окончание приема заходов, обязательно для score_attack
+
+	Ladder string `json:"ladder,omitempty"` // Ладдер рейтинга: tournament (по умолчанию), casual
+}
+
+// GroupTable турнирная таблица группы
+type GroupTable struct {
+	Group     int                   `json:"group"`
+	Standings []tournament.Standing `json:"standings"`
 }
 
 // SubmitMatchResultRequest структура запроса результата матча
 type SubmitMatchResultRequest struct {
-	WinnerID int    `json:"winner_id" binding:"required"`
-	Details  string `json:"details,omitempty"` // Дополнительные детали матча
+	WinnerID int          `json:"winner_id,omitempty"`                      // Победитель матча из одной игры
+	Games    []GameReport `json:"games,omitempty" binding:"omitempty,dive"` // Результаты игр серии best-of-N
+	Details  string       `json:"details,omitempty"`                        // Дополнительные детали матча
+}
+
+// GameReport результат отдельной игры серии
+type GameReport struct {
+	GameNumber    int   `json:"game_number" binding:"required,min=1,max=9"`
+	WinnerID      int   `json:"winner_id" binding:"required"`
+	Player1Score  *int  `json:"player1_score,omitempty"`
+	Player2Score  *int  `json:"player2_score,omitempty"`
+	ClearTimeMs   *int  `json:"clear_time_ms,omitempty" binding:"omitempty,min=1"`
+	Player1Heroes []int `json:"player1_heroes,omitempty"`
+	Player2Heroes []int `json:"player2_heroes,omitempty"`
+}
+
+// ResolveMatchDisputeRequest структура запроса разрешения спора о результате
+type ResolveMatchDisputeRequest struct {
+	WinnerID int          `json:"winner_id,omitempty"`                      // Победитель матча
+	Games    []GameReport `json:"games,omitempty" binding:"omitempty,dive"` // Итоговые результаты игр серии
+	Reason   string       `json:"reason" binding:"required,min=3,max=500"`  // Обоснование решения
+}
+
+// RevertMatchResultRequest структура запроса отмены результата матча
+type RevertMatchResultRequest struct {
+	Cascade bool   `json:"cascade"`                                 // Отменить также уже сыгранные следующие матчи
+	Reason  string `json:"reason" binding:"required,min=3,max=500"` // Причина отмены
+}
+
+// matchReportResult итог сравнения отчетов участников
+type matchReportResult struct {
+	Agreed   []GameReport // Игры, результат которых совпал в обоих отчетах
+	Disputed bool         // Отчеты противоречат друг другу
+}
+
+// matchOutcome итог завершенного матча
+type matchOutcome struct {
+	WinnerID           int
+	LoserID            int
+	Walkover           bool
+	TournamentFinished bool
+	TournamentWinnerID int
 }
 
 // GetTournamentsQuery параметры фильтрации турниров
@@ -65,12 +146,151 @@ func (h *TournamentHandlers) StartTournament(c *gin.Context) {
 	var req StartTournamentRequest
 	c.ShouldBindJSON(&req)
 
+	if req.Format == "" {
+		req.Format = models.TournamentFormatSingleElimination
+	}
+
+	if !models.IsValidTournamentFormat(req.Format) {
+		utils.BadRequestResponse(c, "Invalid tournament format")
+		return
+	}
+
+	if req.Ladder == "" {
+		req.Ladder = models.RatingTypeTournament
+	}
+
+	if req.Ladder != models.RatingTypeTournament && req.Ladder != models.RatingTypeCasual {
+		utils.BadRequestResponse(c, "Invalid ladder, expected tournament or casual")
+		return
+	}
+
+	series := tournament.SeriesFormat{
+		BestOf:       req.BestOf,
+		ByRound:      req.BestOfByRound,
+		FinalsBestOf: req.FinalsBestOf,
+	}
+	if series.BestOf == 0 {
+		series.BestOf = 1
+	}
+	if err := series.Validate(); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	deadlineMinutes := int(h.Config.MatchDeadline.Minutes())
+	if req.MatchDeadlineMinutes != nil {
+		deadlineMinutes = *req.MatchDeadlineMinutes
+	}
+	if deadlineMinutes < 0 || deadlineMinutes > MaxMatchDeadlineMinutes {
+		utils.BadRequestResponse(c, "Match deadline must be between 0 and "+strconv.Itoa(MaxMatchDeadlineMinutes)+" minutes")
+		return
+	}
+
+	if req.WalkoverRule == "" {
+		req.WalkoverRule = h.Config.WalkoverRule
+	}
+	if !config.IsValidWalkoverRule(req.WalkoverRule) {
+		utils.BadRequestResponse(c, "Invalid walkover rule")
+		return
+	}
+
+	draft := tournament.DraftFormat{Bans: req.DraftBans, Picks: req.DraftPicks}
+	if err := draft.Validate(); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	if req.DraftTurnSeconds == 0 {
+		req.DraftTurnSeconds = int(h.Config.DraftTurnDuration.Seconds())
+	}
+	if req.DraftTurnSeconds < MinDraftTurnSeconds || req.DraftTurnSeconds > MaxDraftTurnSeconds {
+		utils.BadRequestResponse(c, "Draft turn time must be between "+strconv.Itoa(MinDraftTurnSeconds)+
+			" and "+strconv.Itoa(MaxDraftTurnSeconds)+" seconds")
+		return
+	}
+
+	if req.DraftTimeoutRule == "" {
+		req.DraftTimeoutRule = h.Config.DraftTimeoutRule
+	}
+	if !config.IsValidDraftTimeoutRule(req.DraftTimeoutRule) {
+		utils.BadRequestResponse(c, "Invalid draft timeout rule")
+		return
+	}
+
+	if req.Ruleset != nil {
+		errs, err := h.validateRuleset(req.Ruleset)
+		if err != nil {
+			utils.InternalErrorResponse(c, "Database error")
+			return
+		}
+		if errs.HasErrors() {
+			utils.BadRequestResponse(c, errs.Error(), validationDetails(errs)...)
+			return
+		}
+	}
+
+	if draft.Enabled() {
+		var activeHeroes []models.Hero
+		err := h.DB.Select(&activeHeroes, `
+			SELECT id, name, element, rarity, role, is_active FROM heroes WHERE is_active = true
+		`)
+		if err != nil {
+			utils.InternalErrorResponse(c, "Database error")
+			return
+		}
+
+		available := 0
+		for _, hero := range activeHeroes {
+			if req.Ruleset == nil || req.Ruleset.HeroAllowed(hero) {
+				available++
+			}
+		}
+		if available < draft.HeroesRequired() {
+			utils.BadRequestResponse(c, "Not enough allowed heroes for the draft: need "+strconv.Itoa(draft.HeroesRequired()))
+			return
+		}
+	}
+
+	var scoreType *string
+	if req.Format == models.TournamentFormatScoreAttack {
+		if err := validateRunWindow(&req); err != nil {
+			utils.BadRequestResponse(c, err.Error())
+			return
+		}
+		if draft.Enabled() {
+			utils.BadRequestResponse(c, "Hero draft is not available for score attack tournaments")
+			return
+		}
+		scoreType = &req.ScoreType
+	} else {
+		req.RunsOpenAt, req.RunsCloseAt = nil, nil
+	}
+
+	if req.ThirdPlaceMatch && req.Format != models.TournamentFormatSingleElimination &&
+		req.Format != models.TournamentFormatGroupsPlayoffs {
+		utils.BadRequestResponse(c, "Third place match is available only for single elimination and playoffs")
+		return
+	}
+
+	if req.Format == models.TournamentFormatGroupsPlayoffs {
+		if req.Groups < 1 {
+			utils.BadRequestResponse(c, "Number of groups must be at least 1")
+			return
+		}
+		if req.AdvancePerGroup < 1 {
+			utils.BadRequestResponse(c, "Number of advancing players must be at least 1")
+			return
+		}
+	}
+
 	// Проверяем, что пользователь является хостом комнаты
 	var hostID int
 	var roomStatus string
+	var teamMode bool
+	var teamSize sql.NullInt64
 	err = h.DB.QueryRow(`
-		SELECT host_id, status FROM rooms WHERE id = $1
-	`, roomID).Scan(&hostID, &roomStatus)
+		SELECT host_id, status, team_mode, team_size FROM rooms WHERE id = $1
+	`, roomID).Scan(&hostID, &roomStatus, &teamMode, &teamSize)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -86,29 +306,77 @@ func (h *TournamentHandlers) StartTournament(c *gin.Context) {
 		return
 	}
 
+	if roomStatus == models.RoomStatusCheckIn {
+		utils.BadRequestResponse(c, "Check-in is still open")
+		return
+	}
+
 	if roomStatus != "waiting" {
 		utils.BadRequestResponse(c, "Room is not in waiting status")
 		return
 	}
 
-	// Получаем участников комнаты
-	var participants []models.User
-	err = h.DB.Select(&participants, `
-		SELECT u.id, u.username, u.rating
-		FROM users u
-		JOIN room_participants rp ON u.id = rp.user_id
-		WHERE rp.room_id = $1
-		ORDER BY rp.joined_at
-	`, roomID)
-
-	if err != nil {
-		utils.InternalErrorResponse(c, "Failed to get participants")
+	if teamMode && req.Format == models.TournamentFormatScoreAttack {
+		utils.BadRequestResponse(c, "Score attack tournaments are not available in team mode")
 		return
 	}
 
-	if len(participants) < 2 {
-		utils.BadRequestResponse(c, "Need at least 2 participants to start tournament")
-		return
+	var players []tournament.Player
+	var teams []tournament.Team
+	if teamMode {
+		// В командной комнате участники сетки - команды, их представляют капитаны
+		teams, err = h.loadRoomTeams(h.DB, roomID)
+		if err != nil {
+			utils.InternalErrorResponse(c, "Failed to get teams")
+			return
+		}
+
+		size := DefaultTeamSize
+		if teamSize.Valid {
+			size = int(teamSize.Int64)
+		}
+
+		players, err = tournament.TeamPlayers(teams, size)
+		if err != nil {
+			utils.BadRequestResponse(c, err.Error())
+			return
+		}
+	} else {
+		// Получаем участников комнаты. Если проводился check-in, в турнир попадают
+		// только подтвердившие участие. Посев идет по рейтингу в ладдере турнира,
+		// как в ladderRating: без матчей в ладдере - начальный рейтинг, а для
+		// турнирного ладдера - основной рейтинг пользователя.
+		var participants []models.User
+		err = h.DB.Select(&participants, `
+			SELECT u.id, u.username,
+			       COALESCE(ur.rating, CASE WHEN $2 = 'tournament' THEN u.rating ELSE $3 END) as rating
+			FROM users u
+			JOIN room_participants rp ON u.id = rp.user_id
+			JOIN rooms r ON rp.room_id = r.id
+			LEFT JOIN user_ratings ur ON ur.user_id = u.id AND ur.ladder = $2
+			WHERE rp.room_id = $1 AND (r.checkin_deadline IS NULL OR rp.checked_in_at IS NOT NULL)
+			ORDER BY rp.joined_at
+		`, roomID, req.Ladder, rating.DefaultRating)
+
+		if err != nil {
+			utils.InternalErrorResponse(c, "Failed to get participants")
+			return
+		}
+
+		if len(participants) < 2 {
+			utils.BadRequestResponse(c, "Need at least 2 participants to start tournament")
+			return
+		}
+
+		// Конвертируем участников в формат для генерации сетки
+		players = make([]tournament.Player, len(participants))
+		for i, p := range participants {
+			players[i] = tournament.Player{
+				ID:       p.ID,
+				Username: p.Username,
+				Rating:   p.Rating,
+			}
+		}
 	}
 
 	// Проверяем, не существует ли уже турнир для этой комнаты
@@ -136,41 +404,81 @@ func (h *TournamentHandlers) StartTournament(c *gin.Context) {
 		tournamentName = "Tournament for Room " + strconv.Itoa(roomID)
 	}
 
-	var tournamentID int
-	err = tx.QueryRow(`
-		INSERT INTO tournaments (room_id, name, status, created_at)
-		VALUES ($1, $2, 'started', CURRENT_TIMESTAMP)
-		RETURNING id
-	`, roomID, tournamentName).Scan(&tournamentID)
+	// Генерируем турнирную сетку
+	var bracket *tournament.Bracket
+	switch {
+	case req.Format == models.TournamentFormatRoundRobin:
+		bracket, err = tournament.GenerateRoundRobin(players)
+	case req.Format == models.TournamentFormatGroupsPlayoffs:
+		bracket, err = tournament.GenerateGroupStage(players, tournament.GroupStageOptions{
+			Groups:  req.Groups,
+			Advance: req.AdvancePerGroup,
+			Seeded:  req.Seeded,
+		})
+		if err != nil {
+			utils.BadRequestResponse(c, err.Error())
+			return
+		}
+	case req.Format == models.TournamentFormatSwiss:
+		bracket, err = tournament.GenerateSwiss(players, req.SwissRounds)
+		if err != nil {
+			utils.BadRequestResponse(c, err.Error())
+			return
+		}
+	case req.Format == models.TournamentFormatScoreAttack:
+		bracket, err = tournament.GenerateScoreAttack(players)
+	case req.Format == models.TournamentFormatDoubleElimination:
+		bracket, err = tournament.GenerateDoubleEliminationBracket(players, tournament.DoubleEliminationOptions{
+			Seeded:       req.Seeded,
+			BracketReset: req.BracketReset,
+		})
+	case req.Seeded:
+		bracket, err = tournament.GenerateSeededBracket(players)
+	default:
+		bracket, err = tournament.GenerateBracket(players)
+	}
 
 	if err != nil {
-		utils.InternalErrorResponse(c, "Failed to create tournament")
+		utils.InternalErrorResponse(c, "Failed to generate tournament bracket")
 		return
 	}
 
-	// Конвертируем участников в формат для генерации сетки
-	players := make([]tournament.Player, len(participants))
-	for i, p := range participants {
-		players[i] = tournament.Player{
-			ID:       p.ID,
-			Username: p.Username,
-			Rating:   p.Rating,
+	// Матч за третье место: в плей-офф добавляется при его формировании
+	if req.ThirdPlaceMatch {
+		if req.Format == models.TournamentFormatGroupsPlayoffs {
+			bracket.ThirdPlace = true
+		} else if err := bracket.AddThirdPlaceMatch(); err != nil {
+			utils.BadRequestResponse(c, err.Error())
+			return
 		}
 	}
 
-	// Генерируем турнирную сетку
-	var bracket *tournament.Bracket
-	if req.Seeded {
-		bracket, err = tournament.GenerateSeededBracket(players)
-	} else {
-		bracket, err = tournament.GenerateBracket(players)
-	}
+	bracket.Series = &series
+	bracket.Series.Apply(bracket.Matches)
+	bracket.TeamMode = teamMode
+
+	groupCount := len(bracket.Groups)
+
+	var tournamentID int
+	err = tx.QueryRow(`
+		INSERT INTO tournaments (room_id, name, status, format, group_count, advance_per_group,
+		                         match_deadline_minutes, walkover_rule,
+		                         draft_bans, draft_picks, draft_turn_seconds, draft_timeout_rule, ruleset,
+		                         score_type, runs_open_at, runs_close_at, team_mode, ladder, created_at)
+		VALUES ($1, $2, 'started', $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, CURRENT_TIMESTAMP)
+		RETURNING id
+	`, roomID, tournamentName, req.Format, groupCount, bracket.Advance,
+		deadlineMinutes, req.WalkoverRule,
+		draft.Bans, draft.Picks, req.DraftTurnSeconds, req.DraftTimeoutRule, req.Ruleset,
+		scoreType, req.RunsOpenAt, req.RunsCloseAt, teamMode, req.Ladder).Scan(&tournamentID)
 
 	if err != nil {
-		utils.InternalErrorResponse(c, "Failed to generate tournament bracket")
+		utils.InternalErrorResponse(c, "Failed to create tournament")
 		return
 	}
 
+	bracket.TournamentID = tournamentID
+
 	// Сохраняем сетку в JSON
 	bracketJSON, err := json.Marshal(bracket)
 	if err != nil {
@@ -188,17 +496,26 @@ func (h *TournamentHandlers) StartTournament(c *gin.Context) {
 		return
 	}
 
+	// Фиксируем составы команд
+	if err = h.saveTournamentTeams(tx, tournamentID, teams); err != nil {
+		utils.InternalErrorResponse(c, "Failed to save team rosters")
+		return
+	}
+
 	// Создаем матчи
-	for _, match := range bracket.Matches {
-		_, err = tx.Exec(`
-			INSERT INTO matches (tournament_id, round, player1_id, player2_id, status)
-			VALUES ($1, $2, $3, $4, 'pending')
-		`, tournamentID, match.Round, match.Player1ID, match.Player2ID)
+	if err = h.insertBracketMatches(tx, tournamentID, bracket.Matches, 0); err != nil {
+		utils.InternalErrorResponse(c, "Failed to create matches")
+		return
+	}
 
-		if err != nil {
-			utils.InternalErrorResponse(c, "Failed to create matches")
-			return
-		}
+	if err = h.syncMatchTeams(tx, tournamentID); err != nil {
+		utils.InternalErrorResponse(c, "Failed to assign match teams")
+		return
+	}
+
+	if err = h.setMatchDeadlines(tx, tournamentID); err != nil {
+		utils.InternalErrorResponse(c, "Failed to set match deadlines")
+		return
 	}
 
 	// Обновляем статус комнаты
@@ -246,9 +563,16 @@ func (h *TournamentHandlers) GetTournament(c *gin.Context) {
 	}
 
 	// Получаем основную информацию о турнире
-	var tournament models.Tournament
-	err = h.DB.Get(&tournament, `
-		SELECT id, room_id, name, status, bracket, winner_id, created_at, updated_at
+	var row struct {
+		models.Tournament
+		BracketJSON []byte `db:"bracket_json"`
+	}
+	err = h.DB.Get(&row, `
+		SELECT id, room_id, name, status, format, group_count, advance_per_group,
+		       match_deadline_minutes, walkover_rule,
+		       draft_bans, draft_picks, draft_turn_seconds, draft_timeout_rule, ruleset,
+		       score_type, runs_open_at, runs_close_at, team_mode, winner_team_id, ladder,
+		       bracket as bracket_json, winner_id, created_at, updated_at
 		FROM tournaments WHERE id = $1
 	`, tournamentID)
 
@@ -261,11 +585,27 @@ func (h *TournamentHandlers) GetTournament(c *gin.Context) {
 		return
 	}
 
+	tournament := row.Tournament
+	if len(row.BracketJSON) > 0 {
+		json.Unmarshal(row.BracketJSON, &tournament.Bracket)
+	}
+
 	// Получаем матчи турнира
-	var matches []models.Match
-	err = h.DB.Select(&matches, `
-		SELECT m.id, m.tournament_id, m.round, m.player1_id, m.player2_id, 
-		       m.winner_id, m.status, m.created_at, m.updated_at,
+	var matchRows []struct {
+		models.Match
+		Player1Username sql.NullString `db:"player1_username"`
+		Player1Rating   sql.NullInt64  `db:"player1_rating"`
+		Player2Username sql.NullString `db:"player2_username"`
+		Player2Rating   sql.NullInt64  `db:"player2_rating"`
+		WinnerUsername  sql.NullString `db:"winner_username"`
+	}
+	err = h.DB.Select(&matchRows, `
+		SELECT m.id, m.tournament_id, m.round,
+		       COALESCE(m.player1_id, 0) as player1_id, COALESCE(m.player2_id, 0) as player2_id,
+		       m.winner_id, m.status, m.bracket, m.position, COALESCE(m.group_number, 0) as group_number,
+		       m.next_match_id, m.next_slot, m.loser_match_id, m.loser_slot, m.best_of,
+		       m.deadline, m.is_walkover, m.draft_status, m.team1_id, m.team2_id, m.winner_team_id,
+		       m.created_at, m.updated_at,
 		       p1.username as player1_username, p1.rating as player1_rating,
 		       p2.username as player2_username, p2.rating as player2_rating,
 		       w.username as winner_username
@@ -274,32 +614,35 @@ func (h *TournamentHandlers) GetTournament(c *gin.Context) {
 		LEFT JOIN users p2 ON m.player2_id = p2.id
 		LEFT JOIN users w ON m.winner_id = w.id
 		WHERE m.tournament_id = $1
-		ORDER BY m.round, m.id
+		ORDER BY m.bracket DESC, m.group_number, m.round, m.position, m.id
 	`, tournamentID)
 
+	var matches []models.Match
 	if err == nil {
 		// Заполняем информацию об игроках
-		for i := range matches {
-			if matches[i].Player1ID > 0 {
-				matches[i].Player1 = &models.User{
-					ID:       matches[i].Player1ID,
-					Username: "", // Заполнится из запроса выше
-					Rating:   0,  // Заполнится из запроса выше
+		for _, r := range matchRows {
+			match := r.Match
+			if match.Player1ID > 0 {
+				match.Player1 = &models.User{
+					ID:       match.Player1ID,
+					Username: r.Player1Username.String,
+					Rating:   int(r.Player1Rating.Int64),
 				}
 			}
-			if matches[i].Player2ID > 0 {
-				matches[i].Player2 = &models.User{
-					ID:       matches[i].Player2ID,
-					Username: "", // Заполнится из запроса выше
-					Rating:   0,  // Заполнится из запроса выше
+			if match.Player2ID > 0 {
+				match.Player2 = &models.User{
+					ID:       match.Player2ID,
+					Username: r.Player2Username.String,
+					Rating:   int(r.Player2Rating.Int64),
 				}
 			}
-			if matches[i].WinnerID != nil {
-				matches[i].Winner = &models.User{
-					ID:       *matches[i].WinnerID,
-					Username: "", // Заполнится из запроса выше
+			if match.WinnerID != nil {
+				match.Winner = &models.User{
+					ID:       *match.WinnerID,
+					Username: r.WinnerUsername.String,
 				}
 			}
+			matches = append(matches, match)
 		}
 		tournament.Matches = matches
 	}
@@ -337,10 +680,73 @@ func (h *TournamentHandlers) GetTournament(c *gin.Context) {
 		}
 	}
 
-	utils.SuccessResponse(c, gin.H{
+	response := gin.H{
 		"tournament": tournament,
 		"progress":   progress,
-	})
+	}
+
+	// Составы команд командного турнира
+	if tournament.TeamMode {
+		var teams []models.TournamentTeam
+		err = h.DB.Select(&teams, `
+			SELECT tournament_id, team_id, captain_id, name, players, substitutes
+			FROM tournament_teams
+			WHERE tournament_id = $1
+			ORDER BY name
+		`, tournamentID)
+
+		if err == nil {
+			response["teams"] = teams
+		}
+	}
+
+	// Итоговые места завершенного турнира
+	if tournament.IsFinished() {
+		var placements []models.TournamentPlacement
+		err = h.DB.Select(&placements, `
+			SELECT tp.tournament_id, tp.user_id, u.username, tp.place, tp.place_to, tp.team_id, tp.created_at
+			FROM tournament_placements tp
+			JOIN users u ON tp.user_id = u.id
+			WHERE tp.tournament_id = $1
+			ORDER BY tp.place, u.username
+		`, tournamentID)
+
+		if err == nil {
+			response["placements"] = placements
+		}
+	}
+
+	// Таблица швейцарской системы
+	if tournament.Format == models.TournamentFormatSwiss {
+		standings, _, err := h.calculateSwissStandings(h.DB, tournamentID, row.BracketJSON)
+		if err != nil {
+			h.Logger.Error("Failed to build swiss standings", "tournament_id", tournamentID, "error", err)
+		} else {
+			response["standings"] = standings
+		}
+	}
+
+	// Таблица заходов турнира на очки или время
+	if tournament.Format == models.TournamentFormatScoreAttack {
+		leaderboard, err := h.getLeaderboard(tournamentID)
+		if err != nil {
+			h.Logger.Error("Failed to build leaderboard", "tournament_id", tournamentID, "error", err)
+		} else {
+			response["leaderboard"] = leaderboard
+		}
+	}
+
+	// Таблицы групп для кругового и группового этапа
+	if tournament.HasGroupStage() {
+		groups, err := h.getGroupTables(tournamentID, row.BracketJSON)
+		if err != nil {
+			h.Logger.Error("Failed to build group tables", "tournament_id", tournamentID, "error", err)
+		} else {
+			response["groups"] = groups
+		}
+	}
+
+	utils.SuccessResponse(c, response)
 }
 
 // GetTournaments получение списка турниров с фильтрацией
@@ -426,7 +832,7 @@ func (h *TournamentHandlers) GetTournaments(c *gin.Context) {
 
 	// Основной запрос
 	mainQuery := `
-		SELECT t.id, t.room_id, t.name, t.status, t.winner_id, t.created_at, t.updated_at,
+		SELECT t.id, t.room_id, t.name, t.status, t.format, t.winner_id, t.created_at, t.updated_at,
 		       r.name as room_name, u.username as winner_username
 		FROM tournaments t
 		JOIN rooms r ON t.room_id = r.id
@@ -450,7 +856,10 @@ func (h *TournamentHandlers) GetTournaments(c *gin.Context) {
 	utils.PaginatedSuccessResponse(c, tournaments, pagination, "Tournaments fetched successfully")
 }
 
-// SubmitMatchResult отправка результата матча
+// SubmitMatchResult отправка результата матча. Участники сообщают результат
+// независимо друг от друга: совпавшие отчеты засчитываются автоматически,
+// расхождение переводит матч в статус disputed. Хост или администратор, не
+// играющий в матче, отправляет окончательный результат сразу.
 func (h *TournamentHandlers) SubmitMatchResult(c *gin.Context) {
 	tournamentID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -473,16 +882,7 @@ func (h *TournamentHandlers) SubmitMatchResult(c *gin.Context) {
 	}
 
 	// Проверяем, что матч принадлежит турниру
-	var match models.Match
-	var roomID int
-	err = h.DB.QueryRow(`
-		SELECT m.id, m.tournament_id, m.round, m.player1_id, m.player2_id, m.status, t.room_id
-		FROM matches m
-		JOIN tournaments t ON m.tournament_id = t.id
-		WHERE m.id = $1 AND m.tournament_id = $2
-	`, matchID, tournamentID).Scan(&match.ID, &match.TournamentID, &match.Round,
-		&match.Player1ID, &match.Player2ID, &match.Status, &roomID)
-
+	match, roomID, hostID, err := h.getMatchForResult(tournamentID, matchID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			utils.NotFoundResponse(c, "Match not found")
@@ -492,32 +892,46 @@ func (h *TournamentHandlers) SubmitMatchResult(c *gin.Context) {
 		return
 	}
 
-	// Проверяем, что пользователь является участником матча или хостом комнаты
-	var isParticipant bool
-	var isHost bool
+	// Проверяем, что пользователь является участником матча, хостом комнаты или администратором.
+	// В командном матче слоты участников занимают капитаны, поэтому результат
+	// от имени команды отправляет только капитан.
+	isParticipant := match.IsParticipant(userID)
+	isReferee := hostID == userID || h.isAdmin(userID)
 
-	if userID == match.Player1ID || userID == match.Player2ID {
-		isParticipant = true
-	}
-
-	var hostID int
-	err = h.DB.Get(&hostID, `SELECT host_id FROM rooms WHERE id = $1`, roomID)
-	if err == nil && hostID == userID {
-		isHost = true
+	if !isParticipant && !isReferee {
+		if match.IsTeamMatch() {
+			utils.ForbiddenResponse(c, "Only team captains, room host or administrator can submit results")
+		} else {
+			utils.ForbiddenResponse(c, "Only match participants, room host or administrator can submit results")
+		}
+		return
 	}
 
-	if !isParticipant && !isHost {
-		utils.ForbiddenResponse(c, "Only match participants or room host can submit results")
+	if respondIfMatchClosed(c, match) {
 		return
 	}
 
-	if match.Status != "pending" {
-		utils.BadRequestResponse(c, "Match is already completed")
-		return
+	// Матч из одной игры можно отправить только с победителем
+	games := req.Games
+	if len(games) == 0 {
+		if req.WinnerID == 0 {
+			utils.BadRequestResponse(c, "Either winner_id or games must be provided")
+			return
+		}
+		if match.BestOf > 1 {
+			utils.BadRequestResponse(c, "Per-game results are required for best-of-"+strconv.Itoa(match.BestOf)+" match")
+			return
+		}
+		games = []GameReport{{GameNumber: 1, WinnerID: req.WinnerID}}
 	}
 
-	if req.WinnerID != match.Player1ID && req.WinnerID != match.Player2ID {
-		utils.BadRequestResponse(c, "Winner must be one of the match participants")
+	if err := h.validateGameReports(match, games); err != nil {
+		var errs validator.ValidationErrors
+		if errors.As(err, &errs) {
+			utils.BadRequestResponse(c, errs.Error(), validationDetails(errs)...)
+		} else {
+			utils.BadRequestResponse(c, err.Error())
+		}
 		return
 	}
 
@@ -529,114 +943,464 @@ func (h *TournamentHandlers) SubmitMatchResult(c *gin.Context) {
 	}
 	defer tx.Rollback()
 
-	// Обновляем результат матча
-	_, err = tx.Exec(`
-		UPDATE matches
-		SET winner_id = $1, status = 'finished', updated_at = CURRENT_TIMESTAMP
-		WHERE id = $2
-	`, req.WinnerID, matchID)
-
-	if err != nil {
-		utils.InternalErrorResponse(c, "Failed to update match")
+	// Статус перепроверяется под блокировкой: параллельный отчет, решение судьи
+	// или техническая победа могли завершить матч после первой проверки
+	if err = lockMatchStatus(tx, &match); err != nil {
+		utils.InternalErrorResponse(c, "Database error")
 		return
 	}
-
-	// Определяем проигравшего
-	loserID := match.Player1ID
-	if req.WinnerID == match.Player1ID {
-		loserID = match.Player2ID
-	}
-
-	// Обновляем рейтинги игроков
-	err = h.updatePlayerRatings(tx, req.WinnerID, loserID)
-	if err != nil {
-		utils.InternalErrorResponse(c, "Failed to update player ratings")
+	if respondIfMatchClosed(c, match) {
 		return
 	}
 
-	// Продвигаем турнир (создаем следующий матч если нужно)
-	err = h.advanceTournament(tx, tournamentID, matchID, req.WinnerID)
-	if err != nil {
-		utils.InternalErrorResponse(c, "Failed to advance tournament")
-		return
-	}
+	// Участник отправляет отчет, засчитываются только игры, подтвержденные соперником
+	confirmed := games
+	if isParticipant {
+		report, err := h.saveMatchReport(tx, match, userID, games, req.Details)
+		if err != nil {
+			if errors.Is(err, errInvalidGames) {
+				utils.BadRequestResponse(c, err.Error())
+			} else {
+				utils.InternalErrorResponse(c, "Failed to save match report")
+			}
+			return
+		}
 
-	// Проверяем, завершился ли турнир
-	var isFinished bool
-	var finalWinnerID sql.NullInt64
-	err = tx.QueryRow(`
-		SELECT COUNT(*) = 0, 
-		       (SELECT winner_id FROM matches 
-		        WHERE tournament_id = $1 AND round = (
-		        	SELECT MAX(round) FROM matches WHERE tournament_id = $1
-		        ) AND status = 'finished' LIMIT 1)
-		FROM matches
-		WHERE tournament_id = $1 AND status = 'pending'
-	`, tournamentID).Scan(&isFinished, &finalWinnerID)
+		if report.Disputed {
+			_, err = tx.Exec(`
+				UPDATE matches SET status = 'disputed', updated_at = CURRENT_TIMESTAMP
+				WHERE id = $1
+			`, matchID)
 
-	if err == nil && isFinished && finalWinnerID.Valid {
-		// Турнир завершен
-		_, err = tx.Exec(`
-			UPDATE tournaments
-			SET status = 'finished', winner_id = $1, updated_at = CURRENT_TIMESTAMP
-			WHERE id = $2
-		`, finalWinnerID.Int64, tournamentID)
+			if err != nil {
+				utils.InternalErrorResponse(c, "Failed to update match")
+				return
+			}
 
-		if err != nil {
-			utils.InternalErrorResponse(c, "Failed to finish tournament")
+			if err = tx.Commit(); err != nil {
+				utils.InternalErrorResponse(c, "Failed to commit transaction")
+				return
+			}
+
+			h.notifyMatchDisputed(match, roomID, hostID)
+
+			utils.SuccessResponse(c, gin.H{
+				"message":    "Reports do not match, the match is disputed",
+				"status":     models.MatchStatusDisputed,
+				"is_decided": false,
+			})
 			return
 		}
 
-		// Обновляем статус комнаты
-		_, err = tx.Exec(`
-			UPDATE rooms SET status = 'finished', updated_at = CURRENT_TIMESTAMP
-			WHERE id = $1
-		`, roomID)
+		if len(report.Agreed) == 0 {
+			if err = tx.Commit(); err != nil {
+				utils.InternalErrorResponse(c, "Failed to commit transaction")
+				return
+			}
 
-		if err != nil {
-			utils.InternalErrorResponse(c, "Failed to update room status")
+			wsMsg := models.WSMessage{
+				Type: "match_report_submitted",
+				Data: gin.H{
+					"room_id":       roomID,
+					"tournament_id": tournamentID,
+					"match_id":      matchID,
+					"reporter_id":   userID,
+				},
+			}
+			msgBytes, _ := json.Marshal(wsMsg)
+			h.Hub.BroadcastToRoom(roomID, msgBytes)
+
+			utils.SuccessResponse(c, gin.H{
+				"message":    "Report saved, waiting for opponent confirmation",
+				"status":     match.Status,
+				"is_decided": false,
+			})
 			return
 		}
+
+		confirmed = report.Agreed
 	}
 
-	// Коммитим транзакцию
-	if err = tx.Commit(); err != nil {
-		utils.InternalErrorResponse(c, "Failed to commit transaction")
+	// Сохраняем игры и определяем победителя серии
+	winnerID, played, err := h.recordMatchGames(tx, match, confirmed, userID)
+	if err != nil {
+		if errors.Is(err, errInvalidGames) {
+			utils.BadRequestResponse(c, err.Error())
+		} else {
+			utils.InternalErrorResponse(c, "Failed to save game results")
+		}
 		return
 	}
 
-	// Отправляем уведомления через WebSocket
-	wsMsg := models.WSMessage{
-		Type: "match_result",
-		Data: gin.H{
-			"room_id":       roomID,
-			"tournament_id": tournamentID,
-			"match_id":      matchID,
-			"winner_id":     req.WinnerID,
-			"loser_id":      loserID,
-		},
+	if req.WinnerID != 0 && winnerID != 0 && req.WinnerID != winnerID {
+		utils.BadRequestResponse(c, "winner_id does not match game results")
+		return
 	}
-	msgBytes, _ := json.Marshal(wsMsg)
-	h.Hub.BroadcastToRoom(roomID, msgBytes)
 
-	// Если турнир завершен, отправляем уведомление
-	if isFinished && finalWinnerID.Valid {
-		finishMsg := models.WSMessage{
-			Type: "tournament_finished",
+	// Серия еще не решена
+	if winnerID == 0 {
+		_, err = tx.Exec(`
+			UPDATE matches SET status = 'in_progress', updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+		`, matchID)
+
+		if err != nil {
+			utils.InternalErrorResponse(c, "Failed to update match")
+			return
+		}
+
+		if err = tx.Commit(); err != nil {
+			utils.InternalErrorResponse(c, "Failed to commit transaction")
+			return
+		}
+
+		score := seriesScore(match, played)
+		wsMsg := models.WSMessage{
+			Type: "match_game_result",
 			Data: gin.H{
 				"room_id":       roomID,
 				"tournament_id": tournamentID,
-				"winner_id":     finalWinnerID.Int64,
+				"match_id":      matchID,
+				"games_played":  len(played),
+				"score":         score,
 			},
 		}
-		finishMsgBytes, _ := json.Marshal(finishMsg)
-		h.Hub.BroadcastToRoom(roomID, finishMsgBytes)
+		msgBytes, _ := json.Marshal(wsMsg)
+		h.Hub.BroadcastToRoom(roomID, msgBytes)
+
+		utils.SuccessResponse(c, gin.H{
+			"message":      "Game results recorded",
+			"games_played": len(played),
+			"score":        score,
+			"is_decided":   false,
+		})
+		return
+	}
+
+	outcome, err := h.finishMatch(tx, match, roomID, winnerID)
+	if err != nil {
+		h.Logger.Error("Failed to finish match", "match_id", matchID, "error", err)
+		utils.InternalErrorResponse(c, "Failed to save match result")
+		return
+	}
+
+	// Коммитим транзакцию
+	if err = tx.Commit(); err != nil {
+		utils.InternalErrorResponse(c, "Failed to commit transaction")
+		return
 	}
 
+	h.notifyMatchFinished(match, roomID, outcome, played)
+
 	utils.SuccessResponse(c, gin.H{
 		"message":     "Match result submitted successfully",
-		"winner_id":   req.WinnerID,
-		"is_finished": isFinished,
+		"winner_id":   winnerID,
+		"score":       seriesScore(match, played),
+		"is_decided":  true,
+		"is_finished": outcome.TournamentFinished,
+	})
+}
+
+// ResolveMatchDispute разрешение спора о результате матча (хост комнаты или администратор)
+func (h *TournamentHandlers) ResolveMatchDispute(c *gin.Context) {
+	tournamentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid tournament ID")
+		return
+	}
+
+	matchID, err := strconv.Atoi(c.Param("match_id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid match ID")
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	var req ResolveMatchDisputeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	match, roomID, hostID, err := h.getMatchForResult(tournamentID, matchID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "Match not found")
+		} else {
+			utils.InternalErrorResponse(c, "Database error")
+		}
+		return
+	}
+
+	if hostID != userID && !h.isAdmin(userID) {
+		utils.ForbiddenResponse(c, "Only room host or administrator can resolve disputes")
+		return
+	}
+
+	if match.Status != models.MatchStatusDisputed {
+		utils.BadRequestResponse(c, "Match is not disputed")
+		return
+	}
+
+	if len(req.Games) == 0 && req.WinnerID == 0 {
+		utils.BadRequestResponse(c, "Either winner_id or games must be provided")
+		return
+	}
+
+	if len(req.Games) == 0 && !match.IsParticipant(req.WinnerID) {
+		utils.BadRequestResponse(c, "Winner must be one of the match participants")
+		return
+	}
+
+	if err := h.validateGameReports(match, req.Games); err != nil {
+		var errs validator.ValidationErrors
+		if errors.As(err, &errs) {
+			utils.BadRequestResponse(c, errs.Error(), validationDetails(errs)...)
+		} else {
+			utils.BadRequestResponse(c, err.Error())
+		}
+		return
+	}
+
+	tx, err := h.DB.Beginx()
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback()
+
+	// Спор мог быть разрешен другим судьей, пока запрос ожидал блокировки
+	if err = lockMatchStatus(tx, &match); err != nil {
+		utils.InternalErrorResponse(c, "Database error")
+		return
+	}
+	if match.Status != models.MatchStatusDisputed {
+		utils.BadRequestResponse(c, "Match is not disputed")
+		return
+	}
+
+	// Переданные игры полностью заменяют ранее подтвержденные
+	if len(req.Games) > 0 {
+		if _, err = tx.Exec(`DELETE FROM match_games WHERE match_id = $1`, matchID); err != nil {
+			utils.InternalErrorResponse(c, "Failed to reset game results")
+			return
+		}
+	}
+
+	winnerID, played, err := h.recordMatchGames(tx, match, req.Games, userID)
+	if err != nil {
+		if errors.Is(err, errInvalidGames) {
+			utils.BadRequestResponse(c, err.Error())
+		} else {
+			utils.InternalErrorResponse(c, "Failed to save game results")
+		}
+		return
+	}
+
+	if len(req.Games) > 0 {
+		if winnerID == 0 {
+			utils.BadRequestResponse(c, "Games must decide the series")
+			return
+		}
+		if req.WinnerID != 0 && req.WinnerID != winnerID {
+			utils.BadRequestResponse(c, "winner_id does not match game results")
+			return
+		}
+	} else {
+		// Победитель назначается решением без записи отдельных игр
+		winnerID = req.WinnerID
+	}
+
+	_, err = tx.Exec(`
+		UPDATE matches
+		SET resolved_by = $1, resolution_reason = $2, resolved_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`, userID, req.Reason, matchID)
+
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to save resolution")
+		return
+	}
+
+	outcome, err := h.finishMatch(tx, match, roomID, winnerID)
+	if err != nil {
+		h.Logger.Error("Failed to finish disputed match", "match_id", matchID, "error", err)
+		utils.InternalErrorResponse(c, "Failed to save match result")
+		return
+	}
+
+	if err = tx.Commit(); err != nil {
+		utils.InternalErrorResponse(c, "Failed to commit transaction")
+		return
+	}
+
+	h.Logger.Info("Match dispute resolved",
+		"match_id", matchID,
+		"resolved_by", userID,
+		"winner_id", winnerID,
+	)
+
+	h.notifyMatchFinished(match, roomID, outcome, played)
+
+	utils.SuccessResponse(c, gin.H{
+		"message":           "Dispute resolved successfully",
+		"winner_id":         winnerID,
+		"score":             seriesScore(match, played),
+		"resolved_by":       userID,
+		"resolution_reason": req.Reason,
+		"is_finished":       outcome.TournamentFinished,
+	})
+}
+
+// RevertMatchResult отмена результата завершенного матча (хост комнаты или администратор).
+// Изменения рейтинга откатываются по сохраненным значениям, игроки убираются из
+// следующих матчей. Если следующие матчи уже сыграны, отмена отклоняется, пока
+// явно не запрошен каскад.
+func (h *TournamentHandlers) RevertMatchResult(c *gin.Context) {
+	tournamentID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid tournament ID")
+		return
+	}
+
+	matchID, err := strconv.Atoi(c.Param("match_id"))
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid match ID")
+		return
+	}
+
+	userID := c.GetInt("user_id")
+
+	var req RevertMatchResultRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	match, roomID, hostID, err := h.getMatchForResult(tournamentID, matchID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			utils.NotFoundResponse(c, "Match not found")
+		} else {
+			utils.InternalErrorResponse(c, "Database error")
+		}
+		return
+	}
+
+	if hostID != userID && !h.isAdmin(userID) {
+		utils.ForbiddenResponse(c, "Only room host or administrator can revert match results")
+		return
+	}
+
+	if match.Status != models.MatchStatusFinished {
+		utils.BadRequestResponse(c, "Only finished matches can be reverted")
+		return
+	}
+
+	tx, err := h.DB.Beginx()
+	if err != nil {
+		utils.InternalErrorResponse(c, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback()
+
+	var tournamentStatus string
+	err = tx.Get(&tournamentStatus, `SELECT status FROM tournaments WHERE id = $1 FOR UPDATE`, tournamentID)
+	if err != nil {
+		utils.InternalErrorResponse(c, "Database error")
+		return
+	}
+
+	if tournamentStatus != models.TournamentStatusStarted && tournamentStatus != models.TournamentStatusFinished {
+		utils.BadRequestResponse(c, "Tournament is not active")
+		return
+	}
+
+	var reverted []int
+	err = h.revertMatch(tx, matchID, req.Cascade, userID, req.Reason, &reverted)
+	if err != nil {
+		switch {
+		case errors.Is(err, errMatchNotRevertable):
+			utils.BadRequestResponse(c, err.Error())
+		case errors.Is(err, errDownstreamPlayed), errors.Is(err, errNextStageGenerated), errors.Is(err, errRatingDeltasMissing):
+			utils.ConflictResponse(c, err.Error())
+		default:
+			h.Logger.Error("Failed to revert match result", "match_id", matchID, "error", err)
+			utils.InternalErrorResponse(c, "Failed to revert match result")
+		}
+		return
+	}
+
+	// Отмененным матчам назначается новый срок
+	if err = h.setMatchDeadlines(tx, tournamentID); err != nil {
+		utils.InternalErrorResponse(c, "Failed to set match deadlines")
+		return
+	}
+
+	if err = h.syncMatchTeams(tx, tournamentID); err != nil {
+		utils.InternalErrorResponse(c, "Failed to update match teams")
+		return
+	}
+
+	// Завершенный турнир снова становится активным
+	reopened := tournamentStatus == models.TournamentStatusFinished
+	if reopened {
+		_, err = tx.Exec(`
+			UPDATE tournaments
+			SET status = 'started', winner_id = NULL, winner_team_id = NULL, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+		`, tournamentID)
+
+		if err != nil {
+			utils.InternalErrorResponse(c, "Failed to reopen tournament")
+			return
+		}
+
+		if _, err = tx.Exec(`DELETE FROM tournament_placements WHERE tournament_id = $1`, tournamentID); err != nil {
+			utils.InternalErrorResponse(c, "Failed to reset tournament placements")
+			return
+		}
+
+		_, err = tx.Exec(`
+			UPDATE rooms SET status = 'in_progress', updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+		`, roomID)
+
+		if err != nil {
+			utils.InternalErrorResponse(c, "Failed to update room status")
+			return
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		utils.InternalErrorResponse(c, "Failed to commit transaction")
+		return
+	}
+
+	h.Logger.Info("Match result reverted",
+		"match_id", matchID,
+		"reverted_by", userID,
+		"reverted_matches", reverted,
+	)
+
+	wsMsg := models.WSMessage{
+		Type: "match_result_reverted",
+		Data: gin.H{
+			"room_id":             roomID,
+			"tournament_id":       tournamentID,
+			"match_id":            matchID,
+			"reverted_matches":    reverted,
+			"tournament_reopened": reopened,
+		},
+	}
+	msgBytes, _ := json.Marshal(wsMsg)
+	h.Hub.BroadcastToRoom(roomID, msgBytes)
+
+	utils.SuccessResponse(c, gin.H{
+		"message":             "Match result reverted successfully",
+		"reverted_matches":    reverted,
+		"tournament_reopened": reopened,
 	})
 }
 
@@ -650,8 +1414,16 @@ func (h *TournamentHandlers) GetMatch(c *gin.Context) {
 
 	var match models.Match
 	err = h.DB.Get(&match, `
-		SELECT m.id, m.tournament_id, m.round, m.player1_id, m.player2_id, 
-		       m.winner_id, m.status, m.created_at, m.updated_at
+		SELECT m.id, m.tournament_id, m.round,
+		       COALESCE(m.player1_id, 0) as player1_id, COALESCE(m.player2_id, 0) as player2_id,
+		       m.winner_id, m.status, m.bracket, m.position, COALESCE(m.group_number, 0) as group_number,
+		       m.next_match_id, m.next_slot, m.loser_match_id, m.loser_slot, m.best_of,
+		       m.resolved_by, m.resolution_reason, m.resolved_at, m.finished_at,
+		       m.winner_rating_delta, m.loser_rating_delta, m.reverted_by, m.revert_reason, m.reverted_at,
+		       m.deadline, m.player1_ready_at, m.player2_ready_at, m.is_walkover,
+		       m.draft_status, m.draft_turn, m.draft_turn_deadline,
+		       m.team1_id, m.team2_id, m.winner_team_id,
+		       m.created_at, m.updated_at
 		FROM matches m
 		WHERE m.id = $1
 	`, matchID)
@@ -699,6 +1471,19 @@ func (h *TournamentHandlers) GetMatch(c *gin.Context) {
 		}
 	}
 
+	// Результаты игр серии
+	var games []models.MatchGame
+	err = h.DB.Select(&games, `
+		SELECT id, match_id, game_number, winner_id, player1_score, player2_score, clear_time_ms,
+		       player1_heroes, player2_heroes, reported_by, created_at, updated_at
+		FROM match_games
+		WHERE match_id = $1
+		ORDER BY game_number
+	`, matchID)
+	if err == nil {
+		match.Games = games
+	}
+
 	utils.SuccessResponse(c, match)
 }
 
@@ -766,7 +1551,7 @@ func (h *TournamentHandlers) CancelTournament(c *gin.Context) {
 	_, err = tx.Exec(`
 		UPDATE matches
 		SET status = 'cancelled', updated_at = CURRENT_TIMESTAMP
-		WHERE tournament_id = $1 AND status = 'pending'
+		WHERE tournament_id = $1 AND status IN ('pending', 'in_progress', 'disputed')
 	`, tournamentID)
 
 	if err != nil {
@@ -915,111 +1700,1404 @@ func (h *TournamentHandlers) GetTournamentStats(c *gin.Context) {
 
 // Вспомогательные функции
 
-// updatePlayerRatings обновляет рейтинги игроков после матча
-func (h *TournamentHandlers) updatePlayerRatings(tx *sqlx.Tx, winnerID, loserID int) error {
-	// Получаем текущие рейтинги и количество игр
-	var winnerRating, loserRating, winnerGames, loserGames int
-
-	err := tx.QueryRow(`
-		SELECT rating, wins + losses FROM users WHERE id = $1
-	`, winnerID).Scan(&winnerRating, &winnerGames)
+// updatePlayerRatings обновляет рейтинги участников после матча и возвращает
+// фактически примененные изменения рейтинга победителя и проигравшего.
+// Рейтинг игроков меняется в ладдере турнира, рейтинг команд - в таблице teams.
+// Изменения рейтинга игроков записываются в историю рейтинга, состояние перед
+// матчем сохраняется в матче для отмены результата.
+// Техническая победа засчитывается в статистику без изменения рейтинга.
+func (h *TournamentHandlers) updatePlayerRatings(tx *sqlx.Tx, target ratingTarget, matchID int, walkover bool) (int, int, error) {
+	if walkover {
+		if err := saveRatingResult(tx, target, target.WinnerID, true, nil); err != nil {
+			return 0, 0, err
+		}
+		return 0, 0, saveRatingResult(tx, target, target.LoserID, false, nil)
+	}
 
+	// Получаем текущие рейтинговые состояния
+	winner, err := loadRatingState(tx, target, target.WinnerID)
 	if err != nil {
-		return err
+		return 0, 0, err
 	}
 
-	err = tx.QueryRow(`
-		SELECT rating, wins + losses FROM users WHERE id = $1
-	`, loserID).Scan(&loserRating, &loserGames)
-
+	loser, err := loadRatingState(tx, target, target.LoserID)
 	if err != nil {
-		return err
+		return 0, 0, err
 	}
 
-	// Рассчитываем новые рейтинги
-	winnerKFactor := rating.GetKFactor(winnerRating, winnerGames)
-	loserKFactor := rating.GetKFactor(loserRating, loserGames)
-
-	newWinnerRating, newLoserRating := rating.CalculateRatingChange(
-		winnerRating, loserRating, winnerKFactor)
+	// Состояние перед матчем восстанавливается при отмене результата
+	if err = saveRatingSnapshot(tx, target, matchID, target.WinnerID, "winner"); err != nil {
+		return 0, 0, err
+	}
 
-	// Применяем модификатор для турнирных матчей
-	_, newLoserRating = rating.CalculateRatingChange(
-		winnerRating, loserRating, loserKFactor)
+	if err = saveRatingSnapshot(tx, target, matchID, target.LoserID, "loser"); err != nil {
+		return 0, 0, err
+	}
 
-	// Обновляем статистику победителя
-	_, err = tx.Exec(`
-		UPDATE users 
-		SET wins = wins + 1, rating = $1, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $2
-	`, newWinnerRating, winnerID)
+	// Рассчитываем новые рейтинги выбранной рейтинговой системой
+	newWinner, newLoser := h.Rating.Rate(winner, loser)
 
-	if err != nil {
-		return err
+	if err = saveRatingResult(tx, target, target.WinnerID, true, &newWinner); err != nil {
+		return 0, 0, err
 	}
 
-	// Обновляем статистику проигравшего
-	_, err = tx.Exec(`
-		UPDATE users 
-		SET losses = losses + 1, rating = $1, updated_at = CURRENT_TIMESTAMP
-		WHERE id = $2
-	`, newLoserRating, loserID)
-
-	return err
-}
+	if err = saveRatingResult(tx, target, target.LoserID, false, &newLoser); err != nil {
+		return 0, 0, err
+	}
+
+	if target.Table == "users" {
+		err = recordRatingChange(tx, ratingChange(target.Ladder, target.WinnerID, target.LoserID, matchID, true, winner, newWinner))
+		if err == nil {
+			err = recordRatingChange(tx, ratingChange(target.Ladder, target.LoserID, target.WinnerID, matchID, false, loser, newLoser))
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return newWinner.Rating - winner.Rating, newLoser.Rating - loser.Rating, nil
+}
 
 // advanceTournament продвигает турнир после завершения матча
-func (h *TournamentHandlers) advanceTournament(tx *sqlx.Tx, tournamentID, matchID, winnerID int) error {
-	// Получаем информацию о завершенном матче
-	var round int
-	err := tx.Get(&round, `
-		SELECT round FROM matches WHERE id = $1
+func (h *TournamentHandlers) advanceTournament(tx *sqlx.Tx, tournamentID, matchID, winnerID, loserID int) error {
+	var format, bracketName string
+	err := tx.QueryRow(`
+		SELECT t.format, m.bracket
+		FROM matches m
+		JOIN tournaments t ON m.tournament_id = t.id
+		WHERE m.id = $1
+	`, matchID).Scan(&format, &bracketName)
+
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case format == models.TournamentFormatSwiss:
+		return h.advanceSwiss(tx, tournamentID)
+	case bracketName == models.MatchBracketGroup:
+		if format == models.TournamentFormatGroupsPlayoffs {
+			return h.startPlayoffsIfReady(tx, tournamentID)
+		}
+		// В круговом турнире все матчи созданы заранее
+		return nil
+	}
+
+	return h.advanceByLinks(tx, matchID, winnerID, loserID)
+}
+
+// advanceByLinks переводит победителя и проигравшего по явным ссылкам сетки
+func (h *TournamentHandlers) advanceByLinks(tx *sqlx.Tx, matchID, winnerID, loserID int) error {
+	var match models.Match
+	err := tx.Get(&match, `
+		SELECT id, round, bracket, COALESCE(player1_id, 0) as player1_id,
+		       next_match_id, next_slot, loser_match_id, loser_slot
+		FROM matches WHERE id = $1
+	`, matchID)
+
+	if err != nil {
+		return err
+	}
+
+	// Гранд-финал: при победе игрока нижней сетки играется повторный финал
+	if match.Bracket == models.MatchBracketGrandFinal {
+		if match.Round != 1 || match.NextMatchID == nil {
+			return nil
+		}
+
+		if winnerID == match.Player1ID {
+			_, err = tx.Exec(`
+				UPDATE matches SET status = 'cancelled', updated_at = CURRENT_TIMESTAMP
+				WHERE id = $1
+			`, *match.NextMatchID)
+			return err
+		}
+
+		if err := h.placePlayer(tx, *match.NextMatchID, 0, match.Player1ID); err != nil {
+			return err
+		}
+		return h.placePlayer(tx, *match.NextMatchID, 1, winnerID)
+	}
+
+	if match.NextMatchID != nil {
+		if err := h.placePlayer(tx, *match.NextMatchID, match.NextSlot, winnerID); err != nil {
+			return err
+		}
+		if err := h.advanceBye(tx, *match.NextMatchID); err != nil {
+			return err
+		}
+	}
+
+	if match.LoserMatchID != nil {
+		if err := h.placePlayer(tx, *match.LoserMatchID, match.LoserSlot, loserID); err != nil {
+			return err
+		}
+		if err := h.advanceBye(tx, *match.LoserMatchID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// advanceBye завершает ожидающий матч, в пустой слот которого не ведет ни одна
+// ссылка сетки (bye в нижней сетке double elimination), и переводит
+// единственного игрока дальше, как tournament.Bracket.AdvanceMatch
+func (h *TournamentHandlers) advanceBye(tx *sqlx.Tx, matchID int) error {
+	var match struct {
+		Bracket     string `db:"bracket"`
+		Status      string `db:"status"`
+		Player1ID   int    `db:"player1_id"`
+		Player2ID   int    `db:"player2_id"`
+		NextMatchID *int   `db:"next_match_id"`
+		NextSlot    int    `db:"next_slot"`
+		Fed1        bool   `db:"fed1"`
+		Fed2        bool   `db:"fed2"`
+	}
+
+	err := tx.Get(&match, `
+		SELECT m.bracket, m.status, COALESCE(m.player1_id, 0) as player1_id, COALESCE(m.player2_id, 0) as player2_id,
+		       m.next_match_id, m.next_slot,
+		       EXISTS (SELECT 1 FROM matches f WHERE (f.next_match_id = m.id AND f.next_slot = 0)
+		                                          OR (f.loser_match_id = m.id AND f.loser_slot = 0)) as fed1,
+		       EXISTS (SELECT 1 FROM matches f WHERE (f.next_match_id = m.id AND f.next_slot = 1)
+		                                          OR (f.loser_match_id = m.id AND f.loser_slot = 1)) as fed2
+		FROM matches m WHERE m.id = $1
+		FOR UPDATE OF m
 	`, matchID)
+	if err != nil {
+		return err
+	}
+
+	if match.Status != models.MatchStatusPending || match.Bracket == models.MatchBracketGrandFinal {
+		return nil
+	}
+
+	playerID := 0
+	switch {
+	case match.Player1ID > 0 && match.Player2ID == 0 && !match.Fed2:
+		playerID = match.Player1ID
+	case match.Player2ID > 0 && match.Player1ID == 0 && !match.Fed1:
+		playerID = match.Player2ID
+	default:
+		return nil
+	}
+
+	_, err = tx.Exec(`
+		UPDATE matches SET winner_id = $1, status = 'finished', updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`, playerID, matchID)
+	if err != nil {
+		return err
+	}
+
+	if match.NextMatchID == nil {
+		return nil
+	}
+
+	if err = h.placePlayer(tx, *match.NextMatchID, match.NextSlot, playerID); err != nil {
+		return err
+	}
+	return h.advanceBye(tx, *match.NextMatchID)
+}
+
+// placePlayer ставит игрока в слот матча
+func (h *TournamentHandlers) placePlayer(tx *sqlx.Tx, matchID, slot, playerID int) error {
+	query := `UPDATE matches SET player1_id = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+	if slot == 1 {
+		query = `UPDATE matches SET player2_id = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`
+	}
+
+	_, err := tx.Exec(query, playerID, matchID)
+	return err
+}
+
+// startPlayoffsIfReady формирует плей-офф, когда сыграны все матчи групп
+func (h *TournamentHandlers) startPlayoffsIfReady(tx *sqlx.Tx, tournamentID int) error {
+	var pending int
+	err := tx.Get(&pending, `
+		SELECT COUNT(*) FROM matches
+		WHERE tournament_id = $1 AND bracket = 'group' AND status IN ('pending', 'in_progress', 'disputed')
+	`, tournamentID)
+
+	if err != nil || pending > 0 {
+		return err
+	}
+
+	var bracketJSON []byte
+	err = tx.Get(&bracketJSON, `SELECT bracket FROM tournaments WHERE id = $1 FOR UPDATE`, tournamentID)
+	if err != nil {
+		return err
+	}
+
+	var bracket tournament.Bracket
+	if err := json.Unmarshal(bracketJSON, &bracket); err != nil {
+		return err
+	}
+
+	if bracket.HasPlayoffs() {
+		return nil
+	}
+
+	groupMatches, err := h.loadBracketMatches(tx, tournamentID, tournament.BracketGroup)
+	if err != nil {
+		return err
+	}
+
+	playoff, err := bracket.AppendPlayoffs(bracket.GroupStandings(groupMatches))
+	if err != nil {
+		return err
+	}
+
+	if err := h.insertBracketMatches(tx, tournamentID, playoff, len(bracket.Matches)-len(playoff)); err != nil {
+		return err
+	}
+
+	bracketJSON, err = json.Marshal(bracket)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE tournaments SET bracket = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2
+	`, bracketJSON, tournamentID)
+
+	return err
+}
+
+// advanceSwiss создает следующий тур швейцарской системы, когда сыграны все
+// матчи текущего
+func (h *TournamentHandlers) advanceSwiss(tx *sqlx.Tx, tournamentID int) error {
+	var bracketJSON []byte
+	err := tx.Get(&bracketJSON, `SELECT bracket FROM tournaments WHERE id = $1 FOR UPDATE`, tournamentID)
+	if err != nil {
+		return err
+	}
+
+	var pending int
+	err = tx.Get(&pending, `
+		SELECT COUNT(*) FROM matches
+		WHERE tournament_id = $1 AND bracket = 'swiss' AND status IN ('pending', 'in_progress', 'disputed')
+	`, tournamentID)
+
+	if err != nil || pending > 0 {
+		return err
+	}
+
+	var bracket tournament.Bracket
+	if err := json.Unmarshal(bracketJSON, &bracket); err != nil {
+		return err
+	}
+
+	matches, err := h.loadBracketMatches(tx, tournamentID, tournament.BracketSwiss)
+	if err != nil {
+		return err
+	}
+
+	round := tournament.CurrentSwissRound(matches)
+	if round >= bracket.Rounds {
+		return nil
+	}
+
+	next := tournament.PairSwissRound(bracket.Players, matches, round+1)
+	bracket.Series.Apply(next)
+	if err := h.insertBracketMatches(tx, tournamentID, next, len(bracket.Matches)); err != nil {
+		return err
+	}
 
+	bracket.Matches = append(bracket.Matches, next...)
+	bracketJSON, err = json.Marshal(bracket)
 	if err != nil {
 		return err
 	}
 
-	// Ищем следующий матч в следующем раунде где должен играть победитель
-	var nextMatchID sql.NullInt64
+	_, err = tx.Exec(`
+		UPDATE tournaments SET bracket = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2
+	`, bracketJSON, tournamentID)
+
+	return err
+}
+
+// tournamentOutcome проверяет, завершен ли турнир, и возвращает победителя
+func (h *TournamentHandlers) tournamentOutcome(tx *sqlx.Tx, tournamentID int) (bool, sql.NullInt64, error) {
+	var format string
+	var pending int
+	var bracketJSON []byte
+	err := tx.QueryRow(`
+		SELECT t.format, t.bracket,
+		       (SELECT COUNT(*) FROM matches WHERE tournament_id = t.id AND status IN ('pending', 'in_progress', 'disputed'))
+		FROM tournaments t
+		WHERE t.id = $1
+	`, tournamentID).Scan(&format, &bracketJSON, &pending)
+
+	if err != nil || pending > 0 {
+		return false, sql.NullInt64{}, err
+	}
+
+	// Швейцарскую систему выигрывает лидер таблицы после последнего тура
+	if format == models.TournamentFormatSwiss {
+		standings, rounds, err := h.calculateSwissStandings(tx, tournamentID, bracketJSON)
+		if err != nil || len(standings) == 0 || rounds > 0 {
+			return false, sql.NullInt64{}, err
+		}
+		winnerID := int64(standings[0].Player.ID)
+		return true, sql.NullInt64{Int64: winnerID, Valid: true}, nil
+	}
+
+	// Круговой турнир выигрывает лидер таблицы
+	if format == models.TournamentFormatRoundRobin {
+		tables, err := h.calculateGroupTables(tx, tournamentID, bracketJSON)
+		if err != nil || len(tables) == 0 || len(tables[0].Standings) == 0 {
+			return false, sql.NullInt64{}, err
+		}
+		winnerID := int64(tables[0].Standings[0].Player.ID)
+		return true, sql.NullInt64{Int64: winnerID, Valid: true}, nil
+	}
+
+	var finalWinnerID sql.NullInt64
 	err = tx.QueryRow(`
-		SELECT id FROM matches 
-		WHERE tournament_id = $1 AND round = $2 AND status = 'pending'
-		AND (player1_id = 0 OR player2_id = 0)
-		ORDER BY id ASC
+		SELECT winner_id FROM matches
+		WHERE tournament_id = $1 AND status = 'finished' AND bracket NOT IN ('group', 'third_place')
+		ORDER BY bracket = 'grand_final' DESC, round DESC, id DESC
 		LIMIT 1
-	`, tournamentID, round+1).Scan(&nextMatchID)
+	`, tournamentID).Scan(&finalWinnerID)
+
+	if err == sql.ErrNoRows {
+		return false, finalWinnerID, nil
+	}
+
+	return err == nil, finalWinnerID, err
+}
+
+// getGroupTables возвращает таблицы групп турнира
+func (h *TournamentHandlers) getGroupTables(tournamentID int, bracketJSON []byte) ([]GroupTable, error) {
+	return h.calculateGroupTables(h.DB, tournamentID, bracketJSON)
+}
+
+// calculateGroupTables считает таблицы групп по составам из сетки и сыгранным матчам
+func (h *TournamentHandlers) calculateGroupTables(q sqlx.Queryer, tournamentID int, bracketJSON []byte) ([]GroupTable, error) {
+	var bracket tournament.Bracket
+	if err := json.Unmarshal(bracketJSON, &bracket); err != nil {
+		return nil, err
+	}
+
+	groupMatches, err := h.loadBracketMatches(q, tournamentID, tournament.BracketGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	standings := bracket.GroupStandings(groupMatches)
+	tables := make([]GroupTable, len(standings))
+	for i, table := range standings {
+		tables[i] = GroupTable{
+			Group:     i + 1,
+			Standings: table,
+		}
+	}
+
+	return tables, nil
+}
+
+// calculateSwissStandings считает таблицу швейцарской системы и возвращает
+// количество еще не созданных туров
+func (h *TournamentHandlers) calculateSwissStandings(q sqlx.Queryer, tournamentID int, bracketJSON []byte) ([]tournament.Standing, int, error) {
+	var bracket tournament.Bracket
+	if err := json.Unmarshal(bracketJSON, &bracket); err != nil {
+		return nil, 0, err
+	}
+
+	matches, err := h.loadBracketMatches(q, tournamentID, tournament.BracketSwiss)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	remaining := bracket.Rounds - tournament.CurrentSwissRound(matches)
+	return tournament.CalculateSwissStandings(bracket.Players, matches), remaining, nil
+}
 
-	if err != nil && err != sql.ErrNoRows {
+// savePlacements рассчитывает и сохраняет итоговые места турнира
+func (h *TournamentHandlers) savePlacements(tx *sqlx.Tx, tournamentID, winnerID int) error {
+	var bracketJSON []byte
+	err := tx.Get(&bracketJSON, `SELECT bracket FROM tournaments WHERE id = $1`, tournamentID)
+	if err != nil {
+		return err
+	}
+
+	var bracket tournament.Bracket
+	if err := json.Unmarshal(bracketJSON, &bracket); err != nil {
+		return err
+	}
+
+	matches, err := h.loadTournamentMatches(tx, tournamentID)
+	if err != nil {
 		return err
 	}
 
-	// Если есть следующий матч, добавляем победителя
-	if nextMatchID.Valid {
-		// Проверяем, какое место свободно
-		var player1ID, player2ID int
-		err = tx.QueryRow(`
-			SELECT player1_id, player2_id FROM matches WHERE id = $1
-		`, nextMatchID.Int64).Scan(&player1ID, &player2ID)
+	_, err = tx.Exec(`DELETE FROM tournament_placements WHERE tournament_id = $1`, tournamentID)
+	if err != nil {
+		return err
+	}
+
+	for _, placement := range tournament.CalculatePlacements(&bracket, matches, winnerID) {
+		_, err = tx.Exec(`
+			INSERT INTO tournament_placements (tournament_id, user_id, place, place_to, team_id)
+			VALUES ($1, $2, $3, $4,
+			        (SELECT team_id FROM tournament_teams WHERE tournament_id = $1 AND captain_id = $2))
+		`, tournamentID, placement.PlayerID, placement.Place, placement.PlaceTo)
 
 		if err != nil {
 			return err
 		}
+	}
 
-		if player1ID == 0 {
-			_, err = tx.Exec(`
-				UPDATE matches SET player1_id = $1 WHERE id = $2
-			`, winnerID, nextMatchID.Int64)
-		} else if player2ID == 0 {
-			_, err = tx.Exec(`
-				UPDATE matches SET player2_id = $1 WHERE id = $2
-			`, winnerID, nextMatchID.Int64)
+	return nil
+}
+
+// loadTournamentMatches загружает все матчи турнира в формате пакета tournament.
+// Ссылки между матчами переводятся в индексы возвращаемого списка.
+func (h *TournamentHandlers) loadTournamentMatches(q sqlx.Queryer, tournamentID int) ([]tournament.Match, error) {
+	var rows []models.Match
+	err := sqlx.Select(q, &rows, `
+		SELECT id, round, COALESCE(player1_id, 0) as player1_id, COALESCE(player2_id, 0) as player2_id,
+		       winner_id, status, bracket, position, COALESCE(group_number, 0) as group_number,
+		       next_match_id, next_slot, loser_match_id, loser_slot, best_of
+		FROM matches
+		WHERE tournament_id = $1
+		ORDER BY id
+	`, tournamentID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	indexByID := make(map[int]int, len(rows))
+	for i, row := range rows {
+		indexByID[row.ID] = i
+	}
+
+	link := func(id *int) *int {
+		if id == nil {
+			return nil
 		}
+		index, ok := indexByID[*id]
+		if !ok {
+			return nil
+		}
+		return &index
+	}
+
+	matches := make([]tournament.Match, len(rows))
+	for i, row := range rows {
+		matches[i] = tournament.Match{
+			ID:           row.ID,
+			TournamentID: tournamentID,
+			Round:        row.Round,
+			Player1ID:    row.Player1ID,
+			Player2ID:    row.Player2ID,
+			WinnerID:     row.WinnerID,
+			Status:       row.Status,
+			Bracket:      row.Bracket,
+			Position:     row.Position,
+			Group:        row.GroupNumber,
+			NextMatch:    link(row.NextMatchID),
+			NextSlot:     row.NextSlot,
+			LoserMatch:   link(row.LoserMatchID),
+			LoserSlot:    row.LoserSlot,
+			BestOf:       row.BestOf,
+		}
+	}
 
-		if err != nil {
-			return err
+	return matches, nil
+}
+
+// loadBracketMatches загружает матчи указанной сетки в формате пакета tournament
+func (h *TournamentHandlers) loadBracketMatches(q sqlx.Queryer, tournamentID int, bracketName string) ([]tournament.Match, error) {
+	var rows []models.Match
+	err := sqlx.Select(q, &rows, `
+		SELECT id, round, COALESCE(player1_id, 0) as player1_id, COALESCE(player2_id, 0) as player2_id,
+		       winner_id, status, bracket, position, COALESCE(group_number, 0) as group_number
+		FROM matches
+		WHERE tournament_id = $1 AND bracket = $2
+		ORDER BY group_number, round, position
+	`, tournamentID, bracketName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]tournament.Match, len(rows))
+	for i, row := range rows {
+		matches[i] = tournament.Match{
+			ID:           row.ID,
+			TournamentID: tournamentID,
+			Round:        row.Round,
+			Player1ID:    row.Player1ID,
+			Player2ID:    row.Player2ID,
+			WinnerID:     row.WinnerID,
+			Status:       row.Status,
+			Bracket:      row.Bracket,
+			Position:     row.Position,
+			Group:        row.GroupNumber,
 		}
 	}
 
-	return nil
+	return matches, nil
+}
+
+// getMatchForResult загружает матч турнира вместе с комнатой и ее хостом
+func (h *TournamentHandlers) getMatchForResult(tournamentID, matchID int) (models.Match, int, int, error) {
+	var match models.Match
+	var roomID, hostID int
+
+	err := h.DB.QueryRow(`
+		SELECT m.id, m.tournament_id, m.round, COALESCE(m.player1_id, 0), COALESCE(m.player2_id, 0),
+		       m.status, m.bracket, m.best_of, m.draft_status, m.team1_id, m.team2_id, t.room_id, r.host_id
+		FROM matches m
+		JOIN tournaments t ON m.tournament_id = t.id
+		JOIN rooms r ON t.room_id = r.id
+		WHERE m.id = $1 AND m.tournament_id = $2
+	`, matchID, tournamentID).Scan(&match.ID, &match.TournamentID, &match.Round,
+		&match.Player1ID, &match.Player2ID, &match.Status, &match.Bracket, &match.BestOf, &match.DraftStatus,
+		&match.Team1ID, &match.Team2ID, &roomID, &hostID)
+
+	return match, roomID, hostID, err
+}
+
+// lockMatchStatus блокирует матч до конца транзакции и обновляет его статус и
+// статус драфта актуальными значениями
+func lockMatchStatus(tx *sqlx.Tx, match *models.Match) error {
+	return tx.QueryRow(`
+		SELECT status, draft_status FROM matches WHERE id = $1 FOR UPDATE
+	`, match.ID).Scan(&match.Status, &match.DraftStatus)
+}
+
+// respondIfMatchClosed отвечает ошибкой, если матч не принимает результат.
+// Возвращает true, если ответ уже отправлен.
+func respondIfMatchClosed(c *gin.Context, match models.Match) bool {
+	switch {
+	case match.Status == models.MatchStatusDisputed:
+		utils.ConflictResponse(c, "Match result is disputed and must be resolved by the room host or an administrator")
+	case match.Status != models.MatchStatusPending && match.Status != models.MatchStatusInProgress:
+		utils.BadRequestResponse(c, "Match is already completed")
+	case match.Player1ID == 0 || match.Player2ID == 0:
+		utils.BadRequestResponse(c, "Match participants are not determined yet")
+	case match.DraftStatus == models.DraftStatusInProgress:
+		utils.ConflictResponse(c, "Hero draft is not finished yet")
+	default:
+		return false
+	}
+	return true
+}
+
+// saveMatchReport сохраняет отчет участника и сравнивает его с отчетом соперника.
+// Новые игры дополняют ранее отправленный участником отчет.
+func (h *TournamentHandlers) saveMatchReport(tx *sqlx.Tx, match models.Match, reporterID int, games []GameReport, details string) (matchReportResult, error) {
+	var result matchReportResult
+
+	// Матч заблокирован вызывающим кодом, поэтому отчеты соперников сравниваются последовательно
+	var reports []models.MatchReport
+	err := tx.Select(&reports, `
+		SELECT id, match_id, reporter_id, games, details, created_at, updated_at
+		FROM match_reports WHERE match_id = $1
+	`, match.ID)
+	if err != nil {
+		return result, err
+	}
+
+	own := make(map[int]GameReport)
+	opponent := make(map[int]GameReport)
+	for _, report := range reports {
+		var reported []GameReport
+		if err := json.Unmarshal(report.Games, &reported); err != nil {
+			return result, err
+		}
+
+		target := opponent
+		if report.ReporterID == reporterID {
+			target = own
+		}
+		for _, game := range reported {
+			target[game.GameNumber] = game
+		}
+	}
+
+	for _, game := range games {
+		own[game.GameNumber] = game
+	}
+
+	merged := make([]GameReport, 0, len(own))
+	played := make([]tournament.GameResult, 0, len(own))
+	for number := 1; number <= match.BestOf; number++ {
+		if game, ok := own[number]; ok {
+			merged = append(merged, game)
+			played = append(played, tournament.GameResult{Number: number, WinnerID: game.WinnerID})
+		}
+	}
+
+	// Отчет участника сам по себе должен быть корректной серией
+	if _, err := tournament.SeriesWinner(match.BestOf, match.Player1ID, match.Player2ID, played); err != nil {
+		return result, fmt.Errorf("%w: %v", errInvalidGames, err)
+	}
+
+	gamesJSON, err := json.Marshal(merged)
+	if err != nil {
+		return result, err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO match_reports (match_id, reporter_id, games, details)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (match_id, reporter_id) DO UPDATE
+		SET games = EXCLUDED.games,
+		    details = EXCLUDED.details,
+		    updated_at = CURRENT_TIMESTAMP
+	`, match.ID, reporterID, gamesJSON, details)
+	if err != nil {
+		return result, err
+	}
+
+	// Любое расхождение в победителе одной и той же игры делает результат спорным
+	for number, game := range own {
+		if other, ok := opponent[number]; ok && other.WinnerID != game.WinnerID {
+			result.Disputed = true
+			return result, nil
+		}
+	}
+
+	// Засчитываются игры подряд с первой, подтвержденные обоими участниками
+	for _, game := range merged {
+		if _, ok := opponent[game.GameNumber]; !ok {
+			break
+		}
+		result.Agreed = append(result.Agreed, game)
+	}
+
+	return result, nil
+}
+
+// finishMatch записывает победителя матча, обновляет рейтинги, продвигает сетку
+// и завершает турнир, если это был последний матч
+func (h *TournamentHandlers) finishMatch(tx *sqlx.Tx, match models.Match, roomID, winnerID int) (matchOutcome, error) {
+	outcome := matchOutcome{WinnerID: winnerID, LoserID: match.Player1ID, Walkover: match.IsWalkover}
+	if winnerID == match.Player1ID {
+		outcome.LoserID = match.Player2ID
+	}
+
+	// Обновляем результат матча; незавершенный драфт (например, при технической
+	// победе) закрывается, чтобы фоновая задача не продолжала ходы
+	_, err := tx.Exec(`
+		UPDATE matches
+		SET winner_id = $1, status = 'finished', is_walkover = $2, finished_at = CURRENT_TIMESTAMP,
+		    draft_status = CASE WHEN draft_status = 'in_progress' THEN 'completed' ELSE draft_status END,
+		    draft_turn_deadline = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`, winnerID, match.IsWalkover, match.ID)
+	if err != nil {
+		return outcome, fmt.Errorf("update match: %w", err)
+	}
+
+	// Отчеты участников больше не нужны
+	if _, err = tx.Exec(`DELETE FROM match_reports WHERE match_id = $1`, match.ID); err != nil {
+		return outcome, fmt.Errorf("delete match reports: %w", err)
+	}
+
+	// Обновляем рейтинги игроков (в командном турнире - команд) и запоминаем
+	// изменения для возможной отмены результата
+	target, err := h.ratingSubjects(tx, match.TournamentID, winnerID, outcome.LoserID)
+	if err != nil {
+		return outcome, fmt.Errorf("rating subjects: %w", err)
+	}
+
+	winnerDelta, loserDelta, err := h.updatePlayerRatings(tx, target, match.ID, match.IsWalkover)
+	if err != nil {
+		return outcome, fmt.Errorf("update player ratings: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE matches SET winner_rating_delta = $1, loser_rating_delta = $2
+		WHERE id = $3
+	`, winnerDelta, loserDelta, match.ID)
+	if err != nil {
+		return outcome, fmt.Errorf("save rating deltas: %w", err)
+	}
+
+	// Продвигаем турнир: победитель и проигравший переходят в следующие матчи
+	if err = h.advanceTournament(tx, match.TournamentID, match.ID, winnerID, outcome.LoserID); err != nil {
+		return outcome, fmt.Errorf("advance tournament: %w", err)
+	}
+
+	// Матчам, в которых теперь известны оба игрока, назначается срок
+	if err = h.setMatchDeadlines(tx, match.TournamentID); err != nil {
+		return outcome, fmt.Errorf("set match deadlines: %w", err)
+	}
+
+	if err = h.syncMatchTeams(tx, match.TournamentID); err != nil {
+		return outcome, fmt.Errorf("sync match teams: %w", err)
+	}
+
+	// Проверяем, завершился ли турнир
+	isFinished, finalWinnerID, err := h.tournamentOutcome(tx, match.TournamentID)
+	if err != nil {
+		return outcome, fmt.Errorf("tournament outcome: %w", err)
+	}
+
+	if !isFinished || !finalWinnerID.Valid {
+		return outcome, nil
+	}
+
+	// Турнир завершен
+	_, err = tx.Exec(`
+		UPDATE tournaments
+		SET status = 'finished', winner_id = $1, updated_at = CURRENT_TIMESTAMP,
+		    winner_team_id = (SELECT team_id FROM tournament_teams WHERE tournament_id = $2 AND captain_id = $1)
+		WHERE id = $2
+	`, finalWinnerID.Int64, match.TournamentID)
+	if err != nil {
+		return outcome, fmt.Errorf("finish tournament: %w", err)
+	}
+
+	// Сохраняем итоговые места
+	if err = h.savePlacements(tx, match.TournamentID, int(finalWinnerID.Int64)); err != nil {
+		return outcome, fmt.Errorf("save placements: %w", err)
+	}
+
+	// Обновляем статус комнаты
+	_, err = tx.Exec(`
+		UPDATE rooms SET status = 'finished', updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, roomID)
+	if err != nil {
+		return outcome, fmt.Errorf("update room status: %w", err)
+	}
+
+	outcome.TournamentFinished = true
+	outcome.TournamentWinnerID = int(finalWinnerID.Int64)
+
+	return outcome, nil
+}
+
+// notifyMatchFinished отправляет в комнату результат матча и, если турнир
+// завершен, уведомление о победителе турнира
+func (h *TournamentHandlers) notifyMatchFinished(match models.Match, roomID int, outcome matchOutcome, played []tournament.GameResult) {
+	wsMsg := models.WSMessage{
+		Type: "match_result",
+		Data: gin.H{
+			"room_id":       roomID,
+			"tournament_id": match.TournamentID,
+			"match_id":      match.ID,
+			"winner_id":     outcome.WinnerID,
+			"loser_id":      outcome.LoserID,
+			"score":         seriesScore(match, played),
+			"is_walkover":   outcome.Walkover,
+		},
+	}
+	msgBytes, _ := json.Marshal(wsMsg)
+	h.Hub.BroadcastToRoom(roomID, msgBytes)
+
+	if outcome.TournamentFinished {
+		finishMsg := models.WSMessage{
+			Type: "tournament_finished",
+			Data: gin.H{
+				"room_id":       roomID,
+				"tournament_id": match.TournamentID,
+				"winner_id":     outcome.TournamentWinnerID,
+			},
+		}
+		finishMsgBytes, _ := json.Marshal(finishMsg)
+		h.Hub.BroadcastToRoom(roomID, finishMsgBytes)
+	}
+}
+
+// notifyMatchDisputed сообщает о споре комнате, а также лично хосту и администраторам,
+// которые могут быть не подключены к комнате
+func (h *TournamentHandlers) notifyMatchDisputed(match models.Match, roomID, hostID int) {
+	wsMsg := models.WSMessage{
+		Type: "match_disputed",
+		Data: gin.H{
+			"room_id":       roomID,
+			"tournament_id": match.TournamentID,
+			"match_id":      match.ID,
+			"player1_id":    match.Player1ID,
+			"player2_id":    match.Player2ID,
+		},
+	}
+	msgBytes, _ := json.Marshal(wsMsg)
+	h.Hub.BroadcastToRoom(roomID, msgBytes)
+
+	recipients := map[int]bool{hostID: true}
+	adminIDs, err := h.adminIDs()
+	if err != nil {
+		h.Logger.Error("Failed to load administrators", "error", err)
+	}
+	for _, id := range adminIDs {
+		recipients[id] = true
+	}
+
+	for id := range recipients {
+		h.Hub.SendToUser(id, msgBytes)
+	}
+}
+
+// Ошибки отмены результата матча
+var (
+	errMatchNotRevertable  = errors.New("match result cannot be reverted")
+	errDownstreamPlayed    = errors.New("downstream match has already been played, use cascade to revert it too")
+	errNextStageGenerated  = errors.New("next stage has already been generated from this result")
+	errRatingDeltasMissing = errors.New("rating changes were not recorded for this match")
+)
+
+// revertMatch отменяет результат матча: откатывает рейтинги, убирает игроков из
+// следующих матчей и возвращает матч в ожидание. При cascade сыгранные следующие
+// матчи отменяются рекурсивно. ID отмененных матчей добавляются в reverted.
+func (h *TournamentHandlers) revertMatch(tx *sqlx.Tx, matchID int, cascade bool, revertedBy int, reason string, reverted *[]int) error {
+	var match models.Match
+	var format string
+	var winnerBefore, loserBefore ratingSnapshot
+	err := tx.QueryRowx(`
+		SELECT m.id, m.tournament_id, m.round, m.bracket, m.status,
+		       COALESCE(m.player1_id, 0), COALESCE(m.player2_id, 0), m.winner_id,
+		       m.next_match_id, m.next_slot, m.loser_match_id, m.loser_slot,
+		       m.winner_rating_delta, m.loser_rating_delta, m.is_walkover, t.format,
+		       m.winner_rating_before, m.winner_deviation_before, m.winner_volatility_before, m.winner_rated_at_before,
+		       m.loser_rating_before, m.loser_deviation_before, m.loser_volatility_before, m.loser_rated_at_before
+		FROM matches m
+		JOIN tournaments t ON m.tournament_id = t.id
+		WHERE m.id = $1
+		FOR UPDATE OF m
+	`, matchID).Scan(&match.ID, &match.TournamentID, &match.Round, &match.Bracket, &match.Status,
+		&match.Player1ID, &match.Player2ID, &match.WinnerID,
+		&match.NextMatchID, &match.NextSlot, &match.LoserMatchID, &match.LoserSlot,
+		&match.WinnerRatingDelta, &match.LoserRatingDelta, &match.IsWalkover, &format,
+		&winnerBefore.Rating, &winnerBefore.Deviation, &winnerBefore.Volatility, &winnerBefore.UpdatedAt,
+		&loserBefore.Rating, &loserBefore.Deviation, &loserBefore.Volatility, &loserBefore.UpdatedAt)
+
+	if err != nil {
+		return err
+	}
+
+	if match.Status != models.MatchStatusFinished || match.WinnerID == nil {
+		return fmt.Errorf("%w: match %d is not finished", errMatchNotRevertable, matchID)
+	}
+
+	if match.Player1ID == 0 || match.Player2ID == 0 {
+		return fmt.Errorf("%w: match %d is a bye", errMatchNotRevertable, matchID)
+	}
+
+	if match.WinnerRatingDelta == nil || match.LoserRatingDelta == nil {
+		return fmt.Errorf("%w: match %d", errRatingDeltasMissing, matchID)
+	}
+
+	winnerID := *match.WinnerID
+	loserID := match.Player1ID
+	if winnerID == match.Player1ID {
+		loserID = match.Player2ID
+	}
+
+	// Следующий этап, сформированный по таблице, нельзя откатить по ссылкам
+	var generated bool
+	switch {
+	case format == models.TournamentFormatSwiss:
+		err = tx.Get(&generated, `
+			SELECT EXISTS(SELECT 1 FROM matches WHERE tournament_id = $1 AND bracket = 'swiss' AND round > $2)
+		`, match.TournamentID, match.Round)
+	case match.Bracket == models.MatchBracketGroup:
+		err = tx.Get(&generated, `
+			SELECT EXISTS(SELECT 1 FROM matches WHERE tournament_id = $1 AND bracket <> 'group')
+		`, match.TournamentID)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if generated {
+		return fmt.Errorf("%w: match %d", errNextStageGenerated, matchID)
+	}
+
+	// Убираем игроков из следующих матчей
+	switch {
+	case match.Bracket == models.MatchBracketGrandFinal:
+		if match.Round == 1 && match.NextMatchID != nil {
+			if winnerID == match.Player1ID {
+				// Повторный финал был отменен - возвращаем его в ожидание
+				_, err = tx.Exec(`
+					UPDATE matches SET status = 'pending', updated_at = CURRENT_TIMESTAMP
+					WHERE id = $1 AND status = 'cancelled'
+				`, *match.NextMatchID)
+			} else {
+				err = h.releaseDownstream(tx, *match.NextMatchID, []int{0, 1}, cascade, revertedBy, reason, reverted)
+			}
+		}
+	default:
+		if match.NextMatchID != nil {
+			err = h.releaseDownstream(tx, *match.NextMatchID, []int{match.NextSlot}, cascade, revertedBy, reason, reverted)
+		}
+		if err == nil && match.LoserMatchID != nil {
+			err = h.releaseDownstream(tx, *match.LoserMatchID, []int{match.LoserSlot}, cascade, revertedBy, reason, reverted)
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// Откатываем рейтинги ровно на примененные изменения
+	target, err := h.ratingSubjects(tx, match.TournamentID, winnerID, loserID)
+	if err != nil {
+		return err
+	}
+
+	winnerRating, winnerReverted, err := revertRatingResult(tx, target, matchID, target.WinnerID, true, !match.IsWalkover, *match.WinnerRatingDelta, winnerBefore)
+	if err != nil {
+		return err
+	}
+
+	loserRating, loserReverted, err := revertRatingResult(tx, target, matchID, target.LoserID, false, !match.IsWalkover, *match.LoserRatingDelta, loserBefore)
+	if err != nil {
+		return err
+	}
+
+	// Отмена рейтингового матча игроков отражается в истории рейтинга
+	if target.Table == "users" && !match.IsWalkover {
+		winnerChange := ratingChange(target.Ladder, winnerID, loserID, matchID, true, winnerRating, winnerReverted)
+		winnerChange.Reason = models.RatingReasonRevert
+
+		loserChange := ratingChange(target.Ladder, loserID, winnerID, matchID, false, loserRating, loserReverted)
+		loserChange.Reason = models.RatingReasonRevert
+
+		if err = recordRatingChange(tx, winnerChange); err != nil {
+			return err
+		}
+		if err = recordRatingChange(tx, loserChange); err != nil {
+			return err
+		}
+	}
+
+	if err = h.resetMatchResults(tx, matchID); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE matches
+		SET winner_id = NULL, status = 'pending', finished_at = NULL,
+		    winner_rating_delta = NULL, loser_rating_delta = NULL,
+		    winner_rating_before = NULL, winner_deviation_before = NULL,
+		    winner_volatility_before = NULL, winner_rated_at_before = NULL,
+		    loser_rating_before = NULL, loser_deviation_before = NULL,
+		    loser_volatility_before = NULL, loser_rated_at_before = NULL,
+		    resolved_by = NULL, resolution_reason = NULL, resolved_at = NULL,
+		    reverted_by = $1, revert_reason = $2, reverted_at = CURRENT_TIMESTAMP,
+		    deadline = NULL, deadline_warned_at = NULL, deadline_expired_at = NULL, player1_ready_at = NULL, player2_ready_at = NULL,
+		    is_walkover = false, draft_status = 'none', draft_turn = 0, draft_turn_deadline = NULL,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`, revertedBy, reason, matchID)
+	if err != nil {
+		return err
+	}
+
+	*reverted = append(*reverted, matchID)
+
+	return nil
+}
+
+// releaseDownstream освобождает слоты следующего матча. Начатый или сыгранный
+// матч без cascade не трогается.
+func (h *TournamentHandlers) releaseDownstream(tx *sqlx.Tx, matchID int, slots []int, cascade bool, revertedBy int, reason string, reverted *[]int) error {
+	var match struct {
+		Status      string `db:"status"`
+		Bye         bool   `db:"bye"`
+		NextMatchID *int   `db:"next_match_id"`
+		NextSlot    int    `db:"next_slot"`
+	}
+	err := tx.Get(&match, `
+		SELECT status, (player1_id IS NULL OR player2_id IS NULL) as bye, next_match_id, next_slot
+		FROM matches WHERE id = $1
+		FOR UPDATE
+	`, matchID)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case match.Status == models.MatchStatusFinished && match.Bye:
+		// Матч пройден без игры (bye) - освобождаем слот, куда перешел игрок
+		if match.NextMatchID != nil {
+			err = h.releaseDownstream(tx, *match.NextMatchID, []int{match.NextSlot}, cascade, revertedBy, reason, reverted)
+			if err != nil {
+				return err
+			}
+		}
+		if _, err = tx.Exec(`UPDATE matches SET winner_id = NULL WHERE id = $1`, matchID); err != nil {
+			return err
+		}
+	case match.Status == models.MatchStatusFinished:
+		if !cascade {
+			return fmt.Errorf("%w: match %d", errDownstreamPlayed, matchID)
+		}
+		if err := h.revertMatch(tx, matchID, cascade, revertedBy, reason, reverted); err != nil {
+			return err
+		}
+	case match.Status == models.MatchStatusInProgress || match.Status == models.MatchStatusDisputed:
+		if !cascade {
+			return fmt.Errorf("%w: match %d", errDownstreamPlayed, matchID)
+		}
+		if err := h.resetMatchResults(tx, matchID); err != nil {
+			return err
+		}
+	case match.Status == models.MatchStatusPending:
+		// Драфт с прежним соперником больше не действителен
+		if _, err := tx.Exec(`DELETE FROM match_hero_picks WHERE match_id = $1`, matchID); err != nil {
+			return err
+		}
+	}
+
+	for _, slot := range slots {
+		query := `
+			UPDATE matches
+			SET player1_id = NULL, player1_ready_at = NULL, status = 'pending',
+			    deadline = NULL, deadline_warned_at = NULL, deadline_expired_at = NULL,
+			    draft_status = 'none', draft_turn = 0, draft_turn_deadline = NULL, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $1`
+		if slot == 1 {
+			query = `
+				UPDATE matches
+				SET player2_id = NULL, player2_ready_at = NULL, status = 'pending',
+				    deadline = NULL, deadline_warned_at = NULL, deadline_expired_at = NULL,
+				    draft_status = 'none', draft_turn = 0, draft_turn_deadline = NULL, updated_at = CURRENT_TIMESTAMP
+				WHERE id = $1`
+		}
+		if _, err := tx.Exec(query, matchID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resetMatchResults удаляет записанные игры, отчеты участников и драфт матча
+func (h *TournamentHandlers) resetMatchResults(tx *sqlx.Tx, matchID int) error {
+	if _, err := tx.Exec(`DELETE FROM match_games WHERE match_id = $1`, matchID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM match_hero_picks WHERE match_id = $1`, matchID); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(`DELETE FROM match_reports WHERE match_id = $1`, matchID)
+	return err
+}
+
+// validateRuleset проверяет правила турнира и существование упомянутых героев
+func (h *TournamentHandlers) validateRuleset(ruleset *models.Ruleset) (validator.ValidationErrors, error) {
+	errs := ruleset.Validate()
+
+	heroIDs := ruleset.HeroIDs()
+	if len(heroIDs) == 0 {
+		return errs, nil
+	}
+
+	ids := make(pq.Int64Array, len(heroIDs))
+	for i, id := range heroIDs {
+		ids[i] = int64(id)
+	}
+
+	heroes, err := h.getHeroes(h.DB, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, id := range ruleset.AllowedHeroes {
+		if _, ok := heroes[id]; !ok {
+			errs = append(errs, validator.ValidationError{
+				Field:   fmt.Sprintf("ruleset.allowed_heroes[%d]", i),
+				Message: "Hero not found",
+				Code:    "NOT_FOUND",
+				Value:   id,
+			})
+		}
+	}
+
+	for i, id := range ruleset.BannedHeroes {
+		if _, ok := heroes[id]; !ok {
+			errs = append(errs, validator.ValidationError{
+				Field:   fmt.Sprintf("ruleset.banned_heroes[%d]", i),
+				Message: "Hero not found",
+				Code:    "NOT_FOUND",
+				Value:   id,
+			})
+		}
+	}
+
+	return errs, nil
+}
+
+// getRuleset возвращает правила турнира (nil - без ограничений)
+func (h *TournamentHandlers) getRuleset(q sqlx.Queryer, tournamentID int) (*models.Ruleset, error) {
+	var ruleset *models.Ruleset
+	err := q.QueryRowx(`SELECT ruleset FROM tournaments WHERE id = $1`, tournamentID).Scan(&ruleset)
+	return ruleset, err
+}
+
+// getHeroes загружает героев по ID
+func (h *TournamentHandlers) getHeroes(q sqlx.Queryer, ids pq.Int64Array) (map[int]models.Hero, error) {
+	var heroes []models.Hero
+	err := sqlx.Select(q, &heroes, `
+		SELECT id, name, element, rarity, role, is_active FROM heroes WHERE id = ANY($1)
+	`, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]models.Hero, len(heroes))
+	for _, hero := range heroes {
+		byID[hero.ID] = hero
+	}
+	return byID, nil
+}
+
+// errInvalidGames ошибка некорректных результатов игр серии
+var errInvalidGames = errors.New("invalid game results")
+
+// validateGameReports проверяет отчеты об играх до записи в базу
+func (h *TournamentHandlers) validateGameReports(match models.Match, games []GameReport) error {
+	seen := make(map[int]bool, len(games))
+	heroIDs := make(map[int]bool)
+
+	for _, game := range games {
+		if game.GameNumber > match.BestOf {
+			return errors.New("game number exceeds best-of for this match")
+		}
+		if seen[game.GameNumber] {
+			return errors.New("duplicate game number " + strconv.Itoa(game.GameNumber))
+		}
+		seen[game.GameNumber] = true
+
+		if game.WinnerID != match.Player1ID && game.WinnerID != match.Player2ID {
+			return errors.New("game winner must be one of the match participants")
+		}
+
+		for _, id := range append(append([]int{}, game.Player1Heroes...), game.Player2Heroes...) {
+			heroIDs[id] = true
+		}
+	}
+
+	if len(heroIDs) == 0 {
+		return nil
+	}
+
+	ids := make(pq.Int64Array, 0, len(heroIDs))
+	for id := range heroIDs {
+		ids = append(ids, int64(id))
+	}
+
+	heroes, err := h.getHeroes(h.DB, ids)
+	if err != nil {
+		return err
+	}
+	if len(heroes) != len(ids) {
+		return errors.New("unknown hero in game results")
+	}
+
+	ruleset, err := h.getRuleset(h.DB, match.TournamentID)
+	if err != nil {
+		return err
+	}
+
+	// Составы с героями проверяются по правилам турнира
+	if ruleset != nil {
+		var errs validator.ValidationErrors
+		for i, game := range games {
+			sides := []struct {
+				field string
+				ids   []int
+			}{
+				{"player1_heroes", game.Player1Heroes},
+				{"player2_heroes", game.Player2Heroes},
+			}
+			for _, side := range sides {
+				if len(side.ids) == 0 {
+					continue
+				}
+				team := make([]models.Hero, len(side.ids))
+				for j, id := range side.ids {
+					team[j] = heroes[id]
+				}
+				errs = append(errs, ruleset.ValidateTeam(fmt.Sprintf("games[%d].%s", i, side.field), team)...)
+			}
+		}
+		if errs.HasErrors() {
+			return errs
+		}
+	}
+
+	if match.DraftStatus == models.DraftStatusCompleted {
+		return h.validateDraftHeroes(match, games)
+	}
+
+	return nil
+}
+
+// recordMatchGames сохраняет игры серии и возвращает победителя серии (0, если
+// серия не решена) и все сыгранные игры
+func (h *TournamentHandlers) recordMatchGames(tx *sqlx.Tx, match models.Match, games []GameReport, reportedBy int) (int, []tournament.GameResult, error) {
+	// Блокируем матч, чтобы параллельные отчеты не перезаписали друг друга
+	if _, err := tx.Exec(`SELECT id FROM matches WHERE id = $1 FOR UPDATE`, match.ID); err != nil {
+		return 0, nil, err
+	}
+
+	var existing []models.MatchGame
+	err := tx.Select(&existing, `
+		SELECT game_number, winner_id FROM match_games WHERE match_id = $1 ORDER BY game_number
+	`, match.ID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// Новые отчеты заменяют ранее записанные игры с тем же номером
+	byNumber := make(map[int]tournament.GameResult, len(existing)+len(games))
+	for _, game := range existing {
+		byNumber[game.GameNumber] = tournament.GameResult{Number: game.GameNumber, WinnerID: game.WinnerID}
+	}
+	for _, game := range games {
+		byNumber[game.GameNumber] = tournament.GameResult{Number: game.GameNumber, WinnerID: game.WinnerID}
+	}
+
+	played := make([]tournament.GameResult, 0, len(byNumber))
+	for _, game := range byNumber {
+		played = append(played, game)
+	}
+
+	winnerID, err := tournament.SeriesWinner(match.BestOf, match.Player1ID, match.Player2ID, played)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: %v", errInvalidGames, err)
+	}
+
+	for _, game := range games {
+		_, err = tx.Exec(`
+			INSERT INTO match_games (match_id, game_number, winner_id, player1_score, player2_score, clear_time_ms,
+			                         player1_heroes, player2_heroes, reported_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT (match_id, game_number) DO UPDATE
+			SET winner_id = EXCLUDED.winner_id,
+			    player1_score = EXCLUDED.player1_score,
+			    player2_score = EXCLUDED.player2_score,
+			    clear_time_ms = EXCLUDED.clear_time_ms,
+			    player1_heroes = EXCLUDED.player1_heroes,
+			    player2_heroes = EXCLUDED.player2_heroes,
+			    reported_by = EXCLUDED.reported_by,
+			    updated_at = CURRENT_TIMESTAMP
+		`, match.ID, game.GameNumber, game.WinnerID, game.Player1Score, game.Player2Score, game.ClearTimeMs,
+			pq.Array(game.Player1Heroes), pq.Array(game.Player2Heroes), reportedBy)
+
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+
+	return winnerID, played, nil
+}
+
+// seriesScore возвращает счет серии по победам каждого игрока
+func seriesScore(match models.Match, played []tournament.GameResult) gin.H {
+	player1Wins, player2Wins := 0, 0
+	for _, game := range played {
+		if game.WinnerID == match.Player1ID {
+			player1Wins++
+		} else if game.WinnerID == match.Player2ID {
+			player2Wins++
+		}
+	}
+
+	return gin.H{
+		"best_of":      match.BestOf,
+		"player1_id":   match.Player1ID,
+		"player1_wins": player1Wins,
+		"player2_id":   match.Player2ID,
+		"player2_wins": player2Wins,
+	}
+}
+
+// insertBracketMatches сохраняет матчи сетки в базу вместе со ссылками между ними.
+// offset - индекс первого матча в Bracket.Matches, ссылки указывают на индексы
+// внутри того же набора матчей.
+func (h *TournamentHandlers) insertBracketMatches(tx *sqlx.Tx, tournamentID int, matches []tournament.Match, offset int) error {
+	ids := make([]int, len(matches))
+
+	for i, match := range matches {
+		var groupNumber interface{}
+		if match.Group > 0 {
+			groupNumber = match.Group
+		}
+
+		// Матч без соперника (bye) сохраняется сразу завершенным
+		status := match.Status
+		if status == "" {
+			status = models.MatchStatusPending
+		}
+
+		bestOf := match.BestOf
+		if bestOf < 1 {
+			bestOf = 1
+		}
+
+		err := tx.QueryRow(`
+			INSERT INTO matches (tournament_id, round, player1_id, player2_id, winner_id, status, bracket, position,
+			                     group_number, best_of)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			RETURNING id
+		`, tournamentID, match.Round, nullablePlayerID(match.Player1ID), nullablePlayerID(match.Player2ID),
+			match.WinnerID, status, match.Bracket, match.Position, groupNumber, bestOf).Scan(&ids[i])
+
+		if err != nil {
+			return err
+		}
+	}
+
+	matchID := func(index *int) (interface{}, error) {
+		if index == nil {
+			return nil, nil
+		}
+		local := *index - offset
+		if local < 0 || local >= len(ids) {
+			return nil, errors.New("bracket link points outside of inserted matches")
+		}
+		return ids[local], nil
+	}
+
+	for i, match := range matches {
+		if match.NextMatch == nil && match.LoserMatch == nil {
+			continue
+		}
+
+		nextID, err := matchID(match.NextMatch)
+		if err != nil {
+			return err
+		}
+		loserID, err := matchID(match.LoserMatch)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`
+			UPDATE matches
+			SET next_match_id = $1, next_slot = $2, loser_match_id = $3, loser_slot = $4
+			WHERE id = $5
+		`, nextID, match.NextSlot, loserID, match.LoserSlot, ids[i])
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// nullablePlayerID возвращает NULL для незаполненного слота матча
+func nullablePlayerID(playerID int) interface{} {
+	if playerID <= 0 {
+		return nil
+	}
+	return playerID
 }
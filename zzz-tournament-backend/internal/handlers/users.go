@@ -4,10 +4,14 @@ package handlers
 import (
 	"database/sql"
 	"log/slog"
+	"sort"
 	"strconv"
+	"time"
 
 	"zzz-tournament/internal/models"
 	"zzz-tournament/internal/websocket"
+	"zzz-tournament/pkg/config"
+	"zzz-tournament/pkg/rating"
 	"zzz-tournament/pkg/utils"
 	"zzz-tournament/pkg/validator"
 
@@ -18,12 +22,16 @@ import (
 // UserHandlers обработчики пользователей
 type UserHandlers struct {
 	BaseHandlers
+	Config *config.RatingConfig
+	Engine rating.Engine
 }
 
 // NewUserHandlers создает новый экземпляр UserHandlers
-func NewUserHandlers(db *sqlx.DB, hub *websocket.Hub, logger *slog.Logger) *UserHandlers {
+func NewUserHandlers(db *sqlx.DB, hub *websocket.Hub, logger *slog.Logger, ratingConfig *config.RatingConfig) *UserHandlers {
 	return &UserHandlers{
 		BaseHandlers: newBaseHandlers(db, hub, logger),
+		Config:       ratingConfig,
+		Engine:       rating.NewEngine(ratingConfig.Engine, ratingConfig.IdlePeriod),
 	}
 }
 
@@ -199,30 +207,106 @@ func (h *UserHandlers) GetLeaderboard(c *gin.Context) {
 		return
 	}
 
+	// Сортировка: по рейтингу или по консервативному рейтингу (рейтинг минус 2 RD).
+	// Отклонение неактивных игроков растет со временем и в базе не хранится,
+	// поэтому консервативная сортировка выполняется после расчета отклонения.
+	sortBy := c.DefaultQuery("sort", "rating")
+	switch sortBy {
+	case "rating", "conservative":
+	default:
+		utils.BadRequestResponse(c, "Invalid sort, expected rating or conservative")
+		return
+	}
+
+	// Ладдер: основной рейтинг хранится в users, остальные - в user_ratings
+	ladder := c.DefaultQuery("ladder", models.RatingTypeTournament)
+	if !models.IsValidLadder(ladder) {
+		utils.BadRequestResponse(c, "Invalid ladder, expected tournament, ranked or casual")
+		return
+	}
+
+	source := "users"
+	var args []interface{}
+	if ladder != models.RatingTypeTournament {
+		args = append(args, ladder)
+		source = `(
+			SELECT u.id, u.username, ur.rating, ur.wins, ur.losses, ur.rated_matches, u.created_at,
+			       ur.rating_deviation, ur.rating_volatility, ur.rating_updated_at
+			FROM user_ratings ur
+			JOIN users u ON ur.user_id = u.id
+			WHERE ur.ladder = $1
+		) ladder`
+	}
+
+	// Провизорные игроки появляются в таблице после калибровочных матчей.
+	// Технические победы калибровкой не считаются.
+	args = append(args, h.Config.PlacementMatches)
+	where := ` WHERE rated_matches >= $` + strconv.Itoa(len(args))
+
 	offset := (page - 1) * perPage
 
 	// Получаем общее количество пользователей
 	var total int
-	err := h.DB.Get(&total, `SELECT COUNT(*) FROM users`)
+	err := h.DB.Get(&total, `SELECT COUNT(*) FROM `+source+where, args...)
 	if err != nil {
 		utils.InternalErrorResponse(c, "Failed to count users")
 		return
 	}
 
 	// Получаем пользователей с пагинацией
-	var users []models.User
+	type LeaderboardEntry struct {
+		models.User
+		RatingDeviation    float64 `db:"rating_deviation" json:"rating_deviation"`
+		RatingVolatility   float64 `db:"rating_volatility" json:"-"`
+		IdleSeconds        float64 `db:"idle_seconds" json:"-"`
+		ConservativeRating int     `db:"-" json:"conservative_rating"`
+	}
+
+	// Для консервативной сортировки загружаются все игроки ладдера
+	limit := ""
+	if sortBy == "rating" {
+		args = append(args, perPage, offset)
+		limit = ` LIMIT $` + strconv.Itoa(len(args)-1) + ` OFFSET $` + strconv.Itoa(len(args))
+	}
+
+	var users []LeaderboardEntry
 	err = h.DB.Select(&users, `
-		SELECT id, username, rating, wins, losses, created_at
-		FROM users
-		ORDER BY rating DESC, wins DESC, username ASC
-		LIMIT $1 OFFSET $2
-	`, perPage, offset)
+		SELECT id, username, rating, wins, losses, rated_matches, created_at, rating_deviation, rating_volatility,
+		       COALESCE(EXTRACT(EPOCH FROM CURRENT_TIMESTAMP - rating_updated_at), 0) as idle_seconds
+		FROM `+source+where+`
+		ORDER BY rating DESC, wins DESC, username ASC`+limit, args...)
 
 	if err != nil {
 		utils.InternalErrorResponse(c, "Failed to fetch leaderboard")
 		return
 	}
 
+	for i := range users {
+		users[i].RatingDeviation = h.Engine.CurrentDeviation(rating.State{
+			Rating:     users[i].Rating,
+			Deviation:  users[i].RatingDeviation,
+			Volatility: users[i].RatingVolatility,
+			Idle:       time.Duration(users[i].IdleSeconds * float64(time.Second)),
+		})
+		users[i].ConservativeRating = rating.ConservativeRating(users[i].Rating, users[i].RatingDeviation)
+	}
+
+	if sortBy == "conservative" {
+		sort.SliceStable(users, func(i, j int) bool {
+			return users[i].ConservativeRating > users[j].ConservativeRating
+		})
+
+		start := offset
+		if start > len(users) {
+			start = len(users)
+		}
+		end := start + perPage
+		if end > len(users) {
+			end = len(users)
+		}
+		users = users[start:end]
+	}
+
 	// Создаем мета информацию для пагинации
 	pagination := utils.NewPaginationMeta(page, perPage, total)
 
@@ -238,10 +322,16 @@ func (h *UserHandlers) GetUserStats(c *gin.Context) {
 		return
 	}
 
+	ladder := c.DefaultQuery("ladder", models.RatingTypeTournament)
+	if !models.IsValidLadder(ladder) {
+		utils.BadRequestResponse(c, "Invalid ladder, expected tournament, ranked or casual")
+		return
+	}
+
 	// Получаем основную информацию о пользователе
 	var user models.User
 	err = h.DB.Get(&user, `
-		SELECT id, username, rating, wins, losses, created_at
+		SELECT id, username, rating, wins, losses, rated_matches, created_at
 		FROM users WHERE id = $1
 	`, userID)
 
@@ -254,34 +344,55 @@ func (h *UserHandlers) GetUserStats(c *gin.Context) {
 		return
 	}
 
+	// Рейтинг и результаты матчей в выбранном ладдере
+	if ladder != models.RatingTypeTournament {
+		ladderStats, err := ladderRating(h.DB, &user, ladder)
+		if err != nil {
+			utils.InternalErrorResponse(c, "Database error")
+			return
+		}
+		user.Rating, user.Wins, user.Losses = ladderStats.Rating, ladderStats.Wins, ladderStats.Losses
+		user.RatedMatches = ladderStats.RatedMatches
+	}
+
 	// Получаем дополнительную статистику
 	type UserStats struct {
 		models.User
-		TotalGames       int     `json:"total_games"`
-		WinRate          float64 `json:"win_rate"`
-		TournamentsWon   int     `json:"tournaments_won"`
-		TournamentsTotal int     `json:"tournaments_total"`
-		CurrentStreak    int     `json:"current_streak"`
-		BestStreak       int     `json:"best_streak"`
-		RatingTier       string  `json:"rating_tier"`
-		RatingColor      string  `json:"rating_color"`
-		Rank             int     `json:"rank"`
+		Ladder           string                       `json:"ladder"`
+		TotalGames       int                          `json:"total_games"`
+		WinRate          float64                      `json:"win_rate"`
+		TournamentsWon   int                          `json:"tournaments_won"`
+		TournamentsTotal int                          `json:"tournaments_total"`
+		CurrentStreak    int                          `json:"current_streak"`
+		BestStreak       int                          `json:"best_streak"`
+		RatingTier       string                       `json:"rating_tier"`
+		RatingColor      string                       `json:"rating_color"`
+		Rank             int                          `json:"rank"` // 0 для провизорного игрока
+		Provisional      bool                         `json:"provisional"`
+		PlacementLeft    int                          `json:"placement_matches_left"`
+		PodiumFinishes   int                          `json:"podium_finishes"`
+		BestPlacement    *int                         `json:"best_placement,omitempty"`
+		Placements       []models.TournamentPlacement `json:"placements"`
+		Rivals           []Rival                      `json:"rivals"`
 	}
 
 	stats := UserStats{
 		User:       user,
+		Ladder:     ladder,
 		TotalGames: user.Wins + user.Losses,
 	}
+	stats.Provisional, stats.PlacementLeft = h.placementStatus(user.RatedMatches)
 
 	// Рассчитываем процент побед
 	if stats.TotalGames > 0 {
 		stats.WinRate = float64(user.Wins) / float64(stats.TotalGames) * 100
 	}
 
-	// Получаем количество выигранных турниров
+	// Получаем количество выигранных турниров. Рейтинговые матчи 1v1 из очереди
+	// оформлены турнирами, но в турнирной статистике не учитываются.
 	err = h.DB.Get(&stats.TournamentsWon, `
-		SELECT COUNT(*) FROM tournaments WHERE winner_id = $1 AND status = 'finished'
-	`, userID)
+		SELECT COUNT(*) FROM tournaments WHERE winner_id = $1 AND status = 'finished' AND ladder <> $2
+	`, userID, models.RatingTypeRanked)
 	if err != nil {
 		stats.TournamentsWon = 0
 	}
@@ -291,19 +402,58 @@ func (h *UserHandlers) GetUserStats(c *gin.Context) {
 		SELECT COUNT(DISTINCT t.id) 
 		FROM tournaments t
 		JOIN room_participants rp ON t.room_id = rp.room_id
-		WHERE rp.user_id = $1 AND t.status = 'finished'
-	`, userID)
+		WHERE rp.user_id = $1 AND t.status = 'finished' AND t.ladder <> $2
+	`, userID, models.RatingTypeRanked)
 	if err != nil {
 		stats.TournamentsTotal = 0
 	}
 
+	// Получаем историю итоговых мест
+	err = h.DB.Select(&stats.Placements, `
+		SELECT tp.tournament_id, t.name as tournament_name, tp.user_id, tp.place, tp.place_to, tp.created_at
+		FROM tournament_placements tp
+		JOIN tournaments t ON tp.tournament_id = t.id
+		WHERE tp.user_id = $1 AND t.ladder <> $2
+		ORDER BY tp.created_at DESC
+		LIMIT 20
+	`, userID, models.RatingTypeRanked)
+	if err != nil || stats.Placements == nil {
+		stats.Placements = []models.TournamentPlacement{}
+	}
+
+	var best sql.NullInt64
+	err = h.DB.QueryRow(`
+		SELECT COUNT(*) FILTER (WHERE tp.place <= 3), MIN(tp.place)
+		FROM tournament_placements tp
+		JOIN tournaments t ON tp.tournament_id = t.id
+		WHERE tp.user_id = $1 AND t.ladder <> $2
+	`, userID, models.RatingTypeRanked).Scan(&stats.PodiumFinishes, &best)
+	if err == nil && best.Valid {
+		bestPlace := int(best.Int64)
+		stats.BestPlacement = &bestPlace
+	}
+
+	// Самые частые соперники
+	stats.Rivals, err = h.getRivals(userID)
+	if err != nil {
+		stats.Rivals = []Rival{}
+	}
+
 	// TODO: Рассчитать текущую и лучшую серии побед
 	// Это требует более сложных запросов к истории матчей
 
-	// Получаем ранг в рейтинге
-	err = h.DB.Get(&stats.Rank, `
-		SELECT COUNT(*) + 1 FROM users WHERE rating > $1
-	`, user.Rating)
+	// Получаем ранг в рейтинге ладдера среди откалиброванных игроков
+	if stats.Provisional {
+		stats.Rank = 0
+	} else if ladder == models.RatingTypeTournament {
+		err = h.DB.Get(&stats.Rank, `
+			SELECT COUNT(*) + 1 FROM users WHERE rating > $1 AND rated_matches >= $2
+		`, user.Rating, h.Config.PlacementMatches)
+	} else {
+		err = h.DB.Get(&stats.Rank, `
+			SELECT COUNT(*) + 1 FROM user_ratings WHERE ladder = $1 AND rating > $2 AND rated_matches >= $3
+		`, ladder, user.Rating, h.Config.PlacementMatches)
+	}
 	if err != nil {
 		stats.Rank = 0
 	}
@@ -401,6 +551,15 @@ func (h *UserHandlers) GetUserByID(c *gin.Context) {
 
 // Вспомогательные функции
 
+// placementStatus возвращает, проходит ли игрок калибровку, и сколько калибровочных матчей осталось.
+// Калибровочными считаются только рейтинговые матчи, без технических побед.
+func (h *UserHandlers) placementStatus(ratedMatches int) (bool, int) {
+	if !h.Config.IsProvisional(ratedMatches) {
+		return false, 0
+	}
+	return true, h.Config.PlacementMatches - ratedMatches
+}
+
 func getPageFromQuery(c *gin.Context, defaultPage int) int {
 	pageStr := c.DefaultQuery("page", strconv.Itoa(defaultPage))
 	page, err := strconv.Atoi(pageStr)
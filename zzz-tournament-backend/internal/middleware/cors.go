@@ -3,6 +3,7 @@ package middleware
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -216,7 +217,7 @@ func isOriginAllowed(origin string, allowedOrigins []string) bool {
 
 // formatDuration форматирует duration в секунды для заголовка Max-Age
 func formatDuration(d time.Duration) string {
-	return string(int(d.Seconds()))
+	return strconv.Itoa(int(d.Seconds()))
 }
 
 // WebSocketCORSMiddleware специальный CORS для WebSocket соединений
@@ -29,7 +29,7 @@ type SecurityEvent struct {
 	ID        int                    `json:"id" db:"id"`
 	UserID    *int                   `json:"user_id,omitempty" db:"user_id"`
 	Event     string                 `json:"event" db:"event"`
-	ClientIP  string                 `json:"client_ip" db:"client_ip"`
+	ClientIP  *string                `json:"client_ip,omitempty" db:"client_ip"` // Нет у событий фоновых задач
 	UserAgent string                 `json:"user_agent" db:"user_agent"`
 	Details   map[string]interface{} `json:"details" db:"details"`
 	CreatedAt time.Time              `json:"created_at" db:"created_at"`
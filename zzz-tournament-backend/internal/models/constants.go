@@ -27,6 +27,13 @@ const (
 	// Hero constraints
 	MaxHeroNameLength  = 50
 	MaxHeroDescription = 1000
+
+	// Team constraints
+	MinTeamNameLength  = 3
+	MaxTeamNameLength  = 50
+	MaxTeamTagLength   = 5
+	MaxTeamPlayers     = 6
+	MaxTeamSubstitutes = 3
 )
 
 // Default values
@@ -8,7 +8,11 @@ package models
 // auth.go - модели аутентификации (токены, события безопасности)
 // hero.go - модель героя и константы
 // room.go - модель комнаты и участников
+// team.go - модель команды и ее состава
 // tournament.go - модели турнира и матчей
+// rating.go - история рейтинга
+// season.go - модели сезонов и итоговых мест
+// moderation.go - метки подозрений на договорные результаты
 // message.go - модель сообщений
 // websocket.go - модели WebSocket сообщений
 // constants.go - общие константы и ограничения
@@ -52,6 +56,7 @@ func NewTournament(roomID int, name string) *Tournament {
 		RoomID:  roomID,
 		Name:    name,
 		Status:  TournamentStatusCreated,
+		Format:  TournamentFormatSingleElimination,
 		Bracket: make(map[string]interface{}),
 	}
 }
@@ -64,6 +69,7 @@ func NewMatch(tournamentID, round, player1ID, player2ID int) *Match {
 		Player1ID:    player1ID,
 		Player2ID:    player2ID,
 		Status:       MatchStatusPending,
+		Bracket:      MatchBracketWinners,
 	}
 }
 
@@ -0,0 +1,65 @@
+// internal/models/moderation.go
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// CollusionFlag подозрение на договорные результаты между двумя игроками
+type CollusionFlag struct {
+	ID              int             `json:"id" db:"id"`
+	Pattern         string          `json:"pattern" db:"pattern"` // repeated_pair, fast_result, alternating_wins, feeder_account
+	UserID          int             `json:"user_id" db:"user_id"`
+	Username        string          `json:"username,omitempty" db:"username"`
+	PartnerID       int             `json:"partner_id" db:"partner_id"`
+	PartnerUsername string          `json:"partner_username,omitempty" db:"partner_username"`
+	MatchIDs        pq.Int64Array   `json:"match_ids" db:"match_ids"`
+	LastMatchAt     time.Time       `json:"last_match_at" db:"last_match_at"`
+	Details         json.RawMessage `json:"details,omitempty" db:"details"`
+	SecurityEventID *int            `json:"security_event_id,omitempty" db:"security_event_id"`
+	Status          string          `json:"status" db:"status"` // open, confirmed, dismissed
+	ReviewedBy      *int            `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewedAt      *time.Time      `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	ReviewNote      *string         `json:"review_note,omitempty" db:"review_note"`
+	CreatedAt       time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// CollusionPattern константы шаблонов договорных результатов
+const (
+	CollusionPatternRepeatedPair    = "repeated_pair"
+	CollusionPatternFastResult      = "fast_result"
+	CollusionPatternAlternatingWins = "alternating_wins"
+	CollusionPatternFeederAccount   = "feeder_account"
+)
+
+// CollusionFlagStatus константы статусов проверки метки
+const (
+	CollusionFlagOpen      = "open"
+	CollusionFlagConfirmed = "confirmed"
+	CollusionFlagDismissed = "dismissed"
+)
+
+// IsValidCollusionPattern проверяет валидность шаблона
+func IsValidCollusionPattern(pattern string) bool {
+	switch pattern {
+	case CollusionPatternRepeatedPair, CollusionPatternFastResult,
+		CollusionPatternAlternatingWins, CollusionPatternFeederAccount:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsValidCollusionFlagStatus проверяет валидность статуса метки
+func IsValidCollusionFlagStatus(status string) bool {
+	switch status {
+	case CollusionFlagOpen, CollusionFlagConfirmed, CollusionFlagDismissed:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,60 @@
+// internal/models/rating.go
+package models
+
+import "time"
+
+// RatingHistory запись истории изменения рейтинга пользователя
+type RatingHistory struct {
+	ID              int       `json:"id" db:"id"`
+	UserID          int       `json:"user_id" db:"user_id"`
+	MatchID         *int      `json:"match_id,omitempty" db:"match_id"`
+	TournamentID    *int      `json:"tournament_id,omitempty" db:"tournament_id"`
+	OpponentID      *int      `json:"opponent_id,omitempty" db:"opponent_id"`
+	Won             *bool     `json:"won,omitempty" db:"won"`       // Итог матча для записей match и revert
+	RatingType      string    `json:"rating_type" db:"rating_type"` // tournament, ranked, casual
+	Reason          string    `json:"reason" db:"reason"`           // match, revert, season_reset, decay
+	RatingBefore    int       `json:"rating_before" db:"rating_before"`
+	RatingAfter     int       `json:"rating_after" db:"rating_after"`
+	Delta           int       `json:"delta" db:"delta"`
+	RatingDeviation float64   `json:"rating_deviation" db:"rating_deviation"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+// Типы рейтинговых матчей. Для каждого типа ведется отдельный ладдер;
+// основной рейтинг пользователя соответствует ладдеру tournament.
+const (
+	RatingTypeTournament = "tournament"
+	RatingTypeRanked     = "ranked"
+	RatingTypeCasual     = "casual"
+)
+
+// UserRating рейтинг пользователя в ладдере
+type UserRating struct {
+	UserID          int        `json:"user_id" db:"user_id"`
+	Ladder          string     `json:"ladder" db:"ladder"`
+	Rating          int        `json:"rating" db:"rating"`
+	RatingDeviation float64    `json:"rating_deviation" db:"rating_deviation"`
+	Wins            int        `json:"wins" db:"wins"`
+	Losses          int        `json:"losses" db:"losses"`
+	RatedMatches    int        `json:"rated_matches" db:"rated_matches"` // Без технических побед
+	RatingUpdatedAt *time.Time `json:"rating_updated_at,omitempty" db:"rating_updated_at"`
+}
+
+// IsValidLadder проверяет валидность ладдера
+func IsValidLadder(ladder string) bool {
+	switch ladder {
+	case RatingTypeTournament, RatingTypeRanked, RatingTypeCasual:
+		return true
+	default:
+		return false
+	}
+}
+
+// Причины изменения рейтинга
+const (
+	RatingReasonMatch  = "match"  // Результат матча
+	RatingReasonRevert = "revert" // Отмена результата матча
+
+	RatingReasonSeasonReset = "season_reset" // Сброс рейтинга в начале нового сезона
+	RatingReasonDecay       = "decay"        // Снижение рейтинга за неактивность
+)
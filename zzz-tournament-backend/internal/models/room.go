@@ -14,24 +14,33 @@ type Room struct {
 	Host         *User     `json:"host,omitempty"`
 	MaxPlayers   int       `json:"max_players" db:"max_players"`
 	CurrentCount int       `json:"current_count" db:"current_count"`
-	Status       string    `json:"status" db:"status"` // waiting, in_progress, finished
+	Status       string    `json:"status" db:"status"` // waiting, check_in, in_progress, finished
 	IsPrivate    bool      `json:"is_private" db:"is_private"`
 	Password     string    `json:"-" db:"password"` // Скрыто в JSON
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
 	Participants []User    `json:"participants,omitempty"`
+
+	CheckInDeadline *time.Time `json:"checkin_deadline,omitempty" db:"checkin_deadline"`
+
+	// Командный режим: участниками комнаты являются капитаны команд
+	TeamMode bool `json:"team_mode" db:"team_mode"`
+	TeamSize *int `json:"team_size,omitempty" db:"team_size"` // Минимум основных игроков в команде
 }
 
 // RoomParticipant связь участника с комнатой
 type RoomParticipant struct {
-	RoomID   int       `json:"room_id" db:"room_id"`
-	UserID   int       `json:"user_id" db:"user_id"`
-	JoinedAt time.Time `json:"joined_at" db:"joined_at"`
+	RoomID      int        `json:"room_id" db:"room_id"`
+	UserID      int        `json:"user_id" db:"user_id"`
+	JoinedAt    time.Time  `json:"joined_at" db:"joined_at"`
+	CheckedInAt *time.Time `json:"checked_in_at,omitempty" db:"checked_in_at"`
+	TeamID      *int       `json:"team_id,omitempty" db:"team_id"` // Команда капитана в командной комнате
 }
 
 // RoomStatus константы статусов комнат
 const (
 	RoomStatusWaiting    = "waiting"
+	RoomStatusCheckIn    = "check_in"
 	RoomStatusInProgress = "in_progress"
 	RoomStatusFinished   = "finished"
 )
@@ -39,7 +48,7 @@ const (
 // IsValidRoomStatus проверяет валидность статуса комнаты
 func IsValidRoomStatus(status string) bool {
 	switch status {
-	case RoomStatusWaiting, RoomStatusInProgress, RoomStatusFinished:
+	case RoomStatusWaiting, RoomStatusCheckIn, RoomStatusInProgress, RoomStatusFinished:
 		return true
 	default:
 		return false
@@ -0,0 +1,263 @@
+// internal/models/ruleset.go
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"zzz-tournament/pkg/validator"
+)
+
+// MaxTeamSize максимальный размер команды в правилах турнира
+const MaxTeamSize = 5
+
+// Ruleset правила турнира для составов команд. Пустые списки и нулевые лимиты
+// означают отсутствие ограничения.
+type Ruleset struct {
+	TeamSize        int            `json:"team_size,omitempty"`        // Точное количество героев в команде
+	AllowedHeroes   []int          `json:"allowed_heroes,omitempty"`   // Пул разрешенных героев
+	BannedHeroes    []int          `json:"banned_heroes,omitempty"`    // Запрещенные герои (например, на текущем патче)
+	AllowedElements []string       `json:"allowed_elements,omitempty"` // Разрешенные элементы
+	AllowedRoles    []string       `json:"allowed_roles,omitempty"`    // Разрешенные роли
+	RarityCaps      map[string]int `json:"rarity_caps,omitempty"`      // Максимум героев редкости в команде: {"S": 1}
+	ElementLimits   map[string]int `json:"element_limits,omitempty"`   // Максимум героев элемента в команде
+	RoleLimits      map[string]int `json:"role_limits,omitempty"`      // Максимум героев роли в команде
+}
+
+// Validate проверяет корректность самих правил
+func (r *Ruleset) Validate() validator.ValidationErrors {
+	var errs validator.ValidationErrors
+
+	if r.TeamSize < 0 || r.TeamSize > MaxTeamSize {
+		errs = append(errs, validator.ValidationError{
+			Field:   "ruleset.team_size",
+			Message: fmt.Sprintf("Team size must be between 0 and %d", MaxTeamSize),
+			Code:    "INVALID_VALUE",
+			Value:   r.TeamSize,
+		})
+	}
+
+	banned := make(map[int]bool, len(r.BannedHeroes))
+	for _, id := range r.BannedHeroes {
+		banned[id] = true
+	}
+	for i, id := range r.AllowedHeroes {
+		if banned[id] {
+			errs = append(errs, validator.ValidationError{
+				Field:   fmt.Sprintf("ruleset.allowed_heroes[%d]", i),
+				Message: "Hero cannot be both allowed and banned",
+				Code:    "CONFLICT",
+				Value:   id,
+			})
+		}
+	}
+
+	for i, element := range r.AllowedElements {
+		if !IsValidElement(element) {
+			errs = append(errs, validator.ValidationError{
+				Field:   fmt.Sprintf("ruleset.allowed_elements[%d]", i),
+				Message: "Invalid hero element",
+				Code:    "INVALID_VALUE",
+				Value:   element,
+			})
+		}
+	}
+
+	for i, role := range r.AllowedRoles {
+		if !IsValidRole(role) {
+			errs = append(errs, validator.ValidationError{
+				Field:   fmt.Sprintf("ruleset.allowed_roles[%d]", i),
+				Message: "Invalid hero role",
+				Code:    "INVALID_VALUE",
+				Value:   role,
+			})
+		}
+	}
+
+	errs = append(errs, validateRulesetLimits("ruleset.rarity_caps", r.RarityCaps, IsValidRarity)...)
+	errs = append(errs, validateRulesetLimits("ruleset.element_limits", r.ElementLimits, IsValidElement)...)
+	errs = append(errs, validateRulesetLimits("ruleset.role_limits", r.RoleLimits, IsValidRole)...)
+
+	return errs
+}
+
+// validateRulesetLimits проверяет ключи и значения лимитов по атрибуту героя
+func validateRulesetLimits(field string, limits map[string]int, isValid func(string) bool) validator.ValidationErrors {
+	var errs validator.ValidationErrors
+
+	for _, key := range sortedKeys(limits) {
+		limit := limits[key]
+		if !isValid(key) {
+			errs = append(errs, validator.ValidationError{
+				Field:   field + "." + key,
+				Message: "Unknown value " + key,
+				Code:    "INVALID_VALUE",
+				Value:   key,
+			})
+			continue
+		}
+		if limit < 0 {
+			errs = append(errs, validator.ValidationError{
+				Field:   field + "." + key,
+				Message: "Limit must not be negative",
+				Code:    "TOO_SMALL",
+				Value:   limit,
+			})
+		}
+	}
+
+	return errs
+}
+
+// HeroIDs возвращает всех героев, упомянутых в правилах
+func (r *Ruleset) HeroIDs() []int {
+	return append(append([]int{}, r.AllowedHeroes...), r.BannedHeroes...)
+}
+
+// HeroAllowed проверяет, можно ли использовать героя в турнире
+func (r *Ruleset) HeroAllowed(hero Hero) bool {
+	return r.heroViolation("", hero) == nil
+}
+
+// heroViolation возвращает нарушение правил для отдельного героя
+func (r *Ruleset) heroViolation(field string, hero Hero) *validator.ValidationError {
+	violation := func(code, message string) *validator.ValidationError {
+		return &validator.ValidationError{Field: field, Message: message, Code: code, Value: hero.ID}
+	}
+
+	if containsInt(r.BannedHeroes, hero.ID) {
+		return violation("HERO_BANNED", hero.Name+" is banned in this tournament")
+	}
+	if len(r.AllowedHeroes) > 0 && !containsInt(r.AllowedHeroes, hero.ID) {
+		return violation("HERO_NOT_ALLOWED", hero.Name+" is not in the tournament hero pool")
+	}
+	if len(r.AllowedElements) > 0 && !containsString(r.AllowedElements, hero.Element) {
+		return violation("ELEMENT_NOT_ALLOWED", hero.Name+": element "+hero.Element+" is not allowed")
+	}
+	if len(r.AllowedRoles) > 0 && !containsString(r.AllowedRoles, hero.Role) {
+		return violation("ROLE_NOT_ALLOWED", hero.Name+": role "+hero.Role+" is not allowed")
+	}
+
+	return nil
+}
+
+// ValidateTeam проверяет состав команды: размер, допустимость героев и лимиты
+func (r *Ruleset) ValidateTeam(field string, team []Hero) validator.ValidationErrors {
+	var errs validator.ValidationErrors
+
+	if r.TeamSize > 0 && len(team) != r.TeamSize {
+		errs = append(errs, validator.ValidationError{
+			Field:   field,
+			Message: fmt.Sprintf("Team must consist of exactly %d heroes", r.TeamSize),
+			Code:    "TEAM_SIZE",
+			Value:   len(team),
+		})
+	}
+
+	return append(errs, r.ValidatePicks(field, team)...)
+}
+
+// ValidatePicks проверяет героев и лимиты состава без требования к размеру
+// команды. Используется для неполного состава, например во время драфта.
+func (r *Ruleset) ValidatePicks(field string, team []Hero) validator.ValidationErrors {
+	var errs validator.ValidationErrors
+
+	seen := make(map[int]bool, len(team))
+	rarities := make(map[string]int)
+	elements := make(map[string]int)
+	roles := make(map[string]int)
+
+	for i, hero := range team {
+		heroField := fmt.Sprintf("%s[%d]", field, i)
+
+		if seen[hero.ID] {
+			errs = append(errs, validator.ValidationError{
+				Field:   heroField,
+				Message: hero.Name + " is already in the team",
+				Code:    "DUPLICATE",
+				Value:   hero.ID,
+			})
+			continue
+		}
+		seen[hero.ID] = true
+
+		if violation := r.heroViolation(heroField, hero); violation != nil {
+			errs = append(errs, *violation)
+		}
+
+		rarities[hero.Rarity]++
+		elements[hero.Element]++
+		roles[hero.Role]++
+	}
+
+	errs = append(errs, checkRulesetLimits(field, "RARITY_CAP", "rarity", r.RarityCaps, rarities)...)
+	errs = append(errs, checkRulesetLimits(field, "ELEMENT_LIMIT", "element", r.ElementLimits, elements)...)
+	errs = append(errs, checkRulesetLimits(field, "ROLE_LIMIT", "role", r.RoleLimits, roles)...)
+
+	return errs
+}
+
+// checkRulesetLimits сравнивает количество героев с лимитами правил
+func checkRulesetLimits(field, code, attribute string, limits, counts map[string]int) validator.ValidationErrors {
+	var errs validator.ValidationErrors
+
+	for _, key := range sortedKeys(limits) {
+		if limit := limits[key]; counts[key] > limit {
+			errs = append(errs, validator.ValidationError{
+				Field:   field,
+				Message: fmt.Sprintf("At most %d heroes with %s %s are allowed, got %d", limit, attribute, key, counts[key]),
+				Code:    code,
+				Value:   counts[key],
+			})
+		}
+	}
+
+	return errs
+}
+
+// Value сохраняет правила в JSONB
+func (r Ruleset) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+// Scan читает правила из JSONB
+func (r *Ruleset) Scan(value interface{}) error {
+	data, ok := value.([]byte)
+	if !ok {
+		return errors.New("ruleset must be JSON")
+	}
+	return json.Unmarshal(data, r)
+}
+
+// sortedKeys возвращает ключи лимитов по порядку, чтобы ошибки шли стабильно
+func sortedKeys(limits map[string]int) []string {
+	keys := make([]string, 0, len(limits))
+	for key := range limits {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// containsInt проверяет наличие числа в списке
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString проверяет наличие строки в списке
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
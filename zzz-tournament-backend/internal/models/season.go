@@ -0,0 +1,44 @@
+// internal/models/season.go
+package models
+
+import "time"
+
+// Season соревновательный сезон
+type Season struct {
+	ID         int        `json:"id" db:"id"`
+	Name       string     `json:"name" db:"name"`
+	StartsAt   time.Time  `json:"starts_at" db:"starts_at"`
+	EndsAt     time.Time  `json:"ends_at" db:"ends_at"`
+	Status     string     `json:"status" db:"status"` // scheduled, active, finished
+	CreatedBy  *int       `json:"created_by,omitempty" db:"created_by"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" db:"finished_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// SeasonStatus константы статусов сезона
+const (
+	SeasonStatusScheduled = "scheduled"
+	SeasonStatusActive    = "active"
+	SeasonStatusFinished  = "finished"
+)
+
+// SeasonStanding итоговое место игрока в ладдере по завершении сезона
+type SeasonStanding struct {
+	SeasonID   int    `json:"season_id" db:"season_id"`
+	UserID     int    `json:"user_id" db:"user_id"`
+	Username   string `json:"username,omitempty" db:"username"`
+	Ladder     string `json:"ladder" db:"ladder"`
+	Rank       int    `json:"rank" db:"rank"`
+	Rating     int    `json:"rating" db:"rating"`
+	Tier       string `json:"tier" db:"tier"`
+	PeakRating int    `json:"peak_rating" db:"peak_rating"`
+	PeakTier   string `json:"peak_tier" db:"peak_tier"`
+	Wins       int    `json:"wins" db:"wins"`
+	Losses     int    `json:"losses" db:"losses"`
+}
+
+// IsFinished проверяет, завершен ли сезон
+func (s *Season) IsFinished() bool {
+	return s.Status == SeasonStatusFinished
+}
@@ -0,0 +1,87 @@
+// internal/models/team.go
+package models
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Team модель команды
+type Team struct {
+	ID        int          `json:"id" db:"id"`
+	Name      string       `json:"name" db:"name"`
+	Tag       *string      `json:"tag,omitempty" db:"tag"`
+	CaptainID int          `json:"captain_id" db:"captain_id"`
+	Captain   *User        `json:"captain,omitempty"`
+	Rating    int          `json:"rating" db:"rating"`
+	Wins      int          `json:"wins" db:"wins"`
+	Losses    int          `json:"losses" db:"losses"`
+	Members   []TeamMember `json:"members,omitempty"`
+	CreatedAt time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+// TeamMember игрок в составе команды
+type TeamMember struct {
+	TeamID    int       `json:"team_id" db:"team_id"`
+	UserID    int       `json:"user_id" db:"user_id"`
+	Username  string    `json:"username" db:"username"`
+	Rating    int       `json:"rating" db:"rating"`
+	Role      string    `json:"role" db:"role"` // player, substitute
+	IsCaptain bool      `json:"is_captain" db:"is_captain"`
+	JoinedAt  time.Time `json:"joined_at" db:"joined_at"`
+}
+
+// TeamRole константы ролей в составе команды
+const (
+	TeamRolePlayer     = "player"
+	TeamRoleSubstitute = "substitute"
+)
+
+// IsValidTeamRole проверяет валидность роли в составе команды
+func IsValidTeamRole(role string) bool {
+	switch role {
+	case TeamRolePlayer, TeamRoleSubstitute:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsCaptain проверяет, является ли пользователь капитаном команды
+func (t *Team) IsCaptain(userID int) bool {
+	return t.CaptainID == userID
+}
+
+// Players возвращает основных игроков команды
+func (t *Team) Players() []TeamMember {
+	var players []TeamMember
+	for _, member := range t.Members {
+		if member.Role == TeamRolePlayer {
+			players = append(players, member)
+		}
+	}
+	return players
+}
+
+// Substitutes возвращает запасных игроков команды
+func (t *Team) Substitutes() []TeamMember {
+	var substitutes []TeamMember
+	for _, member := range t.Members {
+		if member.Role == TeamRoleSubstitute {
+			substitutes = append(substitutes, member)
+		}
+	}
+	return substitutes
+}
+
+// TournamentTeam состав команды, зафиксированный при запуске турнира
+type TournamentTeam struct {
+	TournamentID int           `json:"tournament_id" db:"tournament_id"`
+	TeamID       int           `json:"team_id" db:"team_id"`
+	CaptainID    int           `json:"captain_id" db:"captain_id"`
+	Name         string        `json:"name" db:"name"`
+	Players      pq.Int64Array `json:"players" db:"players"`
+	Substitutes  pq.Int64Array `json:"substitutes" db:"substitutes"`
+}
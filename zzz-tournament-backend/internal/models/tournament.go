@@ -3,36 +3,194 @@ package models
 
 import (
 	"time"
+
+	"github.com/lib/pq"
 )
 
 // Tournament модель турнира
 type Tournament struct {
-	ID        int                    `json:"id" db:"id"`
-	RoomID    int                    `json:"room_id" db:"room_id"`
-	Name      string                 `json:"name" db:"name"`
-	Status    string                 `json:"status" db:"status"` // created, started, finished
-	Bracket   map[string]interface{} `json:"bracket" db:"bracket"`
-	WinnerID  *int                   `json:"winner_id" db:"winner_id"`
-	Winner    *User                  `json:"winner,omitempty"`
-	CreatedAt time.Time              `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time              `json:"updated_at" db:"updated_at"`
-	Matches   []Match                `json:"matches,omitempty"`
+	ID              int                    `json:"id" db:"id"`
+	RoomID          int                    `json:"room_id" db:"room_id"`
+	Name            string                 `json:"name" db:"name"`
+	Status          string                 `json:"status" db:"status"` // created, started, finished
+	Format          string                 `json:"format" db:"format"` // single_elimination, double_elimination, round_robin, groups_playoffs, swiss, score_attack
+	GroupCount      int                    `json:"group_count" db:"group_count"`
+	AdvancePerGroup int                    `json:"advance_per_group" db:"advance_per_group"`
+	DeadlineMinutes int                    `json:"match_deadline_minutes" db:"match_deadline_minutes"`
+	WalkoverRule    string                 `json:"walkover_rule" db:"walkover_rule"` // ready, rating, none
+	Bracket         map[string]interface{} `json:"bracket" db:"bracket"`
+	WinnerID        *int                   `json:"winner_id" db:"winner_id"`
+	Winner          *User                  `json:"winner,omitempty"`
+	CreatedAt       time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time              `json:"updated_at" db:"updated_at"`
+	Matches         []Match                `json:"matches,omitempty"`
+
+	// Драфт героев
+	DraftBans        int    `json:"draft_bans" db:"draft_bans"`
+	DraftPicks       int    `json:"draft_picks" db:"draft_picks"` // 0 - без драфта
+	DraftTurnSeconds int    `json:"draft_turn_seconds" db:"draft_turn_seconds"`
+	DraftTimeoutRule string `json:"draft_timeout_rule" db:"draft_timeout_rule"` // auto_pick, skip
+
+	// Правила составов команд (nil - без ограничений)
+	Ruleset *Ruleset `json:"ruleset,omitempty" db:"ruleset"`
+
+	// Турнир на очки или время (score_attack)
+	ScoreType   *string    `json:"score_type,omitempty" db:"score_type"` // score, time
+	RunsOpenAt  *time.Time `json:"runs_open_at,omitempty" db:"runs_open_at"`
+	RunsCloseAt *time.Time `json:"runs_close_at,omitempty" db:"runs_close_at"`
+
+	// Командный турнир
+	TeamMode     bool `json:"team_mode" db:"team_mode"`
+	WinnerTeamID *int `json:"winner_team_id,omitempty" db:"winner_team_id"`
+
+	// Ладдер рейтинга, в который засчитываются матчи: tournament, casual
+	Ladder string `json:"ladder" db:"ladder"`
 }
 
 // Match модель матча
 type Match struct {
-	ID           int       `json:"id" db:"id"`
-	TournamentID int       `json:"tournament_id" db:"tournament_id"`
-	Round        int       `json:"round" db:"round"`
-	Player1ID    int       `json:"player1_id" db:"player1_id"`
-	Player2ID    int       `json:"player2_id" db:"player2_id"`
-	Player1      *User     `json:"player1,omitempty"`
-	Player2      *User     `json:"player2,omitempty"`
-	WinnerID     *int      `json:"winner_id" db:"winner_id"`
-	Winner       *User     `json:"winner,omitempty"`
-	Status       string    `json:"status" db:"status"` // pending, in_progress, finished
-	CreatedAt    time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+	ID           int         `json:"id" db:"id"`
+	TournamentID int         `json:"tournament_id" db:"tournament_id"`
+	Round        int         `json:"round" db:"round"`
+	Player1ID    int         `json:"player1_id" db:"player1_id"`
+	Player2ID    int         `json:"player2_id" db:"player2_id"`
+	Player1      *User       `json:"player1,omitempty"`
+	Player2      *User       `json:"player2,omitempty"`
+	WinnerID     *int        `json:"winner_id" db:"winner_id"`
+	Winner       *User       `json:"winner,omitempty"`
+	Status       string      `json:"status" db:"status"`   // pending, in_progress, disputed, finished, cancelled
+	Bracket      string      `json:"bracket" db:"bracket"` // winners, losers, grand_final, group, swiss, third_place
+	Position     int         `json:"position" db:"position"`
+	GroupNumber  int         `json:"group_number,omitempty" db:"group_number"`
+	NextMatchID  *int        `json:"next_match_id" db:"next_match_id"`   // Куда переходит победитель
+	NextSlot     int         `json:"next_slot" db:"next_slot"`           // 0 - player1, 1 - player2
+	LoserMatchID *int        `json:"loser_match_id" db:"loser_match_id"` // Куда переходит проигравший
+	LoserSlot    int         `json:"loser_slot" db:"loser_slot"`
+	BestOf       int         `json:"best_of" db:"best_of"`
+	Games        []MatchGame `json:"games,omitempty"`
+	CreatedAt    time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at" db:"updated_at"`
+
+	// Разрешение спора о результате
+	ResolvedBy       *int       `json:"resolved_by,omitempty" db:"resolved_by"`
+	ResolutionReason *string    `json:"resolution_reason,omitempty" db:"resolution_reason"`
+	ResolvedAt       *time.Time `json:"resolved_at,omitempty" db:"resolved_at"`
+
+	// Время фиксации результата
+	FinishedAt *time.Time `json:"finished_at,omitempty" db:"finished_at"`
+
+	// Изменения рейтинга по итогам матча и последняя отмена результата
+	WinnerRatingDelta *int       `json:"winner_rating_delta,omitempty" db:"winner_rating_delta"`
+	LoserRatingDelta  *int       `json:"loser_rating_delta,omitempty" db:"loser_rating_delta"`
+	RevertedBy        *int       `json:"reverted_by,omitempty" db:"reverted_by"`
+	RevertReason      *string    `json:"revert_reason,omitempty" db:"revert_reason"`
+	RevertedAt        *time.Time `json:"reverted_at,omitempty" db:"reverted_at"`
+
+	// Срок матча и техническая победа
+	Deadline       *time.Time `json:"deadline,omitempty" db:"deadline"`
+	Player1ReadyAt *time.Time `json:"player1_ready_at,omitempty" db:"player1_ready_at"`
+	Player2ReadyAt *time.Time `json:"player2_ready_at,omitempty" db:"player2_ready_at"`
+	IsWalkover     bool       `json:"is_walkover" db:"is_walkover"`
+
+	// Драфт героев
+	DraftStatus       string     `json:"draft_status" db:"draft_status"` // none, in_progress, completed
+	DraftTurn         int        `json:"draft_turn,omitempty" db:"draft_turn"`
+	DraftTurnDeadline *time.Time `json:"draft_turn_deadline,omitempty" db:"draft_turn_deadline"`
+
+	// Команды командного матча (слоты игроков занимают капитаны)
+	Team1ID      *int `json:"team1_id,omitempty" db:"team1_id"`
+	Team2ID      *int `json:"team2_id,omitempty" db:"team2_id"`
+	WinnerTeamID *int `json:"winner_team_id,omitempty" db:"winner_team_id"`
+}
+
+// MatchGame результат отдельной игры серии
+type MatchGame struct {
+	ID            int           `json:"id" db:"id"`
+	MatchID       int           `json:"match_id" db:"match_id"`
+	GameNumber    int           `json:"game_number" db:"game_number"`
+	WinnerID      int           `json:"winner_id" db:"winner_id"`
+	Player1Score  *int          `json:"player1_score,omitempty" db:"player1_score"`
+	Player2Score  *int          `json:"player2_score,omitempty" db:"player2_score"`
+	ClearTimeMs   *int          `json:"clear_time_ms,omitempty" db:"clear_time_ms"`
+	Player1Heroes pq.Int64Array `json:"player1_heroes" db:"player1_heroes"`
+	Player2Heroes pq.Int64Array `json:"player2_heroes" db:"player2_heroes"`
+	ReportedBy    *int          `json:"reported_by,omitempty" db:"reported_by"`
+	CreatedAt     time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+// MatchReport отчет участника о результате матча. Результат засчитывается,
+// когда отчеты обоих участников совпадают.
+type MatchReport struct {
+	ID         int       `json:"id" db:"id"`
+	MatchID    int       `json:"match_id" db:"match_id"`
+	ReporterID int       `json:"reporter_id" db:"reporter_id"`
+	Games      []byte    `json:"-" db:"games"` // JSON со списком игр
+	Details    string    `json:"details,omitempty" db:"details"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// MatchHeroPick бан или пик героя в драфте матча
+type MatchHeroPick struct {
+	ID        int       `json:"id" db:"id"`
+	MatchID   int       `json:"match_id" db:"match_id"`
+	Turn      int       `json:"turn" db:"turn"`
+	UserID    int       `json:"user_id" db:"user_id"`
+	HeroID    *int      `json:"hero_id" db:"hero_id"` // nil - ход пропущен
+	HeroName  string    `json:"hero_name,omitempty" db:"hero_name"`
+	Action    string    `json:"action" db:"action"` // ban, pick
+	IsAuto    bool      `json:"is_auto" db:"is_auto"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// TournamentRun заход участника турнира на очки или время
+type TournamentRun struct {
+	ID           int           `json:"id" db:"id"`
+	TournamentID int           `json:"tournament_id" db:"tournament_id"`
+	UserID       int           `json:"user_id" db:"user_id"`
+	Username     string        `json:"username,omitempty" db:"username"`
+	Score        *int64        `json:"score,omitempty" db:"score"`
+	ClearTimeMs  *int64        `json:"clear_time_ms,omitempty" db:"clear_time_ms"`
+	Heroes       pq.Int64Array `json:"heroes" db:"heroes"`
+	EvidenceURL  *string       `json:"evidence_url,omitempty" db:"evidence_url"`
+	Notes        *string       `json:"notes,omitempty" db:"notes"`
+	Status       string        `json:"status" db:"status"` // pending, verified, rejected
+	ReviewedBy   *int          `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewReason *string       `json:"review_reason,omitempty" db:"review_reason"`
+	ReviewedAt   *time.Time    `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	CreatedAt    time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+// EvidenceFile скриншот-подтверждение результата матча или захода
+type EvidenceFile struct {
+	ID           int        `json:"id" db:"id"`
+	TournamentID int        `json:"tournament_id" db:"tournament_id"`
+	MatchID      *int       `json:"match_id,omitempty" db:"match_id"`
+	RunID        *int       `json:"run_id,omitempty" db:"run_id"`
+	UploaderID   int        `json:"uploader_id" db:"uploader_id"`
+	Uploader     string     `json:"uploader,omitempty" db:"uploader"`
+	StorageKey   string     `json:"-" db:"storage_key"`
+	ContentType  string     `json:"content_type" db:"content_type"`
+	SizeBytes    int64      `json:"size_bytes" db:"size_bytes"`
+	OriginalName *string    `json:"original_name,omitempty" db:"original_name"`
+	URL          string     `json:"url,omitempty"`            // Подписанная ссылка на файл
+	URLExpiresAt *time.Time `json:"url_expires_at,omitempty"` // Срок действия ссылки
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// TournamentPlacement итоговое место игрока в турнире
+type TournamentPlacement struct {
+	TournamentID   int       `json:"tournament_id" db:"tournament_id"`
+	TournamentName string    `json:"tournament_name,omitempty" db:"tournament_name"`
+	UserID         int       `json:"user_id" db:"user_id"`
+	Username       string    `json:"username,omitempty" db:"username"`
+	Place          int       `json:"place" db:"place"`
+	PlaceTo        int       `json:"place_to" db:"place_to"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+
+	TeamID *int `json:"team_id,omitempty" db:"team_id"` // Команда в командном турнире
 }
 
 // TournamentStatus константы статусов турниров
@@ -47,6 +205,42 @@ const (
 	MatchStatusPending    = "pending"
 	MatchStatusInProgress = "in_progress"
 	MatchStatusFinished   = "finished"
+	MatchStatusCancelled  = "cancelled"
+	MatchStatusDisputed   = "disputed"
+)
+
+// DraftStatus константы статусов драфта героев
+const (
+	DraftStatusNone       = "none"
+	DraftStatusInProgress = "in_progress"
+	DraftStatusCompleted  = "completed"
+)
+
+// TournamentFormat константы форматов турниров
+const (
+	TournamentFormatSingleElimination = "single_elimination"
+	TournamentFormatDoubleElimination = "double_elimination"
+	TournamentFormatRoundRobin        = "round_robin"
+	TournamentFormatGroupsPlayoffs    = "groups_playoffs"
+	TournamentFormatSwiss             = "swiss"
+	TournamentFormatScoreAttack       = "score_attack"
+)
+
+// RunStatus константы статусов заходов турнира на очки или время
+const (
+	RunStatusPending  = "pending"
+	RunStatusVerified = "verified"
+	RunStatusRejected = "rejected"
+)
+
+// MatchBracket константы сеток матчей
+const (
+	MatchBracketWinners    = "winners"
+	MatchBracketLosers     = "losers"
+	MatchBracketGrandFinal = "grand_final"
+	MatchBracketGroup      = "group"
+	MatchBracketSwiss      = "swiss"
+	MatchBracketThirdPlace = "third_place"
 )
 
 // IsValidTournamentStatus проверяет валидность статуса турнира
@@ -62,13 +256,40 @@ func IsValidTournamentStatus(status string) bool {
 // IsValidMatchStatus проверяет валидность статуса матча
 func IsValidMatchStatus(status string) bool {
 	switch status {
-	case MatchStatusPending, MatchStatusInProgress, MatchStatusFinished:
+	case MatchStatusPending, MatchStatusInProgress, MatchStatusFinished, MatchStatusCancelled, MatchStatusDisputed:
 		return true
 	default:
 		return false
 	}
 }
 
+// IsValidTournamentFormat проверяет валидность формата турнира
+func IsValidTournamentFormat(format string) bool {
+	switch format {
+	case TournamentFormatSingleElimination, TournamentFormatDoubleElimination,
+		TournamentFormatRoundRobin, TournamentFormatGroupsPlayoffs, TournamentFormatSwiss,
+		TournamentFormatScoreAttack:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsValidRunStatus проверяет валидность статуса захода
+func IsValidRunStatus(status string) bool {
+	switch status {
+	case RunStatusPending, RunStatusVerified, RunStatusRejected:
+		return true
+	default:
+		return false
+	}
+}
+
+// HasGroupStage проверяет, есть ли в формате турнира групповой этап
+func (t *Tournament) HasGroupStage() bool {
+	return t.Format == TournamentFormatRoundRobin || t.Format == TournamentFormatGroupsPlayoffs
+}
+
 // IsFinished проверяет, завершен ли турнир
 func (t *Tournament) IsFinished() bool {
 	return t.Status == TournamentStatusFinished
@@ -109,3 +330,8 @@ func (m *Match) IsFinished() bool {
 func (m *Match) HasWinner() bool {
 	return m.WinnerID != nil
 }
+
+// IsTeamMatch проверяет, является ли матч командным
+func (m *Match) IsTeamMatch() bool {
+	return m.Team1ID != nil || m.Team2ID != nil
+}
@@ -14,6 +14,7 @@ type User struct {
 	Rating        int        `json:"rating" db:"rating"`
 	Wins          int        `json:"wins" db:"wins"`
 	Losses        int        `json:"losses" db:"losses"`
+	RatedMatches  int        `json:"rated_matches" db:"rated_matches"` // Без технических побед
 	IsActive      bool       `json:"is_active" db:"is_active"`
 	IsVerified    bool       `json:"is_verified" db:"is_verified"`
 	LastLogin     *time.Time `json:"last_login,omitempty" db:"last_login"`
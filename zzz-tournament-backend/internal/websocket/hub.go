@@ -66,12 +66,18 @@ type Client struct {
 	cancel   context.CancelFunc
 }
 
+// MessageHandler обработчик типа сообщений, зарегистрированный извне хаба.
+// Возвращенные данные отправляются клиенту с тем же типом сообщения.
+type MessageHandler func(client *Client, data interface{}) (interface{}, error)
+
 type Hub struct {
 	Clients    map[*Client]bool
 	Broadcast  chan []byte
 	Register   chan *Client
 	Unregister chan *Client
 	Rooms      map[int]map[*Client]bool
+	handlers   map[string]MessageHandler
+	Queue      *MatchQueue // Очередь рейтинговых матчей 1v1
 	mu         sync.RWMutex
 	ctx        context.Context
 	cancel     context.CancelFunc
@@ -85,6 +91,8 @@ func NewHub() *Hub {
 		Register:   make(chan *Client),
 		Unregister: make(chan *Client),
 		Rooms:      make(map[int]map[*Client]bool),
+		handlers:   make(map[string]MessageHandler),
+		Queue:      NewMatchQueue(),
 		ctx:        ctx,
 		cancel:     cancel,
 	}
@@ -147,6 +155,12 @@ func (h *Hub) unregisterClient(client *Client) {
 			}
 		}
 
+		// Отключившийся пользователь покидает очередь рейтинговых матчей,
+		// если у него не осталось других подключений
+		if !h.hasClientLocked(client.UserID) {
+			h.Queue.Leave(client.UserID)
+		}
+
 		// Отменяем контекст клиента
 		if client.cancel != nil {
 			client.cancel()
@@ -156,6 +170,16 @@ func (h *Hub) unregisterClient(client *Client) {
 	}
 }
 
+// hasClientLocked проверяет, есть ли у пользователя подключения. Вызывается под h.mu.
+func (h *Hub) hasClientLocked(userID int) bool {
+	for client := range h.Clients {
+		if client.UserID == userID {
+			return true
+		}
+	}
+	return false
+}
+
 func (h *Hub) broadcastMessage(message []byte) {
 	h.mu.RLock()
 	clients := make([]*Client, 0, len(h.Clients))
@@ -319,7 +343,25 @@ func (c *Client) handleMessage(msg models.WSMessage) {
 	case "heartbeat":
 		c.handleHeartbeat(msg.Data)
 	default:
-		log.Printf("Unknown message type: %s from client %d", msg.Type, c.UserID)
+		c.Hub.mu.RLock()
+		handler, ok := c.Hub.handlers[msg.Type]
+		c.Hub.mu.RUnlock()
+
+		if !ok {
+			log.Printf("Unknown message type: %s from client %d", msg.Type, c.UserID)
+			return
+		}
+
+		result, err := handler(c, msg.Data)
+		if err != nil {
+			c.sendMessage(models.WSMessage{
+				Type: models.WSTypeError,
+				Data: models.ErrorData{Message: err.Error(), Code: msg.Type},
+			})
+			return
+		}
+
+		c.sendMessage(models.WSMessage{Type: msg.Type, Data: result})
 	}
 }
 
@@ -437,6 +479,37 @@ func (h *Hub) BroadcastToRoom(roomID int, message []byte) {
 	}
 }
 
+// HandleMessage регистрирует обработчик для типа сообщений, который хаб не
+// обрабатывает сам (например, действия, требующие доступа к базе данных)
+func (h *Hub) HandleMessage(msgType string, handler MessageHandler) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.handlers[msgType] = handler
+}
+
+// SendToUser отправляет сообщение всем подключениям пользователя, независимо от комнаты
+func (h *Hub) SendToUser(userID int, message []byte) {
+	h.mu.RLock()
+	clients := make([]*Client, 0, 1)
+	for client := range h.Clients {
+		if client.UserID == userID {
+			clients = append(clients, client)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, client := range clients {
+		select {
+		case client.Send <- message:
+		default:
+			go func(c *Client) {
+				h.Unregister <- c
+			}(client)
+		}
+	}
+}
+
 func (h *Hub) JoinRoom(client *Client, roomID int) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
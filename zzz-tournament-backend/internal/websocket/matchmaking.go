@@ -0,0 +1,298 @@
+// internal/websocket/matchmaking.go
+package websocket
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Ошибки очереди рейтинговых матчей
+var (
+	ErrAlreadyQueued   = errors.New("already in ranked queue")
+	ErrInReadyCheck    = errors.New("ranked match already found, answer the ready check")
+	ErrNoReadyCheck    = errors.New("no pending ready check")
+	ErrAlreadyAnswered = errors.New("ready check already accepted")
+)
+
+// ReadyCheckStatus константы состояний проверки готовности
+const (
+	ReadyCheckPending   = "pending"
+	ReadyCheckConfirmed = "confirmed"
+	ReadyCheckCancelled = "cancelled"
+)
+
+// QueueEntry игрок в очереди рейтинговых матчей
+type QueueEntry struct {
+	UserID   int       `json:"user_id"`
+	Username string    `json:"username"`
+	Rating   int       `json:"rating"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// ReadyCheck подобранная пара игроков, ожидающая подтверждения готовности
+type ReadyCheck struct {
+	ID       int           `json:"id"`
+	Players  [2]QueueEntry `json:"players"`
+	Accepted [2]bool       `json:"accepted"`
+	Deadline time.Time     `json:"deadline"`
+	Status   string        `json:"status"`
+
+	// Requeued игроки, подтвердившие готовность и вернувшиеся в очередь после отмены
+	Requeued []int `json:"requeued,omitempty"`
+}
+
+// Slot возвращает позицию игрока в паре или -1
+func (r *ReadyCheck) Slot(userID int) int {
+	for i, p := range r.Players {
+		if p.UserID == userID {
+			return i
+		}
+	}
+	return -1
+}
+
+// Opponent возвращает соперника игрока в паре
+func (r *ReadyCheck) Opponent(userID int) QueueEntry {
+	if r.Players[0].UserID == userID {
+		return r.Players[1]
+	}
+	return r.Players[0]
+}
+
+// MatchQueue очередь рейтинговых матчей 1v1. Хранится в памяти хаба:
+// после перезапуска сервера игроки встают в очередь заново.
+type MatchQueue struct {
+	entries map[int]QueueEntry
+	checks  map[int]*ReadyCheck
+	byUser  map[int]int // Пользователь -> ID проверки готовности
+	nextID  int
+	mu      sync.Mutex
+}
+
+// NewMatchQueue создает пустую очередь
+func NewMatchQueue() *MatchQueue {
+	return &MatchQueue{
+		entries: make(map[int]QueueEntry),
+		checks:  make(map[int]*ReadyCheck),
+		byUser:  make(map[int]int),
+	}
+}
+
+// Join ставит игрока в очередь
+func (q *MatchQueue) Join(entry QueueEntry) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.entries[entry.UserID]; ok {
+		return ErrAlreadyQueued
+	}
+	if _, ok := q.byUser[entry.UserID]; ok {
+		return ErrInReadyCheck
+	}
+
+	q.entries[entry.UserID] = entry
+	return nil
+}
+
+// Leave убирает игрока из очереди ожидания и сообщает, стоял ли он в ней
+func (q *MatchQueue) Leave(userID int) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.entries[userID]; !ok {
+		return false
+	}
+
+	delete(q.entries, userID)
+	return true
+}
+
+// Requeue возвращает игроков в очередь с прежним временем постановки,
+// чтобы не терять накопленное расширение окна рейтинга
+func (q *MatchQueue) Requeue(entries ...QueueEntry) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, entry := range entries {
+		if _, ok := q.byUser[entry.UserID]; ok {
+			continue
+		}
+		q.entries[entry.UserID] = entry
+	}
+}
+
+// Entry возвращает запись игрока в очереди
+func (q *MatchQueue) Entry(userID int) (QueueEntry, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entry, ok := q.entries[userID]
+	return entry, ok
+}
+
+// ReadyCheckFor возвращает копию текущей проверки готовности игрока
+func (q *MatchQueue) ReadyCheckFor(userID int) (ReadyCheck, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	id, ok := q.byUser[userID]
+	if !ok {
+		return ReadyCheck{}, false
+	}
+	return *q.checks[id], true
+}
+
+// Len возвращает количество игроков, ожидающих соперника
+func (q *MatchQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.entries)
+}
+
+// Pair подбирает пары игроков. Дольше всех ожидающий игрок получает соперника
+// с ближайшим рейтингом, если разница укладывается в окно обоих игроков.
+// Окно рассчитывается функцией window по времени ожидания.
+func (q *MatchQueue) Pair(now time.Time, window func(wait time.Duration) int, readyTimeout time.Duration) []ReadyCheck {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	waiting := make([]QueueEntry, 0, len(q.entries))
+	for _, entry := range q.entries {
+		waiting = append(waiting, entry)
+	}
+	sort.Slice(waiting, func(i, j int) bool {
+		if waiting[i].JoinedAt.Equal(waiting[j].JoinedAt) {
+			return waiting[i].UserID < waiting[j].UserID
+		}
+		return waiting[i].JoinedAt.Before(waiting[j].JoinedAt)
+	})
+
+	paired := make(map[int]bool)
+	var checks []ReadyCheck
+
+	for i, a := range waiting {
+		if paired[a.UserID] {
+			continue
+		}
+		windowA := window(now.Sub(a.JoinedAt))
+
+		best := -1
+		bestDiff := 0
+		for j := i + 1; j < len(waiting); j++ {
+			b := waiting[j]
+			if paired[b.UserID] {
+				continue
+			}
+
+			diff := a.Rating - b.Rating
+			if diff < 0 {
+				diff = -diff
+			}
+			if diff > windowA || diff > window(now.Sub(b.JoinedAt)) {
+				continue
+			}
+			if best == -1 || diff < bestDiff {
+				best, bestDiff = j, diff
+			}
+		}
+
+		if best == -1 {
+			continue
+		}
+
+		b := waiting[best]
+		paired[a.UserID], paired[b.UserID] = true, true
+		delete(q.entries, a.UserID)
+		delete(q.entries, b.UserID)
+
+		q.nextID++
+		check := &ReadyCheck{
+			ID:       q.nextID,
+			Players:  [2]QueueEntry{a, b},
+			Deadline: now.Add(readyTimeout),
+			Status:   ReadyCheckPending,
+		}
+		q.checks[check.ID] = check
+		q.byUser[a.UserID] = check.ID
+		q.byUser[b.UserID] = check.ID
+
+		checks = append(checks, *check)
+	}
+
+	return checks
+}
+
+// Ready принимает ответ игрока на проверку готовности. Отказ отменяет проверку,
+// и соперник, уже подтвердивший готовность, возвращается в очередь.
+// Возвращает состояние проверки после ответа.
+func (q *MatchQueue) Ready(userID int, accept bool) (ReadyCheck, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	id, ok := q.byUser[userID]
+	if !ok {
+		return ReadyCheck{}, ErrNoReadyCheck
+	}
+
+	check := q.checks[id]
+	slot := check.Slot(userID)
+
+	if !accept {
+		check.Accepted[slot] = false
+		q.cancel(check)
+		return *check, nil
+	}
+
+	if check.Accepted[slot] {
+		return *check, ErrAlreadyAnswered
+	}
+
+	check.Accepted[slot] = true
+	if check.Accepted[0] && check.Accepted[1] {
+		check.Status = ReadyCheckConfirmed
+		q.remove(check)
+	}
+
+	return *check, nil
+}
+
+// Expire отменяет проверки готовности с истекшим сроком
+func (q *MatchQueue) Expire(now time.Time) []ReadyCheck {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var expired []ReadyCheck
+	for _, check := range q.checks {
+		if now.Before(check.Deadline) {
+			continue
+		}
+		q.cancel(check)
+		expired = append(expired, *check)
+	}
+
+	return expired
+}
+
+// cancel отменяет проверку готовности и возвращает в очередь подтвердивших игроков
+func (q *MatchQueue) cancel(check *ReadyCheck) {
+	check.Status = ReadyCheckCancelled
+	q.remove(check)
+
+	for i, p := range check.Players {
+		if check.Accepted[i] {
+			q.entries[p.UserID] = p
+			check.Requeued = append(check.Requeued, p.UserID)
+		}
+	}
+}
+
+// remove удаляет проверку готовности из очереди
+func (q *MatchQueue) remove(check *ReadyCheck) {
+	delete(q.checks, check.ID)
+	for _, p := range check.Players {
+		delete(q.byUser, p.UserID)
+	}
+}
@@ -85,6 +85,16 @@ func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	return defaultValue
 }
 
+// getEnvFloat получает float значение из переменной окружения
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
 // LoadAuthConfig загружает конфигурацию аутентификации
 func LoadAuthConfig() (*AuthConfig, error) {
 	config := &AuthConfig{
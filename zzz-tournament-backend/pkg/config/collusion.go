@@ -0,0 +1,80 @@
+// pkg/config/collusion.go
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// CollusionConfig содержит пороги поиска договорных результатов (win-trading)
+type CollusionConfig struct {
+	CheckInterval time.Duration `yaml:"check_interval" env:"COLLUSION_CHECK_INTERVAL" default:"1h"`
+	WindowDays    int           `yaml:"window_days" env:"COLLUSION_WINDOW_DAYS" default:"30"`
+
+	// Повторяющиеся встречи одной пары в разных турнирах
+	RepeatedMatches int `yaml:"repeated_matches" env:"COLLUSION_REPEATED_MATCHES" default:"5"`
+
+	// Результаты, отправленные сразу после создания матча
+	FastResult        time.Duration `yaml:"fast_result" env:"COLLUSION_FAST_RESULT" default:"60s"`
+	FastResultMatches int           `yaml:"fast_result_matches" env:"COLLUSION_FAST_RESULT_MATCHES" default:"3"`
+
+	// Серия встреч пары, в которой победы строго чередуются
+	AlternatingMatches int `yaml:"alternating_matches" env:"COLLUSION_ALTERNATING_MATCHES" default:"6"`
+
+	// Новые аккаунты, которые только проигрывают одному игроку
+	NewAccountDays int `yaml:"new_account_days" env:"COLLUSION_NEW_ACCOUNT_DAYS" default:"14"`
+	FeederLosses   int `yaml:"feeder_losses" env:"COLLUSION_FEEDER_LOSSES" default:"3"`
+}
+
+// LoadCollusionConfig загружает конфигурацию поиска договорных результатов
+func LoadCollusionConfig() (*CollusionConfig, error) {
+	config := &CollusionConfig{
+		CheckInterval:      getEnvDuration("COLLUSION_CHECK_INTERVAL", time.Hour),
+		WindowDays:         getEnvInt("COLLUSION_WINDOW_DAYS", 30),
+		RepeatedMatches:    getEnvInt("COLLUSION_REPEATED_MATCHES", 5),
+		FastResult:         getEnvDuration("COLLUSION_FAST_RESULT", 60*time.Second),
+		FastResultMatches:  getEnvInt("COLLUSION_FAST_RESULT_MATCHES", 3),
+		AlternatingMatches: getEnvInt("COLLUSION_ALTERNATING_MATCHES", 6),
+		NewAccountDays:     getEnvInt("COLLUSION_NEW_ACCOUNT_DAYS", 14),
+		FeederLosses:       getEnvInt("COLLUSION_FEEDER_LOSSES", 3),
+	}
+
+	return config, nil
+}
+
+// Validate проверяет корректность конфигурации
+func (c *CollusionConfig) Validate() error {
+	if c.CheckInterval <= 0 {
+		return fmt.Errorf("collusion check interval must be positive")
+	}
+
+	if c.WindowDays < 1 {
+		return fmt.Errorf("collusion window must be at least 1 day")
+	}
+
+	if c.RepeatedMatches < 2 {
+		return fmt.Errorf("collusion repeated matches threshold must be at least 2")
+	}
+
+	if c.FastResult <= 0 {
+		return fmt.Errorf("collusion fast result threshold must be positive")
+	}
+
+	if c.FastResultMatches < 1 {
+		return fmt.Errorf("collusion fast result matches threshold must be at least 1")
+	}
+
+	if c.AlternatingMatches < 4 {
+		return fmt.Errorf("collusion alternating matches threshold must be at least 4")
+	}
+
+	if c.NewAccountDays < 1 {
+		return fmt.Errorf("collusion new account age must be at least 1 day")
+	}
+
+	if c.FeederLosses < 2 {
+		return fmt.Errorf("collusion feeder losses threshold must be at least 2")
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+// pkg/config/matchmaking.go
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// MatchmakingConfig содержит настройки очереди рейтинговых матчей 1v1
+type MatchmakingConfig struct {
+	// Подбор соперника
+	TickInterval time.Duration `yaml:"tick_interval" env:"RANKED_TICK_INTERVAL" default:"2s"`
+	ReadyTimeout time.Duration `yaml:"ready_timeout" env:"RANKED_READY_TIMEOUT" default:"20s"`
+
+	// Окно рейтинга: начинается с WindowBase и расширяется на WindowStep
+	// за каждые WindowInterval ожидания, но не больше WindowMax
+	WindowBase     int           `yaml:"window_base" env:"RANKED_WINDOW_BASE" default:"100"`
+	WindowStep     int           `yaml:"window_step" env:"RANKED_WINDOW_STEP" default:"50"`
+	WindowInterval time.Duration `yaml:"window_interval" env:"RANKED_WINDOW_INTERVAL" default:"15s"`
+	WindowMax      int           `yaml:"window_max" env:"RANKED_WINDOW_MAX" default:"600"`
+}
+
+// LoadMatchmakingConfig загружает конфигурацию очереди рейтинговых матчей
+func LoadMatchmakingConfig() (*MatchmakingConfig, error) {
+	config := &MatchmakingConfig{
+		TickInterval:   getEnvDuration("RANKED_TICK_INTERVAL", 2*time.Second),
+		ReadyTimeout:   getEnvDuration("RANKED_READY_TIMEOUT", 20*time.Second),
+		WindowBase:     getEnvInt("RANKED_WINDOW_BASE", 100),
+		WindowStep:     getEnvInt("RANKED_WINDOW_STEP", 50),
+		WindowInterval: getEnvDuration("RANKED_WINDOW_INTERVAL", 15*time.Second),
+		WindowMax:      getEnvInt("RANKED_WINDOW_MAX", 600),
+	}
+
+	return config, nil
+}
+
+// Validate проверяет корректность конфигурации
+func (c *MatchmakingConfig) Validate() error {
+	if c.TickInterval <= 0 {
+		return fmt.Errorf("ranked tick interval must be positive")
+	}
+
+	if c.ReadyTimeout < 5*time.Second {
+		return fmt.Errorf("ranked ready timeout must be at least 5 seconds")
+	}
+
+	if c.WindowBase < 0 || c.WindowStep < 0 {
+		return fmt.Errorf("ranked rating window must not be negative")
+	}
+
+	if c.WindowInterval <= 0 {
+		return fmt.Errorf("ranked window interval must be positive")
+	}
+
+	if c.WindowMax < c.WindowBase {
+		return fmt.Errorf("ranked max rating window must not be less than base window")
+	}
+
+	return nil
+}
+
+// RatingWindow возвращает допустимую разницу рейтингов для игрока,
+// ожидающего в очереди wait
+func (c *MatchmakingConfig) RatingWindow(wait time.Duration) int {
+	window := c.WindowBase + int(wait/c.WindowInterval)*c.WindowStep
+	if window > c.WindowMax {
+		return c.WindowMax
+	}
+	return window
+}
@@ -0,0 +1,128 @@
+// pkg/config/rating.go
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Рейтинговые системы
+const (
+	RatingEngineElo     = "elo"     // Elo с K-факторами по рейтингу и опыту
+	RatingEngineGlicko2 = "glicko2" // Glicko-2 с отклонением и волатильностью
+)
+
+// RatingConfig содержит настройки рейтинговой системы
+type RatingConfig struct {
+	Engine string `yaml:"engine" env:"RATING_ENGINE" default:"elo"`
+
+	// Glicko-2 пересчитывает рейтинг после каждого матча, как если бы матч был
+	// отдельным рейтинговым периодом (приближение: результаты не группируются
+	// по периодам). IdlePeriod задает только рост отклонения: за каждый такой
+	// интервал без матчей отклонение рейтинга увеличивается.
+	IdlePeriod time.Duration `yaml:"idle_period" env:"RATING_IDLE_PERIOD" default:"168h"`
+
+	// Сезоны: при смене сезона рейтинг сдвигается к среднему
+	// по формуле mean + (rating - mean) * factor
+	SeasonCheckInterval  time.Duration `yaml:"season_check_interval" env:"SEASON_CHECK_INTERVAL" default:"1m"`
+	SeasonResetMean      int           `yaml:"season_reset_mean" env:"SEASON_RESET_MEAN" default:"1000"`
+	SeasonResetFactor    float64       `yaml:"season_reset_factor" env:"SEASON_RESET_FACTOR" default:"0.5"`
+	SeasonResetDeviation float64       `yaml:"season_reset_deviation" env:"SEASON_RESET_DEVIATION" default:"200"` // Минимальное отклонение Glicko-2 после сброса
+
+	// Калибровка: до PlacementMatches матчей в ладдере игрок считается
+	// провизорным и не попадает в таблицу лидеров
+	PlacementMatches int `yaml:"placement_matches" env:"RATING_PLACEMENT_MATCHES" default:"10"`
+
+	// Снижение рейтинга за неактивность: рейтинг выше DecayThreshold
+	// уменьшается на DecayAmount за каждый день без матчей после DecayAfterDays дней
+	DecayCheckInterval time.Duration `yaml:"decay_check_interval" env:"RATING_DECAY_CHECK_INTERVAL" default:"1h"`
+	DecayThreshold     int           `yaml:"decay_threshold" env:"RATING_DECAY_THRESHOLD" default:"1600"`
+	DecayAfterDays     int           `yaml:"decay_after_days" env:"RATING_DECAY_AFTER_DAYS" default:"14"`
+	DecayAmount        int           `yaml:"decay_amount" env:"RATING_DECAY_AMOUNT" default:"25"` // 0 - снижение отключено
+}
+
+// LoadRatingConfig загружает конфигурацию рейтинга
+func LoadRatingConfig() (*RatingConfig, error) {
+	config := &RatingConfig{
+		Engine:     getEnv("RATING_ENGINE", RatingEngineElo),
+		IdlePeriod: getEnvDuration("RATING_IDLE_PERIOD", 7*24*time.Hour),
+
+		SeasonCheckInterval:  getEnvDuration("SEASON_CHECK_INTERVAL", time.Minute),
+		SeasonResetMean:      getEnvInt("SEASON_RESET_MEAN", 1000),
+		SeasonResetFactor:    getEnvFloat("SEASON_RESET_FACTOR", 0.5),
+		SeasonResetDeviation: getEnvFloat("SEASON_RESET_DEVIATION", 200),
+
+		PlacementMatches: getEnvInt("RATING_PLACEMENT_MATCHES", 10),
+
+		DecayCheckInterval: getEnvDuration("RATING_DECAY_CHECK_INTERVAL", time.Hour),
+		DecayThreshold:     getEnvInt("RATING_DECAY_THRESHOLD", 1600),
+		DecayAfterDays:     getEnvInt("RATING_DECAY_AFTER_DAYS", 14),
+		DecayAmount:        getEnvInt("RATING_DECAY_AMOUNT", 25),
+	}
+
+	return config, nil
+}
+
+// Validate проверяет корректность конфигурации
+func (c *RatingConfig) Validate() error {
+	if !IsValidRatingEngine(c.Engine) {
+		return fmt.Errorf("unknown rating engine: %s", c.Engine)
+	}
+
+	if c.IdlePeriod <= 0 {
+		return fmt.Errorf("rating idle period must be positive")
+	}
+
+	if c.SeasonCheckInterval <= 0 {
+		return fmt.Errorf("season check interval must be positive")
+	}
+
+	if c.SeasonResetMean < 0 {
+		return fmt.Errorf("season reset mean must not be negative")
+	}
+
+	if c.SeasonResetFactor < 0 || c.SeasonResetFactor > 1 {
+		return fmt.Errorf("season reset factor must be between 0 and 1")
+	}
+
+	if c.SeasonResetDeviation < 0 || c.SeasonResetDeviation > 350 {
+		return fmt.Errorf("season reset deviation must be between 0 and 350")
+	}
+
+	if c.PlacementMatches < 0 {
+		return fmt.Errorf("placement matches must not be negative")
+	}
+
+	if c.DecayCheckInterval <= 0 {
+		return fmt.Errorf("rating decay check interval must be positive")
+	}
+
+	if c.DecayThreshold < 0 {
+		return fmt.Errorf("rating decay threshold must not be negative")
+	}
+
+	if c.DecayAfterDays < 1 {
+		return fmt.Errorf("rating decay must start after at least 1 day")
+	}
+
+	if c.DecayAmount < 0 {
+		return fmt.Errorf("rating decay amount must not be negative")
+	}
+
+	return nil
+}
+
+// IsProvisional проверяет, проходит ли игрок с указанным числом матчей калибровку
+func (c *RatingConfig) IsProvisional(gamesPlayed int) bool {
+	return gamesPlayed < c.PlacementMatches
+}
+
+// IsValidRatingEngine проверяет валидность названия рейтинговой системы
+func IsValidRatingEngine(engine string) bool {
+	switch engine {
+	case RatingEngineElo, RatingEngineGlicko2:
+		return true
+	default:
+		return false
+	}
+}
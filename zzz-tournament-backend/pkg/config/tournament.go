@@ -0,0 +1,98 @@
+// pkg/config/tournament.go
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Правила технической победы при истечении срока матча
+const (
+	WalkoverRuleReady  = "ready"  // Побеждает единственный отметившийся готовым, иначе решает хост
+	WalkoverRuleRating = "rating" // Побеждает игрок с большим рейтингом
+	WalkoverRuleNone   = "none"   // Техническая победа не присуждается, хост решает вручную
+)
+
+// Правила драфта героев при истечении времени хода
+const (
+	DraftTimeoutAutoPick = "auto_pick" // Случайный доступный герой вместо пика, бан пропускается
+	DraftTimeoutSkip     = "skip"      // Ход пропускается
+)
+
+// TournamentConfig содержит настройки проведения турниров
+type TournamentConfig struct {
+	// Сроки матчей
+	MatchDeadline         time.Duration `yaml:"match_deadline" env:"MATCH_DEADLINE" default:"0"` // 0 - без срока
+	MatchDeadlineWarning  time.Duration `yaml:"match_deadline_warning" env:"MATCH_DEADLINE_WARNING" default:"5m"`
+	DeadlineCheckInterval time.Duration `yaml:"deadline_check_interval" env:"DEADLINE_CHECK_INTERVAL" default:"30s"`
+
+	// Правило технической победы по умолчанию
+	WalkoverRule string `yaml:"walkover_rule" env:"WALKOVER_RULE" default:"ready"`
+
+	// Драфт героев
+	DraftTurnDuration time.Duration `yaml:"draft_turn_duration" env:"DRAFT_TURN_DURATION" default:"30s"`
+	DraftTimeoutRule  string        `yaml:"draft_timeout_rule" env:"DRAFT_TIMEOUT_RULE" default:"auto_pick"`
+}
+
+// LoadTournamentConfig загружает конфигурацию турниров
+func LoadTournamentConfig() (*TournamentConfig, error) {
+	config := &TournamentConfig{
+		MatchDeadline:         getEnvDuration("MATCH_DEADLINE", 0),
+		MatchDeadlineWarning:  getEnvDuration("MATCH_DEADLINE_WARNING", 5*time.Minute),
+		DeadlineCheckInterval: getEnvDuration("DEADLINE_CHECK_INTERVAL", 30*time.Second),
+		WalkoverRule:          getEnv("WALKOVER_RULE", WalkoverRuleReady),
+		DraftTurnDuration:     getEnvDuration("DRAFT_TURN_DURATION", 30*time.Second),
+		DraftTimeoutRule:      getEnv("DRAFT_TIMEOUT_RULE", DraftTimeoutAutoPick),
+	}
+
+	return config, nil
+}
+
+// Validate проверяет корректность конфигурации
+func (c *TournamentConfig) Validate() error {
+	if c.MatchDeadline < 0 {
+		return fmt.Errorf("match deadline must not be negative")
+	}
+
+	if c.MatchDeadlineWarning < 0 {
+		return fmt.Errorf("match deadline warning must not be negative")
+	}
+
+	if c.DeadlineCheckInterval <= 0 {
+		return fmt.Errorf("deadline check interval must be positive")
+	}
+
+	if !IsValidWalkoverRule(c.WalkoverRule) {
+		return fmt.Errorf("unknown walkover rule: %s", c.WalkoverRule)
+	}
+
+	if c.DraftTurnDuration < time.Second {
+		return fmt.Errorf("draft turn duration must be at least 1 second")
+	}
+
+	if !IsValidDraftTimeoutRule(c.DraftTimeoutRule) {
+		return fmt.Errorf("unknown draft timeout rule: %s", c.DraftTimeoutRule)
+	}
+
+	return nil
+}
+
+// IsValidWalkoverRule проверяет валидность правила технической победы
+func IsValidWalkoverRule(rule string) bool {
+	switch rule {
+	case WalkoverRuleReady, WalkoverRuleRating, WalkoverRuleNone:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsValidDraftTimeoutRule проверяет валидность правила истечения хода драфта
+func IsValidDraftTimeoutRule(rule string) bool {
+	switch rule {
+	case DraftTimeoutAutoPick, DraftTimeoutSkip:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,93 @@
+// pkg/rating/engine.go
+package rating
+
+import (
+	"math"
+	"time"
+)
+
+// Поддерживаемые рейтинговые системы
+const (
+	EngineElo     = "elo"
+	EngineGlicko2 = "glicko2"
+)
+
+// Начальные параметры рейтинга
+const (
+	DefaultRating     = 1000
+	DefaultDeviation  = 350.0 // Отклонение рейтинга (RD) нового игрока
+	DefaultVolatility = 0.06  // Волатильность нового игрока
+)
+
+// State рейтинговое состояние участника перед матчем или после него
+type State struct {
+	Rating      int           `json:"rating"`
+	Deviation   float64       `json:"rating_deviation"`
+	Volatility  float64       `json:"rating_volatility"`
+	GamesPlayed int           `json:"games_played"`
+	Idle        time.Duration `json:"-"` // Время с последнего рейтингового матча (0 - не играл)
+}
+
+// Engine рейтинговая система, пересчитывающая рейтинги по итогам матча
+type Engine interface {
+	// Name возвращает название системы
+	Name() string
+	// Rate возвращает новые состояния победителя и проигравшего
+	Rate(winner, loser State) (State, State)
+	// CurrentDeviation возвращает отклонение рейтинга с учетом времени без матчей
+	CurrentDeviation(state State) float64
+}
+
+// NewEngine создает рейтинговую систему по названию. idlePeriod - интервал
+// Glicko-2, за каждый такой интервал без матчей отклонение растет.
+// Неизвестное название означает Elo.
+func NewEngine(name string, idlePeriod time.Duration) Engine {
+	if name == EngineGlicko2 {
+		return NewGlicko2(idlePeriod)
+	}
+	return EloEngine{}
+}
+
+// EloEngine рейтинговая система Elo с K-факторами по рейтингу и опыту игрока.
+// Отклонение и волатильность не меняются.
+type EloEngine struct{}
+
+// Name возвращает название системы
+func (EloEngine) Name() string {
+	return EngineElo
+}
+
+// CurrentDeviation возвращает сохраненное отклонение: в Elo оно не меняется
+func (EloEngine) CurrentDeviation(state State) float64 {
+	return state.Deviation
+}
+
+// Rate пересчитывает рейтинги по Elo, K-фактор у каждого игрока свой
+func (EloEngine) Rate(winner, loser State) (State, State) {
+	winnerKFactor := GetKFactor(winner.Rating, winner.GamesPlayed)
+	loserKFactor := GetKFactor(loser.Rating, loser.GamesPlayed)
+
+	newWinnerRating, _ := CalculateRatingChange(winner.Rating, loser.Rating, winnerKFactor)
+	_, newLoserRating := CalculateRatingChange(winner.Rating, loser.Rating, loserKFactor)
+
+	winner.Rating = newWinnerRating
+	winner.GamesPlayed++
+	loser.Rating = newLoserRating
+	loser.GamesPlayed++
+
+	return winner, loser
+}
+
+// ConservativeRating возвращает консервативную оценку рейтинга (рейтинг минус
+// два отклонения): с такой уверенностью игрок играет не хуже этого уровня
+func ConservativeRating(rating int, deviation float64) int {
+	return rating - int(math.Round(2*deviation))
+}
+
+// SoftReset сдвигает рейтинг к среднему: mean + (rating - mean) * factor.
+// Отклонение поднимается до minDeviation, чтобы рейтинг быстрее уточнялся.
+func SoftReset(state State, mean int, factor, minDeviation float64) State {
+	state.Rating = mean + int(math.Round(float64(state.Rating-mean)*factor))
+	state.Deviation = math.Max(state.Deviation, minDeviation)
+	return state
+}
@@ -0,0 +1,168 @@
+// pkg/rating/glicko2.go
+package rating
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// Коэффициент перевода рейтинга в шкалу Glicko-2
+	glicko2Scale = 173.7178
+
+	// Центр шкалы Glicko-2. На результат влияет только разность рейтингов,
+	// поэтому начальный рейтинг игроков может быть любым.
+	glicko2Center = 1500.0
+
+	// Ограничение изменения волатильности за период
+	DefaultGlicko2Tau = 0.5
+
+	// Точность подбора волатильности
+	glicko2Epsilon = 0.000001
+)
+
+// Glicko2 рейтинговая система Glicko-2 в упрощенном варианте: результаты не
+// группируются по рейтинговым периодам, каждый матч пересчитывается сразу как
+// отдельный период с одним соперником. Поэтому серия матчей за короткое время
+// меняет рейтинг иначе, чем пакетный расчет за период. За каждый IdlePeriod
+// без матчей отклонение рейтинга растет, но не выше начального.
+type Glicko2 struct {
+	IdlePeriod time.Duration // Интервал без матчей, за который растет отклонение
+	Tau        float64
+}
+
+// NewGlicko2 создает систему Glicko-2 с заданным интервалом роста отклонения
+func NewGlicko2(idlePeriod time.Duration) *Glicko2 {
+	return &Glicko2{
+		IdlePeriod: idlePeriod,
+		Tau:        DefaultGlicko2Tau,
+	}
+}
+
+// Name возвращает название системы
+func (g *Glicko2) Name() string {
+	return EngineGlicko2
+}
+
+// Rate пересчитывает рейтинги, отклонения и волатильности обоих игроков
+func (g *Glicko2) Rate(winner, loser State) (State, State) {
+	winnerPhi := g.inactivePhi(winner)
+	loserPhi := g.inactivePhi(loser)
+
+	newWinner := g.update(winner, winnerPhi, loser, loserPhi, 1)
+	newLoser := g.update(loser, loserPhi, winner, winnerPhi, 0)
+
+	return newWinner, newLoser
+}
+
+// CurrentDeviation возвращает отклонение рейтинга с учетом времени без матчей.
+// Хранимое отклонение меняется только после матча, поэтому для таблицы лидеров
+// и статистики неактивных игроков используется это значение.
+func (g *Glicko2) CurrentDeviation(state State) float64 {
+	return g.inactivePhi(state) * glicko2Scale
+}
+
+// inactivePhi возвращает отклонение в шкале Glicko-2, увеличенное за
+// интервалы IdlePeriod без матчей
+func (g *Glicko2) inactivePhi(state State) float64 {
+	phi := deviationOrDefault(state.Deviation) / glicko2Scale
+	if g.IdlePeriod <= 0 || state.Idle < g.IdlePeriod {
+		return phi
+	}
+
+	periods := float64(state.Idle / g.IdlePeriod)
+	sigma := volatilityOrDefault(state.Volatility)
+	phi = math.Sqrt(phi*phi + periods*sigma*sigma)
+
+	return math.Min(phi, DefaultDeviation/glicko2Scale)
+}
+
+// update рассчитывает новое состояние игрока по результату матча с одним
+// соперником (score: 1 - победа, 0 - поражение)
+func (g *Glicko2) update(player State, phi float64, opponent State, opponentPhi float64, score float64) State {
+	mu := (float64(player.Rating) - glicko2Center) / glicko2Scale
+	opponentMu := (float64(opponent.Rating) - glicko2Center) / glicko2Scale
+	sigma := volatilityOrDefault(player.Volatility)
+
+	gPhi := 1 / math.Sqrt(1+3*opponentPhi*opponentPhi/(math.Pi*math.Pi))
+	expected := 1 / (1 + math.Exp(-gPhi*(mu-opponentMu)))
+
+	v := 1 / (gPhi * gPhi * expected * (1 - expected))
+	delta := v * gPhi * (score - expected)
+
+	newSigma := g.volatility(phi, sigma, v, delta)
+
+	phiStar := math.Sqrt(phi*phi + newSigma*newSigma)
+	newPhi := 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	newMu := mu + newPhi*newPhi*gPhi*(score-expected)
+
+	newRating := int(math.Round(newMu*glicko2Scale + glicko2Center))
+	if newRating > MaxRating {
+		newRating = MaxRating
+	}
+	if newRating < MinRating {
+		newRating = MinRating
+	}
+
+	player.Rating = newRating
+	player.Deviation = math.Min(newPhi*glicko2Scale, DefaultDeviation)
+	player.Volatility = newSigma
+	player.GamesPlayed++
+
+	return player
+}
+
+// volatility подбирает новую волатильность итерационным методом Illinois
+func (g *Glicko2) volatility(phi, sigma, v, delta float64) float64 {
+	a := math.Log(sigma * sigma)
+	tau := g.Tau
+	if tau <= 0 {
+		tau = DefaultGlicko2Tau
+	}
+
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + v + ex
+		return ex*(delta*delta-d)/(2*d*d) - (x-a)/(tau*tau)
+	}
+
+	A := a
+	var B float64
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*tau) < 0 {
+			k++
+		}
+		B = a - k*tau
+	}
+
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > glicko2Epsilon {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+		B, fB = C, fC
+	}
+
+	return math.Exp(A / 2)
+}
+
+func deviationOrDefault(deviation float64) float64 {
+	if deviation <= 0 {
+		return DefaultDeviation
+	}
+	return deviation
+}
+
+func volatilityOrDefault(volatility float64) float64 {
+	if volatility <= 0 {
+		return DefaultVolatility
+	}
+	return volatility
+}
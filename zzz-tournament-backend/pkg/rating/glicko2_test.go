@@ -0,0 +1,202 @@
+// pkg/rating/glicko2_test.go
+package rating
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+const day = 24 * time.Hour
+
+func TestGlicko2Volatility(t *testing.T) {
+	tests := []struct {
+		name  string
+		phi   float64
+		sigma float64
+		v     float64
+		delta float64
+		want  float64
+	}{
+		{
+			// Пример из статьи Glickman "Example of the Glicko-2 system":
+			// игрок 1500/200/0.06 против 1400/30 (победа), 1550/100 и 1700/300 (поражения)
+			name:  "glickman example",
+			phi:   200 / glicko2Scale,
+			sigma: 0.06,
+			v:     1.7789770897,
+			delta: -0.4839332610,
+			want:  0.0599959843,
+		},
+		{
+			// Неожиданный результат: delta^2 > phi^2 + v, волатильность растет
+			name:  "upset raises volatility",
+			phi:   100 / glicko2Scale,
+			sigma: 0.06,
+			v:     31.8498763485,
+			delta: 29.2695185547,
+			want:  0.0600107504,
+		},
+	}
+
+	g := NewGlicko2(0)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := g.volatility(tt.phi, tt.sigma, tt.v, tt.delta)
+			if math.Abs(got-tt.want) > 1e-6 {
+				t.Errorf("volatility = %.10f, want %.10f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlicko2Rate(t *testing.T) {
+	tests := []struct {
+		name          string
+		winner        State
+		loser         State
+		wantWinner    int
+		wantWinnerRD  float64
+		wantWinnerVol float64
+		wantLoser     int
+		wantLoserRD   float64
+		wantLoserVol  float64
+	}{
+		{
+			// Первый матч из примера Glickman, рассчитанный как отдельный период
+			name:          "glickman player beats first opponent",
+			winner:        State{Rating: 1500, Deviation: 200, Volatility: 0.06},
+			loser:         State{Rating: 1400, Deviation: 30, Volatility: 0.06},
+			wantWinner:    1564,
+			wantWinnerRD:  175.4027,
+			wantWinnerVol: 0.0599987,
+			wantLoser:     1398,
+			wantLoserRD:   31.6702,
+			wantLoserVol:  0.0599991,
+		},
+		{
+			name:          "new players",
+			winner:        State{Rating: DefaultRating, Deviation: DefaultDeviation, Volatility: DefaultVolatility},
+			loser:         State{Rating: DefaultRating, Deviation: DefaultDeviation, Volatility: DefaultVolatility},
+			wantWinner:    1162,
+			wantWinnerRD:  290.3190,
+			wantWinnerVol: 0.0599997,
+			wantLoser:     838,
+			wantLoserRD:   290.3190,
+			wantLoserVol:  0.0599997,
+		},
+		{
+			// Пустые отклонение и волатильность заменяются начальными
+			name:          "missing deviation and volatility",
+			winner:        State{Rating: DefaultRating},
+			loser:         State{Rating: DefaultRating},
+			wantWinner:    1162,
+			wantWinnerRD:  290.3190,
+			wantWinnerVol: 0.0599997,
+			wantLoser:     838,
+			wantLoserRD:   290.3190,
+			wantLoserVol:  0.0599997,
+		},
+		{
+			name:          "upset",
+			winner:        State{Rating: 1200, Deviation: 100, Volatility: 0.06},
+			loser:         State{Rating: 1800, Deviation: 100, Volatility: 0.06},
+			wantWinner:    1253,
+			wantWinnerRD:  100.0174,
+			wantWinnerVol: 0.0600108,
+			wantLoser:     1747,
+			wantLoserRD:   100.0174,
+			wantLoserVol:  0.0600108,
+		},
+	}
+
+	g := NewGlicko2(0)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			winner, loser := g.Rate(tt.winner, tt.loser)
+
+			if winner.Rating != tt.wantWinner || loser.Rating != tt.wantLoser {
+				t.Errorf("ratings = %d/%d, want %d/%d", winner.Rating, loser.Rating, tt.wantWinner, tt.wantLoser)
+			}
+			if math.Abs(winner.Deviation-tt.wantWinnerRD) > 0.001 || math.Abs(loser.Deviation-tt.wantLoserRD) > 0.001 {
+				t.Errorf("deviations = %.4f/%.4f, want %.4f/%.4f", winner.Deviation, loser.Deviation, tt.wantWinnerRD, tt.wantLoserRD)
+			}
+			if math.Abs(winner.Volatility-tt.wantWinnerVol) > 1e-6 || math.Abs(loser.Volatility-tt.wantLoserVol) > 1e-6 {
+				t.Errorf("volatilities = %.7f/%.7f, want %.7f/%.7f", winner.Volatility, loser.Volatility, tt.wantWinnerVol, tt.wantLoserVol)
+			}
+			if winner.GamesPlayed != tt.winner.GamesPlayed+1 || loser.GamesPlayed != tt.loser.GamesPlayed+1 {
+				t.Errorf("games played = %d/%d, want one more", winner.GamesPlayed, loser.GamesPlayed)
+			}
+		})
+	}
+}
+
+func TestGlicko2RateClampsRating(t *testing.T) {
+	g := NewGlicko2(0)
+
+	winner, loser := g.Rate(
+		State{Rating: MaxRating, Deviation: DefaultDeviation},
+		State{Rating: MaxRating, Deviation: DefaultDeviation},
+	)
+	if winner.Rating != MaxRating {
+		t.Errorf("winner rating = %d, want %d", winner.Rating, MaxRating)
+	}
+
+	winner, loser = g.Rate(
+		State{Rating: MinRating, Deviation: DefaultDeviation},
+		State{Rating: MinRating, Deviation: DefaultDeviation},
+	)
+	if loser.Rating != MinRating {
+		t.Errorf("loser rating = %d, want %d", loser.Rating, MinRating)
+	}
+	if winner.Rating <= MinRating {
+		t.Errorf("winner rating = %d, want above %d", winner.Rating, MinRating)
+	}
+}
+
+func TestGlicko2CurrentDeviation(t *testing.T) {
+	tests := []struct {
+		name       string
+		idlePeriod time.Duration
+		state      State
+		want       float64
+	}{
+		{name: "just played", idlePeriod: 30 * day, state: State{Deviation: 50, Volatility: 0.06}, want: 50},
+		{name: "less than a period", idlePeriod: 30 * day, state: State{Deviation: 50, Volatility: 0.06, Idle: 29 * day}, want: 50},
+		{name: "one period", idlePeriod: 30 * day, state: State{Deviation: 50, Volatility: 0.06, Idle: 30 * day}, want: 51.0749},
+		{name: "partial periods round down", idlePeriod: 30 * day, state: State{Deviation: 50, Volatility: 0.06, Idle: 59 * day}, want: 51.0749},
+		{name: "ten periods", idlePeriod: 30 * day, state: State{Deviation: 50, Volatility: 0.06, Idle: 300 * day}, want: 59.8866},
+		{name: "capped at initial deviation", idlePeriod: 30 * day, state: State{Deviation: 340, Volatility: 0.06, Idle: 3000 * day}, want: DefaultDeviation},
+		{name: "growth disabled", state: State{Deviation: 50, Volatility: 0.06, Idle: 3000 * day}, want: 50},
+		{name: "missing deviation", idlePeriod: 30 * day, state: State{}, want: DefaultDeviation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewGlicko2(tt.idlePeriod).CurrentDeviation(tt.state)
+			if math.Abs(got-tt.want) > 0.001 {
+				t.Errorf("deviation = %.4f, want %.4f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGlicko2IdleDeviationAffectsRate(t *testing.T) {
+	g := NewGlicko2(30 * day)
+
+	active := State{Rating: 1500, Deviation: 50, Volatility: 0.06}
+	idle := active
+	idle.Idle = 300 * day
+	opponent := State{Rating: 1500, Deviation: 50, Volatility: 0.06}
+
+	activeWinner, _ := g.Rate(active, opponent)
+	idleWinner, _ := g.Rate(idle, opponent)
+
+	// После перерыва рейтинг менее надежен, поэтому меняется сильнее
+	if idleWinner.Rating <= activeWinner.Rating {
+		t.Errorf("idle winner rating = %d, want above %d", idleWinner.Rating, activeWinner.Rating)
+	}
+	if idleWinner.Deviation <= activeWinner.Deviation {
+		t.Errorf("idle winner deviation = %.4f, want above %.4f", idleWinner.Deviation, activeWinner.Deviation)
+	}
+}
@@ -0,0 +1,149 @@
+// pkg/storage/local.go
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalFilesPath путь, по которому сервер отдает файлы локального хранилища
+const LocalFilesPath = "/api/v1/files/"
+
+// Local хранилище в локальной файловой системе. Файлы отдаются сервером по
+// ссылкам, подписанным HMAC с ограниченным сроком действия.
+type Local struct {
+	root      string
+	publicURL string
+	secret    []byte
+}
+
+// NewLocal создает локальное хранилище в каталоге root
+func NewLocal(root, publicURL, secret string) (*Local, error) {
+	if root == "" {
+		return nil, errors.New("local storage path is required")
+	}
+	if secret == "" {
+		return nil, errors.New("local storage signing secret is required")
+	}
+
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, fmt.Errorf("create storage directory: %w", err)
+	}
+
+	return &Local{
+		root:      root,
+		publicURL: strings.TrimRight(publicURL, "/"),
+		secret:    []byte(secret),
+	}, nil
+}
+
+// Put сохраняет файл. Запись идет во временный файл, чтобы при сбое не
+// остался частично записанный файл.
+func (l *Local) Put(ctx context.Context, key, contentType string, data []byte) error {
+	filename, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0o750); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filename)
+}
+
+// Get открывает файл для чтения
+func (l *Local) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	filename, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+// Delete удаляет файл
+func (l *Local) Delete(ctx context.Context, key string) error {
+	filename, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// SignedURL возвращает ссылку на файл через сервер с подписью и сроком действия
+func (l *Local) SignedURL(key string, ttl time.Duration) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+
+	expires := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", l.sign(key, expires))
+
+	return l.publicURL + LocalFilesPath + key + "?" + query.Encode(), nil
+}
+
+// Verify проверяет подпись и срок действия ссылки на файл
+func (l *Local) Verify(key, expires, signature string) bool {
+	if validateKey(key) != nil {
+		return false
+	}
+
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return false
+	}
+
+	return hmac.Equal([]byte(signature), []byte(l.sign(key, expires)))
+}
+
+// sign подписывает ключ файла и срок действия ссылки
+func (l *Local) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// path возвращает путь к файлу в каталоге хранилища
+func (l *Local) path(key string) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(l.root, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,31 @@
+// pkg/storage/sniff.go
+package storage
+
+import (
+	"bytes"
+	"errors"
+)
+
+// ErrUnsupportedImage содержимое файла не является изображением PNG, JPEG или WebP
+var ErrUnsupportedImage = errors.New("file must be a PNG, JPEG or WebP image")
+
+// ImageType формат изображения
+type ImageType struct {
+	ContentType string
+	Extension   string
+}
+
+// DetectImage определяет формат изображения по сигнатуре содержимого.
+// Расширение имени и заголовок Content-Type клиента не учитываются.
+func DetectImage(data []byte) (ImageType, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return ImageType{ContentType: "image/png", Extension: "png"}, nil
+	case bytes.HasPrefix(data, []byte("\xff\xd8\xff")):
+		return ImageType{ContentType: "image/jpeg", Extension: "jpg"}, nil
+	case len(data) >= 12 && bytes.Equal(data[0:4], []byte("RIFF")) && bytes.Equal(data[8:12], []byte("WEBP")):
+		return ImageType{ContentType: "image/webp", Extension: "webp"}, nil
+	default:
+		return ImageType{}, ErrUnsupportedImage
+	}
+}
@@ -0,0 +1,67 @@
+// pkg/storage/storage.go
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// Драйверы хранилища файлов
+const (
+	DriverLocal = "local" // Локальная файловая система
+	DriverS3    = "s3"    // S3-совместимое хранилище (AWS S3, MinIO, ...)
+)
+
+// ErrNotFound файл не найден в хранилище
+var ErrNotFound = errors.New("file not found")
+
+// Storage хранилище загруженных файлов
+type Storage interface {
+	// Put сохраняет файл под ключом
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	// Get открывает файл для чтения
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete удаляет файл; отсутствие файла ошибкой не считается
+	Delete(ctx context.Context, key string) error
+	// SignedURL возвращает подписанную ссылку на файл, действующую ttl
+	SignedURL(key string, ttl time.Duration) (string, error)
+}
+
+// Options настройки хранилища
+type Options struct {
+	Driver string
+
+	// Локальное хранилище
+	LocalPath string // Каталог для файлов
+	PublicURL string // Адрес сервера, через который отдаются файлы
+	Secret    string // Ключ подписи ссылок
+
+	// S3-совместимое хранилище
+	S3 S3Options
+}
+
+// New создает хранилище выбранного драйвера
+func New(opts Options) (Storage, error) {
+	switch opts.Driver {
+	case DriverLocal, "":
+		return NewLocal(opts.LocalPath, opts.PublicURL, opts.Secret)
+	case DriverS3:
+		return NewS3(opts.S3)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", opts.Driver)
+	}
+}
+
+// validateKey проверяет, что ключ - относительный путь без выхода за пределы хранилища
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") || path.Clean(key) != key ||
+		key == ".." || strings.HasPrefix(key, "../") {
+		return fmt.Errorf("invalid storage key %q", key)
+	}
+	return nil
+}
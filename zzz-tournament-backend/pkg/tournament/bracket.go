@@ -3,7 +3,6 @@ package tournament
 
 import (
 	"errors"
-	"math"
 	"math/rand"
 	"time"
 )
@@ -12,10 +11,31 @@ func init() {
 	rand.Seed(time.Now().UnixNano())
 }
 
+// Форматы турниров
+const (
+	FormatSingleElimination = "single_elimination"
+	FormatDoubleElimination = "double_elimination"
+	FormatRoundRobin        = "round_robin"
+	FormatGroupsPlayoffs    = "groups_playoffs"
+	FormatSwiss             = "swiss"
+	FormatScoreAttack       = "score_attack"
+)
+
+// Сетки, к которым относятся матчи
+const (
+	BracketWinners    = "winners"
+	BracketLosers     = "losers"
+	BracketGrandFinal = "grand_final"
+	BracketGroup      = "group"
+	BracketSwiss      = "swiss"
+	BracketThirdPlace = "third_place"
+)
+
 type Player struct {
 	ID       int    `json:"id"`
 	Username string `json:"username"`
 	Rating   int    `json:"rating"`
+	TeamID   int    `json:"team_id,omitempty"` // Команда, которую представляет игрок-капитан
 }
 
 type Match struct {
@@ -28,14 +48,28 @@ type Match struct {
 	Player2      *Player `json:"player2,omitempty"`
 	WinnerID     *int    `json:"winner_id"`
 	Winner       *Player `json:"winner,omitempty"`
-	Status       string  `json:"status"` // pending, in_progress, finished
+	Status       string  `json:"status"` // pending, in_progress, finished, cancelled
+	Bracket      string  `json:"bracket,omitempty"`
+	Position     int     `json:"position"`
+	NextMatch    *int    `json:"next_match,omitempty"`  // Индекс матча для победителя
+	NextSlot     int     `json:"next_slot"`             // 0 - player1, 1 - player2
+	LoserMatch   *int    `json:"loser_match,omitempty"` // Индекс матча для проигравшего
+	LoserSlot    int     `json:"loser_slot"`
+	Group        int     `json:"group,omitempty"`   // Номер группы (с 1) для группового этапа
+	BestOf       int     `json:"best_of,omitempty"` // Количество игр в серии
 }
 
 type Bracket struct {
-	TournamentID int     `json:"tournament_id"`
-	Rounds       int     `json:"rounds"`
-	Matches      []Match `json:"matches"`
-	Players      []Player `json:"players"`
+	TournamentID int           `json:"tournament_id"`
+	Format       string        `json:"format"`
+	Rounds       int           `json:"rounds"`
+	Matches      []Match       `json:"matches"`
+	Players      []Player      `json:"players"`
+	Groups       [][]Player    `json:"groups,omitempty"`    // Составы групп
+	Advance      int           `json:"advance,omitempty"`   // Выходят в плей-офф из каждой группы
+	Series       *SeriesFormat `json:"series,omitempty"`    // Количество игр в матчах
+	ThirdPlace   bool          `json:"third_place"`         // Матч за третье место в плей-офф
+	TeamMode     bool          `json:"team_mode,omitempty"` // Участники сетки - команды
 }
 
 // GenerateBracket создает турнирную сетку на выбывание
@@ -51,54 +85,85 @@ func GenerateBracket(players []Player) (*Bracket, error) {
 	// Перемешиваем участников для рандомности
 	shuffled := make([]Player, len(players))
 	copy(shuffled, players)
-	
+
 	for i := range shuffled {
 		j := rand.Intn(i + 1)
 		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
 	}
 
-	// Рассчитываем количество раундов
-	rounds := int(math.Ceil(math.Log2(float64(len(shuffled)))))
-	
+	return buildSingleElimination(shuffled), nil
+}
+
+// buildSingleElimination строит сетку на выбывание по порядку посева (первый -
+// сильнейший). Каждый матч ссылается на матч, куда переходит победитель.
+// Игроки без соперника в первом раунде получают bye: такой матч сразу
+// завершается, а игрок переходит в следующий раунд.
+func buildSingleElimination(ordered []Player) *Bracket {
+	size := 1
+	rounds := 0
+	for size < len(ordered) {
+		size *= 2
+		rounds++
+	}
+
 	bracket := &Bracket{
+		Format:  FormatSingleElimination,
 		Rounds:  rounds,
-		Players: shuffled,
+		Players: ordered,
 		Matches: []Match{},
 	}
 
-	// Генерируем первый раунд
-	round := 1
-	currentPlayers := shuffled
-	
-	for len(currentPlayers) > 1 {
-		var nextRoundPlayers []Player
-		
-		for i := 0; i < len(currentPlayers); i += 2 {
-			if i+1 < len(currentPlayers) {
-				// Обычный матч между двумя игроками
-				match := Match{
-					Round:     round,
-					Player1ID: currentPlayers[i].ID,
-					Player2ID: currentPlayers[i+1].ID,
-					Player1:   &currentPlayers[i],
-					Player2:   &currentPlayers[i+1],
-					Status:    "pending",
-				}
-				bracket.Matches = append(bracket.Matches, match)
-				
-				// Placeholder для следующего раунда
-				nextRoundPlayers = append(nextRoundPlayers, Player{ID: -1})
-			} else {
-				// Нечетное количество - игрок проходит дальше автоматически (bye)
-				nextRoundPlayers = append(nextRoundPlayers, currentPlayers[i])
-			}
+	indexes := make([][]int, rounds+1)
+	for round := 1; round <= rounds; round++ {
+		count := size >> round
+		indexes[round] = make([]int, count)
+		for pos := 0; pos < count; pos++ {
+			bracket.Matches = append(bracket.Matches, Match{
+				Round:    round,
+				Status:   "pending",
+				Bracket:  BracketWinners,
+				Position: pos,
+			})
+			indexes[round][pos] = len(bracket.Matches) - 1
+		}
+	}
+
+	for round := 1; round < rounds; round++ {
+		for pos, idx := range indexes[round] {
+			bracket.linkWinner(idx, indexes[round+1][pos/2], pos%2)
 		}
-		
-		currentPlayers = nextRoundPlayers
-		round++
 	}
 
-	return bracket, nil
+	for pos, seed := range seedOrder(size) {
+		if seed <= len(ordered) {
+			bracket.setSlot(indexes[1][pos/2], pos%2, &ordered[seed-1])
+		}
+	}
+
+	// Размер сетки - степень двойки, поэтому в каждом матче первого раунда
+	// есть хотя бы один игрок
+	for _, idx := range indexes[1] {
+		match := &bracket.Matches[idx]
+		if match.Player1 != nil && match.Player2 != nil {
+			continue
+		}
+
+		player := match.Player1
+		if player == nil {
+			player = match.Player2
+		}
+
+		winnerID := player.ID
+		match.WinnerID = &winnerID
+		match.Winner = player
+		match.Status = "finished"
+
+		if match.NextMatch != nil {
+			bracket.setSlot(*match.NextMatch, match.NextSlot, player)
+		}
+	}
+
+	return bracket
 }
 
 // GetNextMatches возвращает матчи, готовые к проведению
@@ -138,24 +203,47 @@ func (b *Bracket) AdvanceMatch(matchIndex int, winnerID int) error {
 		match.Winner = match.Player2
 	}
 
-	// Находим следующий матч в следующем раунде
-	nextRound := match.Round + 1
-	
-	for i := range b.Matches {
-		if b.Matches[i].Round == nextRound && b.Matches[i].Status == "pending" {
-			// Ищем пустое место в следующем матче
-			if b.Matches[i].Player1ID == -1 {
-				b.Matches[i].Player1ID = winnerID
-				b.Matches[i].Player1 = match.Winner
-				break
-			} else if b.Matches[i].Player2ID == -1 {
-				b.Matches[i].Player2ID = winnerID
-				b.Matches[i].Player2 = match.Winner
-				break
+	// Групповой этап: после последнего матча групп формируем плей-офф
+	if match.Bracket == BracketGroup {
+		if b.Format == FormatGroupsPlayoffs && b.IsGroupStageFinished() && !b.HasPlayoffs() {
+			if _, err := b.AppendPlayoffs(b.GroupStandings(b.Matches)); err != nil {
+				return err
 			}
 		}
+		return nil
 	}
-	
+
+	// Швейцарская система: следующий тур создается после завершения текущего
+	if match.Bracket == BracketSwiss {
+		if b.isSwissRoundFinished() && CurrentSwissRound(b.Matches) < b.Rounds {
+			_, err := b.AppendSwissRound()
+			return err
+		}
+		return nil
+	}
+
+	// Переходы заданы явными ссылками
+	loser := match.Player1
+	if winnerID == match.Player1ID {
+		loser = match.Player2
+	}
+
+	if match.Bracket == BracketGrandFinal {
+		if match.Round == 1 && match.NextMatch != nil {
+			b.advanceGrandFinal(match)
+		}
+		return nil
+	}
+
+	if match.NextMatch != nil {
+		b.setSlot(*match.NextMatch, match.NextSlot, match.Winner)
+		b.advanceBye(*match.NextMatch)
+	}
+	if match.LoserMatch != nil && loser != nil {
+		b.setSlot(*match.LoserMatch, match.LoserSlot, loser)
+		b.advanceBye(*match.LoserMatch)
+	}
+
 	return nil
 }
 
@@ -164,23 +252,42 @@ func (b *Bracket) IsTournamentFinished() (bool, *Player) {
 	if len(b.Matches) == 0 {
 		return false, nil
 	}
+
+	switch b.Format {
+	case FormatDoubleElimination:
+		return b.isDoubleEliminationFinished()
+	case FormatSwiss:
+		return b.isSwissFinished()
+	case FormatRoundRobin:
+		if !b.IsGroupStageFinished() {
+			return false, nil
+		}
+		table := CalculateStandings(b.Players, b.Matches)
+		return true, &table[0].Player
+	case FormatGroupsPlayoffs:
+		if !b.HasPlayoffs() {
+			return false, nil
+		}
+	}
 	
-	// Находим финальный матч (максимальный раунд)
-	maxRound := 0
+	// Находим финальный матч: матч верхней сетки без следующего матча
 	var finalMatch *Match
-	
+
 	for i := range b.Matches {
-		if b.Matches[i].Round > maxRound {
-			maxRound = b.Matches[i].Round
-			finalMatch = &b.Matches[i]
+		m := &b.Matches[i]
+		if m.Bracket == BracketThirdPlace && m.Status != "finished" {
+			return false, nil
+		}
+		if m.Bracket == BracketWinners && m.NextMatch == nil {
+			finalMatch = m
 		}
 	}
-	
+
 	// Проверяем, завершен ли финальный матч
 	if finalMatch != nil && finalMatch.Status == "finished" && finalMatch.WinnerID != nil {
 		return true, finalMatch.Winner
 	}
-	
+
 	return false, nil
 }
 
@@ -261,26 +368,15 @@ func GenerateSeededBracket(players []Player) (*Bracket, error) {
 		return nil, errors.New("need at least 2 players for tournament")
 	}
 
+	if len(players) > 64 {
+		return nil, errors.New("maximum 64 players allowed")
+	}
+
 	// Сортируем игроков по рейтингу (по убыванию)
 	sorted := make([]Player, len(players))
 	copy(sorted, players)
-	
-	for i := 0; i < len(sorted)-1; i++ {
-		for j := i + 1; j < len(sorted); j++ {
-			if sorted[i].Rating < sorted[j].Rating {
-				sorted[i], sorted[j] = sorted[j], sorted[i]
-			}
-		}
-	}
+	sortByRating(sorted)
 
-	// Создаем посевную сетку (1 vs последний, 2 vs предпоследний и т.д.)
-	seeded := make([]Player, len(sorted))
-	for i := 0; i < len(sorted); i += 2 {
-		seeded[i] = sorted[i/2]
-		if i+1 < len(sorted) {
-			seeded[i+1] = sorted[len(sorted)-1-i/2]
-		}
-	}
-
-	return GenerateBracket(seeded)
-}
\ No newline at end of file
+	// Посев расставляет игроков так, что 1 и 2 встречаются только в финале
+	return buildSingleElimination(sorted), nil
+}
@@ -0,0 +1,330 @@
+// pkg/tournament/double_elimination.go
+package tournament
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+)
+
+// DoubleEliminationOptions настройки генерации сетки double elimination
+type DoubleEliminationOptions struct {
+	Seeded       bool // Посев по рейтингу вместо случайной жеребьевки
+	BracketReset bool // Повторный гранд-финал, если победил игрок из нижней сетки
+}
+
+// GenerateDoubleEliminationBracket создает сетку на выбывание после двух поражений
+func GenerateDoubleEliminationBracket(players []Player, opts DoubleEliminationOptions) (*Bracket, error) {
+	if len(players) < 2 {
+		return nil, errors.New("need at least 2 players for tournament")
+	}
+
+	if len(players) > 64 {
+		return nil, errors.New("maximum 64 players allowed")
+	}
+
+	ordered := make([]Player, len(players))
+	copy(ordered, players)
+
+	if opts.Seeded {
+		sortByRating(ordered)
+	} else {
+		for i := range ordered {
+			j := rand.Intn(i + 1)
+			ordered[i], ordered[j] = ordered[j], ordered[i]
+		}
+	}
+
+	// Размер сетки - ближайшая степень двойки, недостающие места становятся bye
+	size := 1
+	rounds := 0
+	for size < len(ordered) {
+		size *= 2
+		rounds++
+	}
+
+	bracket := &Bracket{
+		Format:  FormatDoubleElimination,
+		Rounds:  rounds,
+		Players: ordered,
+		Matches: []Match{},
+	}
+
+	addMatch := func(bracketName string, round, position int) int {
+		bracket.Matches = append(bracket.Matches, Match{
+			Bracket:  bracketName,
+			Round:    round,
+			Position: position,
+			Status:   "pending",
+		})
+		return len(bracket.Matches) - 1
+	}
+
+	// Верхняя сетка
+	winners := make([][]int, rounds+1)
+	for round := 1; round <= rounds; round++ {
+		count := size >> round
+		winners[round] = make([]int, count)
+		for pos := 0; pos < count; pos++ {
+			winners[round][pos] = addMatch(BracketWinners, round, pos)
+		}
+	}
+
+	for round := 1; round < rounds; round++ {
+		for pos, idx := range winners[round] {
+			bracket.linkWinner(idx, winners[round+1][pos/2], pos%2)
+		}
+	}
+
+	// Нижняя сетка: 2*(rounds-1) раундов
+	loserRounds := 2 * (rounds - 1)
+	losers := make([][]int, loserRounds+1)
+	for round := 1; round <= loserRounds; round++ {
+		var count int
+		if round%2 == 0 {
+			count = size >> (round/2 + 1)
+		} else {
+			count = size >> ((round+1)/2 + 1)
+		}
+		losers[round] = make([]int, count)
+		for pos := 0; pos < count; pos++ {
+			losers[round][pos] = addMatch(BracketLosers, round, pos)
+		}
+	}
+
+	if loserRounds > 0 {
+		// Проигравшие первого раунда верхней сетки встречаются между собой
+		for pos, idx := range winners[1] {
+			bracket.linkLoser(idx, losers[1][pos/2], pos%2)
+		}
+
+		for round := 1; round <= loserRounds; round++ {
+			if round%2 == 1 {
+				// Победители нечетного раунда ждут проигравших из верхней сетки
+				for pos, idx := range losers[round] {
+					bracket.linkWinner(idx, losers[round+1][pos], 0)
+				}
+				continue
+			}
+
+			// Проигравшие верхней сетки заходят в четный раунд, порядок
+			// чередуется, чтобы избежать повторных встреч
+			wbRound := round/2 + 1
+			count := len(winners[wbRound])
+			for pos, idx := range winners[wbRound] {
+				target := pos
+				if (round/2)%2 == 1 {
+					target = count - 1 - pos
+				}
+				bracket.linkLoser(idx, losers[round][target], 1)
+			}
+
+			if round < loserRounds {
+				for pos, idx := range losers[round] {
+					bracket.linkWinner(idx, losers[round+1][pos/2], pos%2)
+				}
+			}
+		}
+	}
+
+	// Гранд-финал
+	grandFinal := addMatch(BracketGrandFinal, 1, 0)
+	bracket.linkWinner(winners[rounds][0], grandFinal, 0)
+	if loserRounds > 0 {
+		bracket.linkWinner(losers[loserRounds][0], grandFinal, 1)
+	} else {
+		bracket.linkLoser(winners[rounds][0], grandFinal, 1)
+	}
+
+	if opts.BracketReset {
+		reset := addMatch(BracketGrandFinal, 2, 0)
+		bracket.linkWinner(grandFinal, reset, 0)
+	}
+
+	// Расставляем игроков по посеву
+	for pos, seed := range seedOrder(size) {
+		if seed <= len(ordered) {
+			bracket.setSlot(winners[1][pos/2], pos%2, &ordered[seed-1])
+		}
+	}
+
+	// Матч первого раунда без соперника (bye), как в buildSingleElimination,
+	// сразу завершается и игрок переходит дальше. Проигравшего в нем нет,
+	// поэтому слот нижней сетки, куда он попал бы, остается пустым.
+	for _, idx := range winners[1] {
+		match := &bracket.Matches[idx]
+		if match.Player1 != nil && match.Player2 != nil {
+			continue
+		}
+
+		player := match.Player1
+		if player == nil {
+			player = match.Player2
+		}
+
+		winnerID := player.ID
+		match.WinnerID = &winnerID
+		match.Winner = player
+		match.Status = "finished"
+		match.LoserMatch = nil
+		match.LoserSlot = 0
+
+		if match.NextMatch != nil {
+			bracket.setSlot(*match.NextMatch, match.NextSlot, player)
+		}
+	}
+
+	// Матчи нижней сетки, в которые не попадет ни один игрок, отменяются.
+	// Матч с одним пустым слотом проходится без игры, когда придет второй игрок
+	// (см. advanceBye).
+	for round := 1; round <= loserRounds; round++ {
+		for _, idx := range losers[round] {
+			if bracket.isFed(idx, 0) || bracket.isFed(idx, 1) {
+				continue
+			}
+			match := &bracket.Matches[idx]
+			match.Status = "cancelled"
+			match.NextMatch = nil
+			match.NextSlot = 0
+		}
+	}
+
+	return bracket, nil
+}
+
+// seedOrder возвращает порядок номеров посева в первом раунде (1 vs N, 2 vs N-1 ...)
+func seedOrder(size int) []int {
+	order := []int{1}
+	for len(order) < size {
+		next := make([]int, 0, len(order)*2)
+		total := len(order)*2 + 1
+		for _, seed := range order {
+			next = append(next, seed, total-seed)
+		}
+		order = next
+	}
+	return order
+}
+
+// sortByRating сортирует игроков по рейтингу по убыванию. Игроки с равным
+// рейтингом сохраняют исходный порядок (порядок входа в комнату).
+func sortByRating(players []Player) {
+	sort.SliceStable(players, func(i, j int) bool {
+		return players[i].Rating > players[j].Rating
+	})
+}
+
+// linkWinner направляет победителя матча from в слот матча to
+func (b *Bracket) linkWinner(from, to, slot int) {
+	next := to
+	b.Matches[from].NextMatch = &next
+	b.Matches[from].NextSlot = slot
+}
+
+// linkLoser направляет проигравшего матча from в слот матча to
+func (b *Bracket) linkLoser(from, to, slot int) {
+	next := to
+	b.Matches[from].LoserMatch = &next
+	b.Matches[from].LoserSlot = slot
+}
+
+// setSlot ставит игрока в слот матча
+func (b *Bracket) setSlot(matchIndex, slot int, player *Player) {
+	match := &b.Matches[matchIndex]
+	if slot == 0 {
+		match.Player1ID = player.ID
+		match.Player1 = player
+	} else {
+		match.Player2ID = player.ID
+		match.Player2 = player
+	}
+}
+
+// isFed проверяет, ведет ли в слот матча ссылка из другого матча. Пустой слот
+// без ссылки никогда не будет занят.
+func (b *Bracket) isFed(matchIndex, slot int) bool {
+	for i := range b.Matches {
+		m := &b.Matches[i]
+		if m.NextMatch != nil && *m.NextMatch == matchIndex && m.NextSlot == slot {
+			return true
+		}
+		if m.LoserMatch != nil && *m.LoserMatch == matchIndex && m.LoserSlot == slot {
+			return true
+		}
+	}
+	return false
+}
+
+// advanceBye завершает ожидающий матч, второй слот которого никогда не будет
+// занят (bye в нижней сетке), и переводит единственного игрока дальше по ссылке
+func (b *Bracket) advanceBye(matchIndex int) {
+	match := &b.Matches[matchIndex]
+	if match.Status != "pending" || match.Bracket == BracketGrandFinal {
+		return
+	}
+
+	var player *Player
+	switch {
+	case match.Player1ID > 0 && match.Player2ID <= 0 && !b.isFed(matchIndex, 1):
+		player = match.Player1
+	case match.Player2ID > 0 && match.Player1ID <= 0 && !b.isFed(matchIndex, 0):
+		player = match.Player2
+	default:
+		return
+	}
+
+	winnerID := player.ID
+	match.WinnerID = &winnerID
+	match.Winner = player
+	match.Status = "finished"
+
+	if match.NextMatch != nil {
+		b.setSlot(*match.NextMatch, match.NextSlot, player)
+		b.advanceBye(*match.NextMatch)
+	}
+}
+
+// FindMatch возвращает индекс матча по сетке, раунду и позиции или -1
+func (b *Bracket) FindMatch(bracketName string, round, position int) int {
+	for i := range b.Matches {
+		m := &b.Matches[i]
+		if m.Bracket == bracketName && m.Round == round && m.Position == position {
+			return i
+		}
+	}
+	return -1
+}
+
+// advanceGrandFinal обрабатывает результат первого гранд-финала.
+// Если победил игрок верхней сетки, повторный финал не нужен.
+func (b *Bracket) advanceGrandFinal(match *Match) {
+	reset := &b.Matches[*match.NextMatch]
+
+	if *match.WinnerID == match.Player1ID {
+		reset.Status = "cancelled"
+		return
+	}
+
+	b.setSlot(*match.NextMatch, 0, match.Player1)
+	b.setSlot(*match.NextMatch, 1, match.Player2)
+}
+
+// isDoubleEliminationFinished проверяет, сыгран ли решающий гранд-финал
+func (b *Bracket) isDoubleEliminationFinished() (bool, *Player) {
+	var decider *Match
+	for i := range b.Matches {
+		m := &b.Matches[i]
+		if m.Bracket != BracketGrandFinal || m.Status == "cancelled" {
+			continue
+		}
+		if decider == nil || m.Round > decider.Round {
+			decider = m
+		}
+	}
+
+	if decider != nil && decider.Status == "finished" && decider.WinnerID != nil {
+		return true, decider.Winner
+	}
+
+	return false, nil
+}
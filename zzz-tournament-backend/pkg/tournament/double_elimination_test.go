@@ -0,0 +1,268 @@
+// pkg/tournament/double_elimination_test.go
+package tournament
+
+import "testing"
+
+// lowerRated побеждает игрок с меньшим рейтингом
+func lowerRated(match Match) int {
+	if match.Player2.Rating < match.Player1.Rating {
+		return match.Player2ID
+	}
+	return match.Player1ID
+}
+
+// seededDoubleElimination строит сетку с посевом по рейтингу
+func seededDoubleElimination(t *testing.T, players int, reset bool) *Bracket {
+	t.Helper()
+
+	bracket, err := GenerateDoubleEliminationBracket(testPlayers(players),
+		DoubleEliminationOptions{Seeded: true, BracketReset: reset})
+	if err != nil {
+		t.Fatalf("%d players: %v", players, err)
+	}
+	return bracket
+}
+
+func TestGenerateDoubleEliminationValidation(t *testing.T) {
+	tests := []struct {
+		players int
+		wantErr bool
+	}{
+		{players: 0, wantErr: true},
+		{players: 1, wantErr: true},
+		{players: 2},
+		{players: 64},
+		{players: 65, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		_, err := GenerateDoubleEliminationBracket(testPlayers(tt.players), DoubleEliminationOptions{})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%d players: err = %v, wantErr %v", tt.players, err, tt.wantErr)
+		}
+	}
+}
+
+func TestDoubleEliminationStructure(t *testing.T) {
+	tests := []struct {
+		players       int
+		wantWinners   int
+		wantLosers    int
+		wantByes      int
+		wantCancelled int
+	}{
+		{players: 2, wantWinners: 1, wantLosers: 0},
+		{players: 3, wantWinners: 3, wantLosers: 2, wantByes: 1},
+		{players: 4, wantWinners: 3, wantLosers: 2},
+		{players: 5, wantWinners: 7, wantLosers: 6, wantByes: 3, wantCancelled: 1},
+		{players: 6, wantWinners: 7, wantLosers: 6, wantByes: 2},
+		{players: 7, wantWinners: 7, wantLosers: 6, wantByes: 1},
+		{players: 8, wantWinners: 7, wantLosers: 6},
+		{players: 9, wantWinners: 15, wantLosers: 14, wantByes: 7, wantCancelled: 3},
+		{players: 12, wantWinners: 15, wantLosers: 14, wantByes: 4},
+	}
+
+	for _, tt := range tests {
+		bracket := seededDoubleElimination(t, tt.players, false)
+
+		winners, losers, grandFinals, byes, cancelled := 0, 0, 0, 0, 0
+		seen := make(map[int]bool)
+		for i, match := range bracket.Matches {
+			switch match.Bracket {
+			case BracketWinners:
+				winners++
+			case BracketLosers:
+				losers++
+			case BracketGrandFinal:
+				grandFinals++
+			}
+
+			if match.Status == "cancelled" {
+				cancelled++
+				if match.NextMatch != nil {
+					t.Errorf("%d players: cancelled match %d still links forward", tt.players, i)
+				}
+			}
+
+			if match.Bracket != BracketWinners || match.Round != 1 {
+				continue
+			}
+
+			for _, id := range []int{match.Player1ID, match.Player2ID} {
+				if id > 0 {
+					if seen[id] {
+						t.Errorf("%d players: player %d seeded twice", tt.players, id)
+					}
+					seen[id] = true
+				}
+			}
+
+			if match.Player1ID > 0 && match.Player2ID > 0 {
+				continue
+			}
+			byes++
+			if match.Status != "finished" || match.WinnerID == nil {
+				t.Errorf("%d players: bye match %d is not finished", tt.players, i)
+			}
+			if match.LoserMatch != nil {
+				t.Errorf("%d players: bye match %d sends a loser down", tt.players, i)
+			}
+			if next := bracket.Matches[*match.NextMatch]; next.Player1ID != *match.WinnerID && next.Player2ID != *match.WinnerID {
+				t.Errorf("%d players: bye winner %d did not advance", tt.players, *match.WinnerID)
+			}
+		}
+
+		if winners != tt.wantWinners || losers != tt.wantLosers || grandFinals != 1 {
+			t.Errorf("%d players: winners/losers/grand final = %d/%d/%d, want %d/%d/1",
+				tt.players, winners, losers, grandFinals, tt.wantWinners, tt.wantLosers)
+		}
+		if byes != tt.wantByes {
+			t.Errorf("%d players: byes = %d, want %d", tt.players, byes, tt.wantByes)
+		}
+		if cancelled != tt.wantCancelled {
+			t.Errorf("%d players: cancelled = %d, want %d", tt.players, cancelled, tt.wantCancelled)
+		}
+		if len(seen) != tt.players {
+			t.Errorf("%d players: %d players seeded", tt.players, len(seen))
+		}
+	}
+}
+
+func TestDoubleEliminationLosersBye(t *testing.T) {
+	// 3 игрока: 1 проходит первый раунд без игры, поэтому проигравший 2-3
+	// проходит первый раунд нижней сетки без соперника
+	bracket := seededDoubleElimination(t, 3, false)
+
+	semi := bracket.FindMatch(BracketWinners, 1, 1)
+	if err := bracket.AdvanceMatch(semi, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	lb1 := bracket.Matches[bracket.FindMatch(BracketLosers, 1, 0)]
+	if lb1.Status != "finished" || lb1.WinnerID == nil || *lb1.WinnerID != 3 {
+		t.Fatalf("losers round 1 = %+v, want automatic win for 3", lb1)
+	}
+
+	lb2 := bracket.Matches[bracket.FindMatch(BracketLosers, 2, 0)]
+	if lb2.Player1ID != 3 || lb2.Player2ID != 0 || lb2.Status != "pending" {
+		t.Fatalf("losers round 2 = %+v, want 3 waiting for winners final loser", lb2)
+	}
+
+	final := bracket.FindMatch(BracketWinners, 2, 0)
+	if err := bracket.AdvanceMatch(final, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	lb2 = bracket.Matches[bracket.FindMatch(BracketLosers, 2, 0)]
+	if lb2.Player1ID != 3 || lb2.Player2ID != 2 {
+		t.Errorf("losers round 2 players = %d vs %d, want 3 vs 2", lb2.Player1ID, lb2.Player2ID)
+	}
+}
+
+func TestDoubleEliminationPlaysToCompletion(t *testing.T) {
+	strategies := []struct {
+		name   string
+		winner func(Match) int
+	}{
+		{name: "favourites", winner: higherRated},
+		{name: "upsets", winner: lowerRated},
+	}
+
+	for _, strategy := range strategies {
+		for _, players := range []int{2, 3, 4, 5, 6, 7, 9, 12, 16, 17, 33} {
+			bracket := seededDoubleElimination(t, players, true)
+			playBracket(t, bracket, strategy.winner)
+
+			done, champion := bracket.IsTournamentFinished()
+			if !done || champion == nil {
+				t.Fatalf("%s, %d players: tournament not finished", strategy.name, players)
+			}
+			if strategy.name == "favourites" && champion.ID != 1 {
+				t.Errorf("%s, %d players: champion = %d, want 1", strategy.name, players, champion.ID)
+			}
+
+			losses := make(map[int]int)
+			for i, match := range bracket.Matches {
+				if match.Status != "finished" && match.Status != "cancelled" {
+					t.Errorf("%s, %d players: match %d left %s", strategy.name, players, i, match.Status)
+				}
+				if match.Status != "finished" || match.Player1ID <= 0 || match.Player2ID <= 0 {
+					continue
+				}
+				loser := match.Player1ID
+				if *match.WinnerID == match.Player1ID {
+					loser = match.Player2ID
+				}
+				losses[loser]++
+			}
+
+			// С повторным финалом выбывают все, кроме чемпиона, ровно после двух поражений
+			for id := 1; id <= players; id++ {
+				want := 2
+				if id == champion.ID {
+					want = losses[id]
+					if want > 1 {
+						t.Errorf("%s, %d players: champion lost %d times", strategy.name, players, want)
+					}
+				}
+				if losses[id] != want {
+					t.Errorf("%s, %d players: player %d lost %d times, want %d", strategy.name, players, id, losses[id], want)
+				}
+			}
+		}
+	}
+}
+
+func TestDoubleEliminationGrandFinal(t *testing.T) {
+	tests := []struct {
+		name         string
+		reset        bool
+		finalWinner  int
+		resetWinner  int
+		wantChampion int
+	}{
+		{name: "upper bracket winner takes final", reset: true, finalWinner: 1, wantChampion: 1},
+		{name: "lower bracket winner forces reset", reset: true, finalWinner: 2, resetWinner: 1, wantChampion: 1},
+		{name: "lower bracket winner takes reset", reset: true, finalWinner: 2, resetWinner: 2, wantChampion: 2},
+		{name: "no reset", finalWinner: 2, wantChampion: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 2 игрока: проигравший единственного матча верхней сетки сразу идет в гранд-финал
+			bracket := seededDoubleElimination(t, 2, tt.reset)
+
+			if err := bracket.AdvanceMatch(bracket.FindMatch(BracketWinners, 1, 0), 1); err != nil {
+				t.Fatal(err)
+			}
+
+			final := bracket.FindMatch(BracketGrandFinal, 1, 0)
+			if m := bracket.Matches[final]; m.Player1ID != 1 || m.Player2ID != 2 {
+				t.Fatalf("grand final = %d vs %d, want 1 vs 2", m.Player1ID, m.Player2ID)
+			}
+			if err := bracket.AdvanceMatch(final, tt.finalWinner); err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.resetWinner > 0 {
+				if done, _ := bracket.IsTournamentFinished(); done {
+					t.Fatal("tournament finished before bracket reset")
+				}
+				if err := bracket.AdvanceMatch(bracket.FindMatch(BracketGrandFinal, 2, 0), tt.resetWinner); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			done, champion := bracket.IsTournamentFinished()
+			if !done || champion == nil || champion.ID != tt.wantChampion {
+				t.Fatalf("finished = %v, champion = %v, want %d", done, champion, tt.wantChampion)
+			}
+
+			if tt.reset && tt.resetWinner == 0 {
+				if m := bracket.Matches[bracket.FindMatch(BracketGrandFinal, 2, 0)]; m.Status != "cancelled" {
+					t.Errorf("bracket reset status = %s, want cancelled", m.Status)
+				}
+			}
+		})
+	}
+}
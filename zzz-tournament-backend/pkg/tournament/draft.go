@@ -0,0 +1,71 @@
+// pkg/tournament/draft.go
+package tournament
+
+import "errors"
+
+// Ограничения драфта героев
+const (
+	MaxDraftBans  = 5
+	MaxDraftPicks = 5
+)
+
+// Действия хода драфта
+const (
+	DraftActionBan  = "ban"
+	DraftActionPick = "pick"
+)
+
+// DraftFormat количество банов и пиков каждого игрока в драфте героев
+type DraftFormat struct {
+	Bans  int `json:"bans"`
+	Picks int `json:"picks"`
+}
+
+// DraftTurn ход драфта
+type DraftTurn struct {
+	Number int    `json:"number"` // Номер хода, начиная с 1
+	Action string `json:"action"` // ban, pick
+	Slot   int    `json:"slot"`   // 0 - player1, 1 - player2
+}
+
+// Enabled проверяет, проводится ли драфт
+func (d DraftFormat) Enabled() bool {
+	return d.Picks > 0
+}
+
+// Validate проверяет количество банов и пиков
+func (d DraftFormat) Validate() error {
+	if d.Bans < 0 || d.Bans > MaxDraftBans {
+		return errors.New("draft bans must be between 0 and 5")
+	}
+	if d.Picks < 0 || d.Picks > MaxDraftPicks {
+		return errors.New("draft picks must be between 0 and 5")
+	}
+	if d.Bans > 0 && d.Picks == 0 {
+		return errors.New("draft with bans requires picks")
+	}
+	return nil
+}
+
+// HeroesRequired возвращает количество героев, необходимое для драфта
+func (d DraftFormat) HeroesRequired() int {
+	return 2 * (d.Bans + d.Picks)
+}
+
+// Sequence возвращает порядок ходов драфта: сначала игроки поочередно банят,
+// затем пикают "змейкой" (1-2-2-1-1-2...), чтобы второй игрок получил два
+// пика подряд в ответ на первый пик соперника
+func (d DraftFormat) Sequence() []DraftTurn {
+	turns := make([]DraftTurn, 0, d.HeroesRequired())
+
+	for i := 0; i < 2*d.Bans; i++ {
+		turns = append(turns, DraftTurn{Number: len(turns) + 1, Action: DraftActionBan, Slot: i % 2})
+	}
+
+	for i := 0; i < 2*d.Picks; i++ {
+		slot := ((i + 1) / 2) % 2
+		turns = append(turns, DraftTurn{Number: len(turns) + 1, Action: DraftActionPick, Slot: slot})
+	}
+
+	return turns
+}
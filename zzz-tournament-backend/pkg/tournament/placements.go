@@ -0,0 +1,180 @@
+// pkg/tournament/placements.go
+package tournament
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+// Placement итоговое место игрока в турнире. Игроки, выбывшие на одной стадии,
+// делят места: например, проигравшие в четвертьфинале занимают 5-8 места.
+type Placement struct {
+	PlayerID int `json:"player_id"`
+	Place    int `json:"place"`    // Лучшее место в диапазоне
+	PlaceTo  int `json:"place_to"` // Худшее место в диапазоне
+}
+
+// AddThirdPlaceMatch добавляет матч за третье место между проигравшими в полуфиналах
+func (b *Bracket) AddThirdPlaceMatch() error {
+	final := -1
+	for i := range b.Matches {
+		m := &b.Matches[i]
+		if m.Bracket == BracketThirdPlace {
+			return errors.New("third place match already exists")
+		}
+		if m.Bracket == BracketWinners && m.NextMatch == nil {
+			final = i
+		}
+	}
+
+	if final < 0 {
+		return errors.New("final match not found")
+	}
+
+	var semifinals []int
+	for i := range b.Matches {
+		m := &b.Matches[i]
+		if m.Bracket != BracketWinners || m.NextMatch == nil || *m.NextMatch != final {
+			continue
+		}
+		// Полуфинал с bye не даст проигравшего
+		if m.Status == "finished" {
+			return errors.New("third place match needs at least 4 players")
+		}
+		semifinals = append(semifinals, i)
+	}
+
+	if len(semifinals) != 2 {
+		return errors.New("third place match needs at least 4 players")
+	}
+
+	b.Matches = append(b.Matches, Match{
+		Round:    b.Matches[final].Round,
+		Status:   "pending",
+		Bracket:  BracketThirdPlace,
+		Position: 0,
+	})
+	thirdPlace := len(b.Matches) - 1
+
+	for _, idx := range semifinals {
+		b.linkLoser(idx, thirdPlace, b.Matches[idx].NextSlot)
+	}
+
+	b.Series.Apply(b.Matches[thirdPlace:])
+
+	return nil
+}
+
+// CalculatePlacements распределяет итоговые места завершенного турнира.
+// В форматах на выбывание место определяется стадией вылета, в круговой и
+// швейцарской системе - местом в таблице.
+func CalculatePlacements(b *Bracket, matches []Match, winnerID int) []Placement {
+	stages := make(map[int]int, len(b.Players))
+
+	switch b.Format {
+	case FormatRoundRobin:
+		for _, standing := range CalculateStandings(b.Players, matches) {
+			stages[standing.Player.ID] = -standing.Rank
+		}
+	case FormatSwiss:
+		for _, standing := range CalculateSwissStandings(b.Players, matches) {
+			stages[standing.Player.ID] = -standing.Rank
+		}
+	default:
+		eliminationStages(stages, matches)
+
+		// Не вышедшие из групп располагаются ниже участников плей-офф
+		if b.Format == FormatGroupsPlayoffs {
+			for _, table := range b.GroupStandings(matches) {
+				for _, standing := range table {
+					if standing.Rank > b.Advance {
+						stages[standing.Player.ID] = -standing.Rank
+					}
+				}
+			}
+		}
+	}
+
+	if winnerID > 0 {
+		stages[winnerID] = math.MaxInt32
+	}
+
+	return rankByStage(stages)
+}
+
+// eliminationStages записывает для каждого выбывшего игрока стадию вылета:
+// чем позже выбыл игрок, тем больше значение
+func eliminationStages(stages map[int]int, matches []Match) {
+	losersRounds := 0
+	finalRound := 0
+	for _, m := range matches {
+		if m.Bracket == BracketLosers && m.Round > losersRounds {
+			losersRounds = m.Round
+		}
+		if m.Bracket == BracketWinners && m.Round > finalRound {
+			finalRound = m.Round
+		}
+	}
+
+	for _, m := range matches {
+		if m.Status != "finished" || m.WinnerID == nil || m.Player1ID <= 0 || m.Player2ID <= 0 {
+			continue
+		}
+
+		loserID := m.Player1ID
+		if *m.WinnerID == m.Player1ID {
+			loserID = m.Player2ID
+		}
+
+		switch m.Bracket {
+		case BracketWinners:
+			// Проигравший уходит в нижнюю сетку или в матч за третье место
+			if m.LoserMatch != nil {
+				continue
+			}
+			stages[loserID] = 2 * m.Round
+		case BracketThirdPlace:
+			stages[*m.WinnerID] = 2*(finalRound-1) + 1
+			stages[loserID] = 2 * (finalRound - 1)
+		case BracketLosers:
+			stages[loserID] = 2 * m.Round
+		case BracketGrandFinal:
+			// Если сыгран повторный финал, первый не решает судьбу турнира
+			if m.NextMatch != nil && *m.NextMatch < len(matches) && matches[*m.NextMatch].Status != "cancelled" {
+				continue
+			}
+			stages[loserID] = 2 * (losersRounds + 1)
+		}
+	}
+}
+
+// rankByStage переводит стадии в места с учетом дележа
+func rankByStage(stages map[int]int) []Placement {
+	placements := make([]Placement, 0, len(stages))
+	for playerID := range stages {
+		placements = append(placements, Placement{PlayerID: playerID})
+	}
+
+	sort.Slice(placements, func(i, j int) bool {
+		si, sj := stages[placements[i].PlayerID], stages[placements[j].PlayerID]
+		if si != sj {
+			return si > sj
+		}
+		return placements[i].PlayerID < placements[j].PlayerID
+	})
+
+	for i := 0; i < len(placements); {
+		j := i
+		for j < len(placements) && stages[placements[j].PlayerID] == stages[placements[i].PlayerID] {
+			j++
+		}
+		for k := i; k < j; k++ {
+			placements[k].Place = i + 1
+			placements[k].PlaceTo = j
+		}
+		i = j
+	}
+
+	return placements
+}
@@ -0,0 +1,409 @@
+// pkg/tournament/round_robin.go
+package tournament
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+)
+
+// Очки за результат матча в групповом этапе
+const (
+	PointsPerWin  = 3
+	PointsPerLoss = 0
+)
+
+// GroupStageOptions настройки группового этапа
+type GroupStageOptions struct {
+	Groups  int  // Количество групп
+	Advance int  // Сколько игроков из каждой группы выходят в плей-офф
+	Seeded  bool // Распределение по рейтингу змейкой вместо случайного
+}
+
+// HeadToHeadRecord личные встречи с конкретным соперником
+type HeadToHeadRecord struct {
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+}
+
+// Standing строка турнирной таблицы
+type Standing struct {
+	Rank       int                      `json:"rank"`
+	Player     Player                   `json:"player"`
+	Played     int                      `json:"played"`
+	Wins       int                      `json:"wins"`
+	Losses     int                      `json:"losses"`
+	Points     int                      `json:"points"`
+	HeadToHead map[int]HeadToHeadRecord `json:"head_to_head"`
+
+	// Дополнительные показатели швейцарской системы
+	Buchholz        int `json:"buchholz,omitempty"`
+	SonnebornBerger int `json:"sonneborn_berger,omitempty"`
+}
+
+// GenerateRoundRobin создает круговой турнир: каждый играет с каждым
+func GenerateRoundRobin(players []Player) (*Bracket, error) {
+	if len(players) < 2 {
+		return nil, errors.New("need at least 2 players for tournament")
+	}
+
+	if len(players) > 32 {
+		return nil, errors.New("maximum 32 players allowed for round robin")
+	}
+
+	bracket := &Bracket{
+		Format:  FormatRoundRobin,
+		Players: players,
+		Groups:  [][]Player{players},
+		Matches: []Match{},
+	}
+
+	bracket.Rounds = bracket.addGroupMatches(1, players)
+
+	return bracket, nil
+}
+
+// GenerateGroupStage создает групповой этап с последующим плей-офф
+func GenerateGroupStage(players []Player, opts GroupStageOptions) (*Bracket, error) {
+	if opts.Groups < 1 {
+		return nil, errors.New("need at least 1 group")
+	}
+
+	if len(players) < opts.Groups*2 {
+		return nil, errors.New("each group needs at least 2 players")
+	}
+
+	if len(players) > 64 {
+		return nil, errors.New("maximum 64 players allowed")
+	}
+
+	minGroupSize := len(players) / opts.Groups
+	if opts.Advance < 1 || opts.Advance >= minGroupSize {
+		return nil, errors.New("number of advancing players must be between 1 and group size minus 1")
+	}
+
+	if opts.Groups*opts.Advance < 2 {
+		return nil, errors.New("at least 2 players must advance to playoffs")
+	}
+
+	ordered := make([]Player, len(players))
+	copy(ordered, players)
+
+	if opts.Seeded {
+		sortByRating(ordered)
+	} else {
+		for i := range ordered {
+			j := rand.Intn(i + 1)
+			ordered[i], ordered[j] = ordered[j], ordered[i]
+		}
+	}
+
+	// Распределение змейкой: 1-A, 2-B, 3-B, 4-A ...
+	groups := make([][]Player, opts.Groups)
+	for i, player := range ordered {
+		row := i / opts.Groups
+		col := i % opts.Groups
+		if row%2 == 1 {
+			col = opts.Groups - 1 - col
+		}
+		groups[col] = append(groups[col], player)
+	}
+
+	bracket := &Bracket{
+		Format:  FormatGroupsPlayoffs,
+		Players: ordered,
+		Groups:  groups,
+		Advance: opts.Advance,
+		Matches: []Match{},
+	}
+
+	for i, group := range groups {
+		rounds := bracket.addGroupMatches(i+1, group)
+		if rounds > bracket.Rounds {
+			bracket.Rounds = rounds
+		}
+	}
+
+	return bracket, nil
+}
+
+// addGroupMatches добавляет матчи группы по круговой системе (метод круга)
+// и возвращает количество туров
+func (b *Bracket) addGroupMatches(group int, players []Player) int {
+	circle := make([]*Player, 0, len(players)+1)
+	for i := range players {
+		circle = append(circle, &players[i])
+	}
+
+	// При нечетном количестве добавляем пустое место - соперник по нему отдыхает
+	if len(circle)%2 == 1 {
+		circle = append(circle, nil)
+	}
+
+	n := len(circle)
+	rounds := n - 1
+
+	for round := 1; round <= rounds; round++ {
+		position := 0
+		for i := 0; i < n/2; i++ {
+			home, away := circle[i], circle[n-1-i]
+			if home == nil || away == nil {
+				continue
+			}
+
+			// Чередуем стороны у закрепленного игрока
+			if i == 0 && round%2 == 0 {
+				home, away = away, home
+			}
+
+			b.Matches = append(b.Matches, Match{
+				Round:     round,
+				Player1ID: home.ID,
+				Player2ID: away.ID,
+				Player1:   home,
+				Player2:   away,
+				Status:    "pending",
+				Bracket:   BracketGroup,
+				Group:     group,
+				Position:  position,
+			})
+			position++
+		}
+
+		// Вращаем всех, кроме первого
+		last := circle[n-1]
+		copy(circle[2:], circle[1:n-1])
+		circle[1] = last
+	}
+
+	return rounds
+}
+
+// CalculateStandings считает турнирную таблицу группы по сыгранным матчам
+func CalculateStandings(players []Player, matches []Match) []Standing {
+	standings := tallyStandings(players, matches)
+
+	rankStandings(standings, func(s *Standing) []int {
+		return []int{s.Points}
+	})
+
+	return standings
+}
+
+// tallyStandings подсчитывает очки, победы и личные встречи по сыгранным матчам.
+// Матч без соперника (bye) засчитывается как победа.
+func tallyStandings(players []Player, matches []Match) []Standing {
+	index := make(map[int]int, len(players))
+	standings := make([]Standing, len(players))
+	for i, player := range players {
+		index[player.ID] = i
+		standings[i] = Standing{
+			Player:     player,
+			HeadToHead: make(map[int]HeadToHeadRecord),
+		}
+	}
+
+	for _, match := range matches {
+		if match.Status != "finished" || match.WinnerID == nil {
+			continue
+		}
+
+		if match.Player1ID <= 0 || match.Player2ID <= 0 {
+			if i, ok := index[*match.WinnerID]; ok {
+				standings[i].Played++
+				standings[i].Wins++
+				standings[i].Points += PointsPerWin
+			}
+			continue
+		}
+
+		i1, ok1 := index[match.Player1ID]
+		i2, ok2 := index[match.Player2ID]
+		if !ok1 || !ok2 {
+			continue
+		}
+
+		winner, loser := i1, i2
+		if *match.WinnerID == match.Player2ID {
+			winner, loser = i2, i1
+		}
+
+		standings[winner].Played++
+		standings[winner].Wins++
+		standings[winner].Points += PointsPerWin
+		standings[loser].Played++
+		standings[loser].Losses++
+		standings[loser].Points += PointsPerLoss
+
+		wID := standings[winner].Player.ID
+		lID := standings[loser].Player.ID
+
+		record := standings[winner].HeadToHead[lID]
+		record.Wins++
+		standings[winner].HeadToHead[lID] = record
+
+		record = standings[loser].HeadToHead[wID]
+		record.Losses++
+		standings[loser].HeadToHead[wID] = record
+	}
+
+	return standings
+}
+
+// rankStandings сортирует таблицу по ключам (по убыванию), затем по победам в
+// личных встречах среди игроков с равными ключами, затем по рейтингу
+func rankStandings(standings []Standing, keys func(s *Standing) []int) {
+	keyOf := make(map[int][]int, len(standings))
+	for i := range standings {
+		keyOf[standings[i].Player.ID] = keys(&standings[i])
+	}
+
+	equal := func(a, b []int) bool {
+		for i := range a {
+			if a[i] != b[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Победы в личных встречах среди игроков с равными ключами
+	tiebreak := make(map[int]int, len(standings))
+	for i := range standings {
+		for j := range standings {
+			a, b := standings[i].Player.ID, standings[j].Player.ID
+			if i == j || !equal(keyOf[a], keyOf[b]) {
+				continue
+			}
+			tiebreak[a] += standings[i].HeadToHead[b].Wins
+		}
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		a, b := standings[i].Player, standings[j].Player
+		ka, kb := keyOf[a.ID], keyOf[b.ID]
+		for k := range ka {
+			if ka[k] != kb[k] {
+				return ka[k] > kb[k]
+			}
+		}
+		if tiebreak[a.ID] != tiebreak[b.ID] {
+			return tiebreak[a.ID] > tiebreak[b.ID]
+		}
+		if a.Rating != b.Rating {
+			return a.Rating > b.Rating
+		}
+		return a.ID < b.ID
+	})
+
+	for i := range standings {
+		standings[i].Rank = i + 1
+	}
+}
+
+// GroupStandings возвращает таблицы всех групп по переданным матчам
+func (b *Bracket) GroupStandings(matches []Match) [][]Standing {
+	byGroup := make(map[int][]Match)
+	for _, match := range matches {
+		if match.Bracket == BracketGroup {
+			byGroup[match.Group] = append(byGroup[match.Group], match)
+		}
+	}
+
+	tables := make([][]Standing, len(b.Groups))
+	for i, group := range b.Groups {
+		tables[i] = CalculateStandings(group, byGroup[i+1])
+	}
+
+	return tables
+}
+
+// IsGroupStageFinished проверяет, сыграны ли все матчи групп
+func (b *Bracket) IsGroupStageFinished() bool {
+	for _, match := range b.Matches {
+		if match.Bracket == BracketGroup && match.Status != "finished" {
+			return false
+		}
+	}
+	return true
+}
+
+// HasPlayoffs проверяет, сгенерирован ли плей-офф
+func (b *Bracket) HasPlayoffs() bool {
+	for _, match := range b.Matches {
+		if match.Bracket != BracketGroup {
+			return true
+		}
+	}
+	return false
+}
+
+// AppendPlayoffs формирует плей-офф из лучших игроков групп и добавляет его матчи
+// в сетку. Возвращает добавленные матчи.
+func (b *Bracket) AppendPlayoffs(tables [][]Standing) ([]Match, error) {
+	if b.HasPlayoffs() {
+		return nil, errors.New("playoffs already generated")
+	}
+
+	// Сначала все победители групп, затем вторые места и т.д.
+	var qualified []Player
+	for place := 0; place < b.Advance; place++ {
+		var tier []Standing
+		for _, table := range tables {
+			if place < len(table) {
+				tier = append(tier, table[place])
+			}
+		}
+
+		sort.SliceStable(tier, func(i, j int) bool {
+			if tier[i].Points != tier[j].Points {
+				return tier[i].Points > tier[j].Points
+			}
+			return tier[i].Player.Rating > tier[j].Player.Rating
+		})
+
+		for _, standing := range tier {
+			qualified = append(qualified, standing.Player)
+		}
+	}
+
+	playoff, err := GeneratePlayoffBracket(qualified)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := len(b.Matches)
+	for i := range playoff.Matches {
+		m := &playoff.Matches[i]
+		if m.NextMatch != nil {
+			next := *m.NextMatch + offset
+			m.NextMatch = &next
+		}
+		if m.LoserMatch != nil {
+			next := *m.LoserMatch + offset
+			m.LoserMatch = &next
+		}
+	}
+
+	b.Series.Apply(playoff.Matches)
+	b.Matches = append(b.Matches, playoff.Matches...)
+	b.Rounds = playoff.Rounds
+
+	if b.ThirdPlace && len(qualified) >= 4 {
+		if err := b.AddThirdPlaceMatch(); err != nil {
+			return nil, err
+		}
+	}
+
+	return b.Matches[offset:], nil
+}
+
+// GeneratePlayoffBracket создает сетку на выбывание, сохраняя порядок посева
+// переданных игроков (первый - сильнейший)
+func GeneratePlayoffBracket(qualified []Player) (*Bracket, error) {
+	if len(qualified) < 2 {
+		return nil, errors.New("need at least 2 players for playoffs")
+	}
+
+	return buildSingleElimination(qualified), nil
+}
@@ -0,0 +1,264 @@
+// pkg/tournament/round_robin_test.go
+package tournament
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGenerateRoundRobin(t *testing.T) {
+	tests := []struct {
+		players    int
+		wantErr    bool
+		wantRounds int
+	}{
+		{players: 1, wantErr: true},
+		{players: 2, wantRounds: 1},
+		{players: 3, wantRounds: 3},
+		{players: 4, wantRounds: 3},
+		{players: 5, wantRounds: 5},
+		{players: 6, wantRounds: 5},
+		{players: 9, wantRounds: 9},
+		{players: 32, wantRounds: 31},
+		{players: 33, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		bracket, err := GenerateRoundRobin(testPlayers(tt.players))
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%d players: expected error", tt.players)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%d players: %v", tt.players, err)
+		}
+
+		if bracket.Rounds != tt.wantRounds {
+			t.Errorf("%d players: rounds = %d, want %d", tt.players, bracket.Rounds, tt.wantRounds)
+		}
+		if want := tt.players * (tt.players - 1) / 2; len(bracket.Matches) != want {
+			t.Errorf("%d players: matches = %d, want %d", tt.players, len(bracket.Matches), want)
+		}
+
+		// Каждая пара встречается ровно один раз, игрок играет не больше раза за тур
+		met := make(map[[2]int]int)
+		perRound := make(map[int]map[int]bool)
+		for _, match := range bracket.Matches {
+			if match.Player1ID == match.Player2ID || match.Player1ID <= 0 || match.Player2ID <= 0 {
+				t.Errorf("%d players: invalid match %d vs %d", tt.players, match.Player1ID, match.Player2ID)
+			}
+			if match.Bracket != BracketGroup || match.Group != 1 || match.Status != "pending" {
+				t.Errorf("%d players: unexpected match %+v", tt.players, match)
+			}
+			met[pairKey(match.Player1ID, match.Player2ID)]++
+
+			if perRound[match.Round] == nil {
+				perRound[match.Round] = make(map[int]bool)
+			}
+			for _, id := range []int{match.Player1ID, match.Player2ID} {
+				if perRound[match.Round][id] {
+					t.Errorf("%d players: player %d plays twice in round %d", tt.players, id, match.Round)
+				}
+				perRound[match.Round][id] = true
+			}
+		}
+
+		for a := 1; a <= tt.players; a++ {
+			for b := a + 1; b <= tt.players; b++ {
+				if count := met[[2]int{a, b}]; count != 1 {
+					t.Errorf("%d players: %d vs %d met %d times", tt.players, a, b, count)
+				}
+			}
+		}
+
+		// При нечетном количестве каждый игрок отдыхает ровно один тур
+		rests := make(map[int]int)
+		for round := 1; round <= bracket.Rounds; round++ {
+			for id := 1; id <= tt.players; id++ {
+				if !perRound[round][id] {
+					rests[id]++
+				}
+			}
+		}
+		for id := 1; id <= tt.players; id++ {
+			want := tt.players % 2
+			if rests[id] != want {
+				t.Errorf("%d players: player %d rests %d rounds, want %d", tt.players, id, rests[id], want)
+			}
+		}
+	}
+}
+
+func TestGenerateGroupStage(t *testing.T) {
+	tests := []struct {
+		name       string
+		players    int
+		opts       GroupStageOptions
+		wantErr    bool
+		wantGroups [][]int
+	}{
+		{
+			name:       "snake seeding",
+			players:    8,
+			opts:       GroupStageOptions{Groups: 2, Advance: 2, Seeded: true},
+			wantGroups: [][]int{{1, 4, 5, 8}, {2, 3, 6, 7}},
+		},
+		{
+			name:       "uneven groups",
+			players:    7,
+			opts:       GroupStageOptions{Groups: 2, Advance: 1, Seeded: true},
+			wantGroups: [][]int{{1, 4, 5}, {2, 3, 6, 7}},
+		},
+		{
+			name:       "three groups",
+			players:    9,
+			opts:       GroupStageOptions{Groups: 3, Advance: 1, Seeded: true},
+			wantGroups: [][]int{{1, 6, 7}, {2, 5, 8}, {3, 4, 9}},
+		},
+		{name: "no groups", players: 8, opts: GroupStageOptions{Advance: 1}, wantErr: true},
+		{name: "group of one", players: 5, opts: GroupStageOptions{Groups: 3, Advance: 1}, wantErr: true},
+		{name: "whole group advances", players: 8, opts: GroupStageOptions{Groups: 2, Advance: 4}, wantErr: true},
+		{name: "single qualifier", players: 4, opts: GroupStageOptions{Groups: 1, Advance: 1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bracket, err := GenerateGroupStage(testPlayers(tt.players), tt.opts)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			groups := make([][]int, len(bracket.Groups))
+			groupOf := make(map[int]int)
+			for i, group := range bracket.Groups {
+				for _, player := range group {
+					groups[i] = append(groups[i], player.ID)
+					groupOf[player.ID] = i + 1
+				}
+			}
+			if !reflect.DeepEqual(groups, tt.wantGroups) {
+				t.Errorf("groups = %v, want %v", groups, tt.wantGroups)
+			}
+
+			want := 0
+			for _, group := range groups {
+				want += len(group) * (len(group) - 1) / 2
+			}
+			if len(bracket.Matches) != want {
+				t.Errorf("matches = %d, want %d", len(bracket.Matches), want)
+			}
+
+			for _, match := range bracket.Matches {
+				if groupOf[match.Player1ID] != match.Group || groupOf[match.Player2ID] != match.Group {
+					t.Errorf("match %d vs %d is not inside group %d", match.Player1ID, match.Player2ID, match.Group)
+				}
+			}
+		})
+	}
+}
+
+func TestCalculateStandings(t *testing.T) {
+	tests := []struct {
+		name       string
+		players    int
+		matches    []Match
+		wantOrder  []int
+		wantPoints map[int]int
+	}{
+		{
+			// 1 и 2 решает личная встреча, 4 выше 3 несмотря на рейтинг
+			name:    "head to head breaks equal points",
+			players: 4,
+			matches: []Match{
+				finished(BracketGroup, 1, 1, 2, 1),
+				finished(BracketGroup, 1, 3, 4, 4),
+				finished(BracketGroup, 2, 3, 1, 3),
+				finished(BracketGroup, 2, 2, 4, 2),
+				finished(BracketGroup, 3, 1, 4, 1),
+				finished(BracketGroup, 3, 2, 3, 2),
+			},
+			wantOrder:  []int{1, 2, 4, 3},
+			wantPoints: map[int]int{1: 6, 2: 6, 3: 3, 4: 3},
+		},
+		{
+			// Круговая ничья по личным встречам решается рейтингом
+			name:    "cycle falls back to rating",
+			players: 3,
+			matches: []Match{
+				finished(BracketGroup, 1, 1, 2, 2),
+				finished(BracketGroup, 2, 2, 3, 3),
+				finished(BracketGroup, 3, 3, 1, 1),
+			},
+			wantOrder:  []int{1, 2, 3},
+			wantPoints: map[int]int{1: 3, 2: 3, 3: 3},
+		},
+		{
+			// Несыгранные матчи не учитываются
+			name:    "ignores unfinished matches",
+			players: 3,
+			matches: []Match{
+				finished(BracketGroup, 1, 1, 3, 3),
+				{Round: 2, Player1ID: 1, Player2ID: 2, Status: "pending", Bracket: BracketGroup},
+			},
+			wantOrder:  []int{3, 1, 2},
+			wantPoints: map[int]int{1: 0, 2: 0, 3: 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			standings := CalculateStandings(testPlayers(tt.players), tt.matches)
+
+			order := make([]int, len(standings))
+			for i, standing := range standings {
+				order[i] = standing.Player.ID
+				if standing.Rank != i+1 {
+					t.Errorf("player %d rank = %d, want %d", standing.Player.ID, standing.Rank, i+1)
+				}
+				if standing.Points != tt.wantPoints[standing.Player.ID] {
+					t.Errorf("player %d points = %d, want %d", standing.Player.ID, standing.Points, tt.wantPoints[standing.Player.ID])
+				}
+				if standing.Played != standing.Wins+standing.Losses {
+					t.Errorf("player %d played %d, wins %d, losses %d", standing.Player.ID, standing.Played, standing.Wins, standing.Losses)
+				}
+			}
+
+			if !reflect.DeepEqual(order, tt.wantOrder) {
+				t.Errorf("order = %v, want %v", order, tt.wantOrder)
+			}
+		})
+	}
+}
+
+func TestGroupStageAppendsPlayoffs(t *testing.T) {
+	bracket, err := GenerateGroupStage(testPlayers(8), GroupStageOptions{Groups: 2, Advance: 2, Seeded: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	playBracket(t, bracket, higherRated)
+
+	// Победители групп посеяны выше вторых мест: 1 и 2, затем 3 и 4
+	var semis [][2]int
+	for _, match := range bracket.Matches {
+		if match.Bracket == BracketWinners && match.Round == 1 {
+			semis = append(semis, [2]int{match.Player1ID, match.Player2ID})
+		}
+	}
+	if want := [][2]int{{1, 4}, {2, 3}}; !reflect.DeepEqual(semis, want) {
+		t.Errorf("semifinals = %v, want %v", semis, want)
+	}
+
+	done, champion := bracket.IsTournamentFinished()
+	if !done || champion == nil || champion.ID != 1 {
+		t.Errorf("finished = %v, champion = %v, want 1", done, champion)
+	}
+}
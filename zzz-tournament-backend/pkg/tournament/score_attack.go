@@ -0,0 +1,141 @@
+// pkg/tournament/score_attack.go
+package tournament
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// Типы результата захода
+const (
+	ScoreTypeScore = "score" // Побеждает больший счет (Deadly Assault)
+	ScoreTypeTime  = "time"  // Побеждает меньшее время прохождения (Shiyu Defense)
+)
+
+// Run подтвержденный заход участника
+type Run struct {
+	ID          int
+	PlayerID    int
+	Score       int64
+	ClearTimeMs int64
+	SubmittedAt time.Time
+}
+
+// RankedRun лучший заход участника с местом в таблице
+type RankedRun struct {
+	Run
+	Rank int
+}
+
+// IsValidScoreType проверяет тип результата захода
+func IsValidScoreType(scoreType string) bool {
+	return scoreType == ScoreTypeScore || scoreType == ScoreTypeTime
+}
+
+// GenerateScoreAttack создает турнир на очки или время: матчей нет, в сетке
+// сохраняется только состав участников на момент старта
+func GenerateScoreAttack(players []Player) (*Bracket, error) {
+	if len(players) < 2 {
+		return nil, errors.New("need at least 2 players for tournament")
+	}
+
+	return &Bracket{
+		Format:  FormatScoreAttack,
+		Matches: []Match{},
+		Players: players,
+	}, nil
+}
+
+// better сравнивает результаты заходов; при равенстве выше тот, кто отправил заход раньше
+func better(scoreType string, a, b Run) bool {
+	if cmp := compareRuns(scoreType, a, b); cmp != 0 {
+		return cmp < 0
+	}
+	return a.SubmittedAt.Before(b.SubmittedAt)
+}
+
+// compareRuns возвращает -1, если результат a лучше b, 1 - если хуже, 0 - если равен
+func compareRuns(scoreType string, a, b Run) int {
+	x, y := a.Score, b.Score
+	if scoreType == ScoreTypeTime {
+		x, y = b.ClearTimeMs, a.ClearTimeMs
+	}
+
+	switch {
+	case x > y:
+		return -1
+	case x < y:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// RankRuns оставляет лучший заход каждого участника и расставляет места.
+// Участники с одинаковым результатом делят место, следующее место
+// пропускается (1, 1, 3).
+func RankRuns(scoreType string, runs []Run) []RankedRun {
+	best := make(map[int]Run)
+	for _, run := range runs {
+		if current, ok := best[run.PlayerID]; !ok || better(scoreType, run, current) {
+			best[run.PlayerID] = run
+		}
+	}
+
+	ranked := make([]RankedRun, 0, len(best))
+	for _, run := range best {
+		ranked = append(ranked, RankedRun{Run: run})
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		return better(scoreType, ranked[i].Run, ranked[j].Run)
+	})
+
+	for i := range ranked {
+		if i > 0 && compareRuns(scoreType, ranked[i].Run, ranked[i-1].Run) == 0 {
+			ranked[i].Rank = ranked[i-1].Rank
+		} else {
+			ranked[i].Rank = i + 1
+		}
+	}
+
+	return ranked
+}
+
+// ScoreAttackPlacements рассчитывает итоговые места по таблице заходов.
+// Участники с равным результатом делят диапазон мест, участники без
+// подтвержденного захода делят последние места.
+func ScoreAttackPlacements(players []Player, ranked []RankedRun) []Placement {
+	placements := make([]Placement, 0, len(players))
+	placed := make(map[int]bool, len(ranked))
+
+	for i := 0; i < len(ranked); {
+		j := i
+		for j < len(ranked) && ranked[j].Rank == ranked[i].Rank {
+			j++
+		}
+		for _, run := range ranked[i:j] {
+			placements = append(placements, Placement{
+				PlayerID: run.PlayerID,
+				Place:    ranked[i].Rank,
+				PlaceTo:  ranked[i].Rank + j - i - 1,
+			})
+			placed[run.PlayerID] = true
+		}
+		i = j
+	}
+
+	place := len(placements) + 1
+	for _, player := range players {
+		if !placed[player.ID] {
+			placements = append(placements, Placement{
+				PlayerID: player.ID,
+				Place:    place,
+				PlaceTo:  len(players),
+			})
+		}
+	}
+
+	return placements
+}
@@ -0,0 +1,132 @@
+// pkg/tournament/series.go
+package tournament
+
+import (
+	"errors"
+	"sort"
+)
+
+// MaxBestOf максимальное количество игр в серии
+const MaxBestOf = 9
+
+// SeriesFormat количество игр в матчах турнира
+type SeriesFormat struct {
+	BestOf       int         `json:"best_of"`                  // По умолчанию для всех матчей
+	ByRound      map[int]int `json:"by_round,omitempty"`       // Переопределение по номеру раунда
+	FinalsBestOf int         `json:"finals_best_of,omitempty"` // Для финальных матчей
+}
+
+// GameResult результат отдельной игры серии
+type GameResult struct {
+	Number   int `json:"game_number"`
+	WinnerID int `json:"winner_id"`
+}
+
+// Validate проверяет, что все значения - нечетные числа от 1 до MaxBestOf
+func (s *SeriesFormat) Validate() error {
+	values := []int{s.BestOf}
+	if s.FinalsBestOf != 0 {
+		values = append(values, s.FinalsBestOf)
+	}
+	for round, bestOf := range s.ByRound {
+		if round < 1 {
+			return errors.New("round number must be positive")
+		}
+		values = append(values, bestOf)
+	}
+
+	for _, bestOf := range values {
+		if bestOf < 1 || bestOf > MaxBestOf || bestOf%2 == 0 {
+			return errors.New("best-of must be an odd number between 1 and 9")
+		}
+	}
+
+	return nil
+}
+
+// BestOfFor возвращает количество игр для матча
+func (s *SeriesFormat) BestOfFor(match Match) int {
+	if s == nil {
+		return 1
+	}
+
+	if s.FinalsBestOf > 0 && IsFinalMatch(match) {
+		return s.FinalsBestOf
+	}
+
+	if bestOf, ok := s.ByRound[match.Round]; ok {
+		return bestOf
+	}
+
+	if s.BestOf > 0 {
+		return s.BestOf
+	}
+
+	return 1
+}
+
+// Apply проставляет количество игр переданным матчам
+func (s *SeriesFormat) Apply(matches []Match) {
+	for i := range matches {
+		matches[i].BestOf = s.BestOfFor(matches[i])
+	}
+}
+
+// IsFinalMatch проверяет, решает ли матч судьбу турнира: матч на выбывание без
+// следующего матча или гранд-финал
+func IsFinalMatch(match Match) bool {
+	switch match.Bracket {
+	case BracketGroup, BracketSwiss, BracketThirdPlace:
+		return false
+	case BracketGrandFinal:
+		return true
+	}
+	return match.NextMatch == nil
+}
+
+// WinsRequired возвращает количество побед, необходимое для выигрыша серии
+func WinsRequired(bestOf int) int {
+	if bestOf < 1 {
+		bestOf = 1
+	}
+	return bestOf/2 + 1
+}
+
+// SeriesWinner определяет победителя серии по сыгранным играм.
+// Возвращает 0, если серия еще не решена.
+func SeriesWinner(bestOf, player1ID, player2ID int, games []GameResult) (int, error) {
+	if len(games) > bestOf {
+		return 0, errors.New("too many games for this series")
+	}
+
+	sorted := make([]GameResult, len(games))
+	copy(sorted, games)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Number < sorted[j].Number
+	})
+
+	required := WinsRequired(bestOf)
+	wins := make(map[int]int, 2)
+	winnerID := 0
+
+	for i, game := range sorted {
+		if game.Number != i+1 {
+			return 0, errors.New("games must be numbered consecutively starting from 1")
+		}
+
+		if game.WinnerID != player1ID && game.WinnerID != player2ID {
+			return 0, errors.New("game winner must be one of the match participants")
+		}
+
+		if winnerID != 0 {
+			return 0, errors.New("series is already decided")
+		}
+
+		wins[game.WinnerID]++
+		if wins[game.WinnerID] >= required {
+			winnerID = game.WinnerID
+		}
+	}
+
+	return winnerID, nil
+}
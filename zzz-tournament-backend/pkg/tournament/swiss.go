@@ -0,0 +1,300 @@
+// pkg/tournament/swiss.go
+package tournament
+
+import (
+	"errors"
+	"math"
+)
+
+// GenerateSwiss создает турнир по швейцарской системе. Сразу формируется только
+// первый тур, следующие создаются по мере завершения предыдущих.
+func GenerateSwiss(players []Player, rounds int) (*Bracket, error) {
+	if len(players) < 2 {
+		return nil, errors.New("need at least 2 players for tournament")
+	}
+
+	if len(players) > 64 {
+		return nil, errors.New("maximum 64 players allowed")
+	}
+
+	if rounds == 0 {
+		rounds = DefaultSwissRounds(len(players))
+	}
+
+	if rounds < 1 || rounds >= len(players)+len(players)%2 {
+		return nil, errors.New("number of swiss rounds must be between 1 and number of players minus 1")
+	}
+
+	bracket := &Bracket{
+		Format:  FormatSwiss,
+		Rounds:  rounds,
+		Players: players,
+		Matches: []Match{},
+	}
+
+	if _, err := bracket.AppendSwissRound(); err != nil {
+		return nil, err
+	}
+
+	return bracket, nil
+}
+
+// DefaultSwissRounds возвращает рекомендуемое количество туров для числа игроков
+func DefaultSwissRounds(players int) int {
+	if players < 2 {
+		return 0
+	}
+	return int(math.Ceil(math.Log2(float64(players))))
+}
+
+// CurrentSwissRound возвращает номер последнего созданного тура
+func CurrentSwissRound(matches []Match) int {
+	round := 0
+	for _, match := range matches {
+		if match.Bracket == BracketSwiss && match.Round > round {
+			round = match.Round
+		}
+	}
+	return round
+}
+
+// AppendSwissRound формирует следующий тур по текущей таблице и добавляет его в сетку
+func (b *Bracket) AppendSwissRound() ([]Match, error) {
+	round := CurrentSwissRound(b.Matches) + 1
+	if round > b.Rounds {
+		return nil, errors.New("all swiss rounds already generated")
+	}
+
+	if !b.isSwissRoundFinished() {
+		return nil, errors.New("current round is not finished")
+	}
+
+	matches := PairSwissRound(b.Players, b.Matches, round)
+	b.Series.Apply(matches)
+	b.Matches = append(b.Matches, matches...)
+
+	return matches, nil
+}
+
+// PairSwissRound составляет пары тура: игроки с близким количеством очков
+// играют между собой, повторные встречи исключаются, при нечетном количестве
+// участников bye получает самый низкий в таблице игрок, еще не получавший bye,
+// при котором тур обходится без повторных встреч
+func PairSwissRound(players []Player, matches []Match, round int) []Match {
+	standings := CalculateSwissStandings(players, matches)
+
+	played := make(map[[2]int]bool)
+	hadBye := make(map[int]bool)
+	for _, match := range matches {
+		if match.Bracket != BracketSwiss {
+			continue
+		}
+		if match.Player2ID <= 0 {
+			hadBye[match.Player1ID] = true
+			continue
+		}
+		played[pairKey(match.Player1ID, match.Player2ID)] = true
+	}
+
+	order := make([]Player, 0, len(standings))
+	for _, standing := range standings {
+		order = append(order, standing.Player)
+	}
+
+	// При нечетном количестве участников bye по очереди пробуется отдать игрокам
+	// снизу таблицы, пока не найдется тур без повторных встреч
+	byeCandidates := []int{-1}
+	if len(order)%2 == 1 {
+		byeCandidates = byeCandidates[:0]
+		for i := len(order) - 1; i >= 0; i-- {
+			if !hadBye[order[i].ID] {
+				byeCandidates = append(byeCandidates, i)
+			}
+		}
+		if len(byeCandidates) == 0 {
+			byeCandidates = append(byeCandidates, len(order)-1)
+		}
+	}
+
+	var bye *Player
+	var pairs [][2]int
+	budget := maxSwissPairingSteps
+	for _, index := range byeCandidates {
+		rest := order
+		if index >= 0 {
+			rest = make([]Player, 0, len(order)-1)
+			rest = append(rest, order[:index]...)
+			rest = append(rest, order[index+1:]...)
+		}
+
+		if pairs = pairWithoutRematches(rest, played, &budget); pairs != nil {
+			if index >= 0 {
+				player := order[index]
+				bye = &player
+			}
+			order = rest
+			break
+		}
+		if budget <= 0 {
+			break
+		}
+	}
+
+	if pairs == nil {
+		// Без повторных встреч составить тур нельзя или перебор слишком долгий -
+		// bye получает первый кандидат, остальные играют по порядку таблицы
+		if index := byeCandidates[0]; index >= 0 {
+			player := order[index]
+			bye = &player
+			order = append(order[:index:index], order[index+1:]...)
+		}
+		for i := 0; i+1 < len(order); i += 2 {
+			pairs = append(pairs, [2]int{i, i + 1})
+		}
+	}
+
+	result := make([]Match, 0, len(pairs)+1)
+	for position, pair := range pairs {
+		home, away := order[pair[0]], order[pair[1]]
+		result = append(result, Match{
+			Round:     round,
+			Player1ID: home.ID,
+			Player2ID: away.ID,
+			Player1:   &home,
+			Player2:   &away,
+			Status:    "pending",
+			Bracket:   BracketSwiss,
+			Position:  position,
+		})
+	}
+
+	if bye != nil {
+		winnerID := bye.ID
+		result = append(result, Match{
+			Round:     round,
+			Player1ID: bye.ID,
+			Player1:   bye,
+			WinnerID:  &winnerID,
+			Winner:    bye,
+			Status:    "finished",
+			Bracket:   BracketSwiss,
+			Position:  len(pairs),
+		})
+	}
+
+	return result
+}
+
+// maxSwissPairingSteps ограничивает перебор при составлении тура. Тур формируется
+// в транзакции записи результата, поэтому перебор не должен быть экспоненциальным.
+const maxSwissPairingSteps = 20000
+
+// pairWithoutRematches подбирает пары перебором с возвратом, начиная с лидеров
+// таблицы. Каждая попытка пары расходует шаг из budget. Возвращает nil, если
+// без повторных встреч обойтись нельзя или шаги закончились.
+func pairWithoutRematches(order []Player, played map[[2]int]bool, budget *int) [][2]int {
+	used := make([]bool, len(order))
+	pairs := make([][2]int, 0, len(order)/2)
+
+	var pair func() bool
+	pair = func() bool {
+		first := -1
+		for i := range order {
+			if !used[i] {
+				first = i
+				break
+			}
+		}
+		if first < 0 {
+			return true
+		}
+
+		used[first] = true
+		for j := first + 1; j < len(order); j++ {
+			if used[j] || played[pairKey(order[first].ID, order[j].ID)] {
+				continue
+			}
+			if *budget <= 0 {
+				break
+			}
+			*budget--
+
+			used[j] = true
+			pairs = append(pairs, [2]int{first, j})
+			if pair() {
+				return true
+			}
+			pairs = pairs[:len(pairs)-1]
+			used[j] = false
+		}
+		used[first] = false
+
+		return false
+	}
+
+	if !pair() {
+		return nil
+	}
+
+	return pairs
+}
+
+// CalculateSwissStandings считает таблицу швейцарской системы: очки, затем
+// коэффициент Бухгольца, Зоннеборна-Бергера и личные встречи
+func CalculateSwissStandings(players []Player, matches []Match) []Standing {
+	swissMatches := make([]Match, 0, len(matches))
+	for _, match := range matches {
+		if match.Bracket == BracketSwiss {
+			swissMatches = append(swissMatches, match)
+		}
+	}
+
+	standings := tallyStandings(players, swissMatches)
+
+	points := make(map[int]int, len(standings))
+	for _, standing := range standings {
+		points[standing.Player.ID] = standing.Points
+	}
+
+	for i := range standings {
+		for opponentID, record := range standings[i].HeadToHead {
+			games := record.Wins + record.Losses
+			standings[i].Buchholz += points[opponentID] * games
+			standings[i].SonnebornBerger += points[opponentID] * record.Wins
+		}
+	}
+
+	rankStandings(standings, func(s *Standing) []int {
+		return []int{s.Points, s.Buchholz, s.SonnebornBerger}
+	})
+
+	return standings
+}
+
+// isSwissFinished проверяет, сыграны ли все туры
+func (b *Bracket) isSwissFinished() (bool, *Player) {
+	if CurrentSwissRound(b.Matches) < b.Rounds || !b.isSwissRoundFinished() {
+		return false, nil
+	}
+
+	standings := CalculateSwissStandings(b.Players, b.Matches)
+	return true, &standings[0].Player
+}
+
+// isSwissRoundFinished проверяет, сыграны ли все матчи текущего тура
+func (b *Bracket) isSwissRoundFinished() bool {
+	for _, match := range b.Matches {
+		if match.Bracket == BracketSwiss && match.Status != "finished" {
+			return false
+		}
+	}
+	return true
+}
+
+// pairKey ключ пары игроков, не зависящий от порядка
+func pairKey(a, b int) [2]int {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]int{a, b}
+}
@@ -0,0 +1,417 @@
+// pkg/tournament/swiss_test.go
+package tournament
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testPlayers возвращает игроков с ID от 1 до n, рейтинг убывает вместе с посевом
+func testPlayers(n int) []Player {
+	players := make([]Player, n)
+	for i := range players {
+		players[i] = Player{ID: i + 1, Rating: 2000 - i*10}
+	}
+	return players
+}
+
+// finished возвращает завершенный матч с указанным победителем
+func finished(bracket string, round, player1ID, player2ID, winnerID int) Match {
+	return Match{
+		Round:     round,
+		Player1ID: player1ID,
+		Player2ID: player2ID,
+		WinnerID:  &winnerID,
+		Status:    "finished",
+		Bracket:   bracket,
+	}
+}
+
+// higherRated побеждает игрок с большим рейтингом
+func higherRated(match Match) int {
+	if match.Player2.Rating > match.Player1.Rating {
+		return match.Player2ID
+	}
+	return match.Player1ID
+}
+
+// playBracket доигрывает сетку, победителя каждого матча выбирает winner
+func playBracket(t *testing.T, b *Bracket, winner func(Match) int) {
+	t.Helper()
+
+	for steps := 0; ; steps++ {
+		if steps > 1000 {
+			t.Fatal("bracket did not finish")
+		}
+
+		next := -1
+		for i, match := range b.Matches {
+			if match.Status == "pending" && match.Player1ID > 0 && match.Player2ID > 0 {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			return
+		}
+
+		if err := b.AdvanceMatch(next, winner(b.Matches[next])); err != nil {
+			t.Fatalf("advance match %d: %v", next, err)
+		}
+	}
+}
+
+func TestGenerateSwissValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		players    int
+		rounds     int
+		wantErr    bool
+		wantRounds int
+	}{
+		{name: "one player", players: 1, wantErr: true},
+		{name: "default rounds", players: 8, wantRounds: 3},
+		{name: "default rounds odd", players: 5, wantRounds: 3},
+		{name: "explicit rounds", players: 6, rounds: 5, wantRounds: 5},
+		{name: "odd players may play everyone", players: 5, rounds: 5, wantRounds: 5},
+		{name: "too many rounds", players: 6, rounds: 6, wantErr: true},
+		{name: "too many rounds odd", players: 5, rounds: 6, wantErr: true},
+		{name: "negative rounds", players: 4, rounds: -1, wantErr: true},
+		{name: "too many players", players: 65, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bracket, err := GenerateSwiss(testPlayers(tt.players), tt.rounds)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if bracket.Rounds != tt.wantRounds {
+				t.Errorf("rounds = %d, want %d", bracket.Rounds, tt.wantRounds)
+			}
+			if round := CurrentSwissRound(bracket.Matches); round != 1 {
+				t.Errorf("current round = %d, want 1", round)
+			}
+		})
+	}
+}
+
+func TestPairSwissRoundFirstRound(t *testing.T) {
+	tests := []struct {
+		players   int
+		wantPairs int
+		wantBye   int
+	}{
+		{players: 2, wantPairs: 1},
+		{players: 3, wantPairs: 1, wantBye: 3},
+		{players: 5, wantPairs: 2, wantBye: 5},
+		{players: 8, wantPairs: 4},
+		{players: 9, wantPairs: 4, wantBye: 9},
+	}
+
+	for _, tt := range tests {
+		matches := PairSwissRound(testPlayers(tt.players), nil, 1)
+
+		pairs, bye := 0, 0
+		seen := make(map[int]bool)
+		for _, match := range matches {
+			if match.Round != 1 || match.Bracket != BracketSwiss {
+				t.Errorf("%d players: unexpected match %+v", tt.players, match)
+			}
+			for _, id := range []int{match.Player1ID, match.Player2ID} {
+				if id <= 0 {
+					continue
+				}
+				if seen[id] {
+					t.Errorf("%d players: player %d paired twice", tt.players, id)
+				}
+				seen[id] = true
+			}
+
+			if match.Player2ID <= 0 {
+				bye = match.Player1ID
+				if match.Status != "finished" || match.WinnerID == nil || *match.WinnerID != bye {
+					t.Errorf("%d players: bye is not an automatic win: %+v", tt.players, match)
+				}
+				continue
+			}
+			pairs++
+		}
+
+		if pairs != tt.wantPairs {
+			t.Errorf("%d players: pairs = %d, want %d", tt.players, pairs, tt.wantPairs)
+		}
+		if bye != tt.wantBye {
+			t.Errorf("%d players: bye = %d, want %d", tt.players, bye, tt.wantBye)
+		}
+		if len(seen) != tt.players {
+			t.Errorf("%d players: %d players scheduled", tt.players, len(seen))
+		}
+	}
+}
+
+func TestPairSwissRoundAvoidsRematches(t *testing.T) {
+	tests := []struct {
+		name      string
+		players   int
+		matches   []Match
+		round     int
+		wantPairs [][2]int
+		wantBye   int
+	}{
+		{
+			// Лидер уже играл со вторым и третьим местом
+			name:    "leader already met next two",
+			players: 4,
+			matches: []Match{
+				finished(BracketSwiss, 1, 1, 2, 1),
+				finished(BracketSwiss, 1, 3, 4, 3),
+				finished(BracketSwiss, 2, 1, 3, 1),
+				finished(BracketSwiss, 2, 2, 4, 2),
+			},
+			round:     3,
+			wantPairs: [][2]int{{1, 4}, {2, 3}},
+		},
+		{
+			// Последний в таблице уже получал bye, поэтому bye переходит выше
+			name:    "second bye goes to next lowest",
+			players: 5,
+			matches: []Match{
+				finished(BracketSwiss, 1, 1, 2, 1),
+				finished(BracketSwiss, 1, 3, 4, 3),
+				finished(BracketSwiss, 1, 5, 0, 5),
+			},
+			round:     2,
+			wantPairs: [][2]int{{1, 3}, {5, 2}},
+			wantBye:   4,
+		},
+		{
+			// Все игроки ниже уже получали bye, поэтому его получает лидер
+			name:    "bye moves up past players who had one",
+			players: 3,
+			matches: []Match{
+				finished(BracketSwiss, 1, 1, 2, 1),
+				finished(BracketSwiss, 1, 3, 0, 3),
+				finished(BracketSwiss, 2, 3, 1, 3),
+				finished(BracketSwiss, 2, 2, 0, 2),
+			},
+			round:     3,
+			wantPairs: [][2]int{{3, 2}},
+			wantBye:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := PairSwissRound(testPlayers(tt.players), tt.matches, tt.round)
+
+			var pairs [][2]int
+			bye := 0
+			for _, match := range matches {
+				if match.Round != tt.round {
+					t.Errorf("round = %d, want %d", match.Round, tt.round)
+				}
+				if match.Player2ID <= 0 {
+					bye = match.Player1ID
+					continue
+				}
+				pairs = append(pairs, [2]int{match.Player1ID, match.Player2ID})
+			}
+
+			if !reflect.DeepEqual(pairs, tt.wantPairs) {
+				t.Errorf("pairs = %v, want %v", pairs, tt.wantPairs)
+			}
+			if bye != tt.wantBye {
+				t.Errorf("bye = %d, want %d", bye, tt.wantBye)
+			}
+		})
+	}
+}
+
+func TestSwissTournamentWithoutRematches(t *testing.T) {
+	tests := []struct {
+		players int
+		rounds  int
+	}{
+		{players: 4, rounds: 3},
+		{players: 5, rounds: 4},
+		{players: 7, rounds: 3},
+		{players: 8, rounds: 3},
+		{players: 9, rounds: 4},
+		{players: 16, rounds: 4},
+		{players: 33, rounds: 6},
+		{players: 64, rounds: 6},
+	}
+
+	for _, tt := range tests {
+		players := testPlayers(tt.players)
+		bracket, err := GenerateSwiss(players, tt.rounds)
+		if err != nil {
+			t.Fatalf("%d players: %v", tt.players, err)
+		}
+
+		playBracket(t, bracket, higherRated)
+
+		done, winner := bracket.IsTournamentFinished()
+		if !done || winner == nil || winner.ID != 1 {
+			t.Fatalf("%d players: finished = %v, winner = %v", tt.players, done, winner)
+		}
+		if round := CurrentSwissRound(bracket.Matches); round != tt.rounds {
+			t.Errorf("%d players: rounds played = %d, want %d", tt.players, round, tt.rounds)
+		}
+
+		met := make(map[[2]int]int)
+		byes := make(map[int]int)
+		perRound := make(map[int]map[int]bool)
+		for _, match := range bracket.Matches {
+			if perRound[match.Round] == nil {
+				perRound[match.Round] = make(map[int]bool)
+			}
+			for _, id := range []int{match.Player1ID, match.Player2ID} {
+				if id <= 0 {
+					continue
+				}
+				if perRound[match.Round][id] {
+					t.Errorf("%d players: player %d plays twice in round %d", tt.players, id, match.Round)
+				}
+				perRound[match.Round][id] = true
+			}
+
+			if match.Player2ID <= 0 {
+				byes[match.Player1ID]++
+				continue
+			}
+			met[pairKey(match.Player1ID, match.Player2ID)]++
+		}
+
+		for pair, count := range met {
+			if count > 1 {
+				t.Errorf("%d players: %v met %d times", tt.players, pair, count)
+			}
+		}
+		for id, count := range byes {
+			if count > 1 {
+				t.Errorf("%d players: player %d got %d byes", tt.players, id, count)
+			}
+		}
+		for round, seen := range perRound {
+			if len(seen) != tt.players {
+				t.Errorf("%d players: round %d schedules %d players", tt.players, round, len(seen))
+			}
+		}
+	}
+}
+
+func TestCalculateSwissStandings(t *testing.T) {
+	tests := []struct {
+		name         string
+		players      int
+		matches      []Match
+		wantOrder    []int
+		wantBuchholz map[int]int
+		wantSB       map[int]int
+	}{
+		{
+			// У 3 и 4 по одной победе, но соперники 3 набрали больше
+			name:    "buchholz breaks equal points",
+			players: 4,
+			matches: []Match{
+				finished(BracketSwiss, 1, 1, 2, 1),
+				finished(BracketSwiss, 1, 3, 4, 3),
+				finished(BracketSwiss, 2, 1, 3, 1),
+				finished(BracketSwiss, 2, 4, 2, 4),
+			},
+			wantOrder:    []int{1, 3, 4, 2},
+			wantBuchholz: map[int]int{1: 3, 2: 9, 3: 9, 4: 3},
+			wantSB:       map[int]int{1: 3, 2: 0, 3: 3, 4: 0},
+		},
+		{
+			// Bye приносит очки, но не соперника для Бухгольца
+			name:    "bye counts as win without opponent",
+			players: 3,
+			matches: []Match{
+				finished(BracketSwiss, 1, 1, 2, 1),
+				finished(BracketSwiss, 1, 3, 0, 3),
+				finished(BracketSwiss, 2, 3, 1, 3),
+				finished(BracketSwiss, 2, 2, 0, 2),
+			},
+			wantOrder:    []int{3, 1, 2},
+			wantBuchholz: map[int]int{1: 9, 2: 3, 3: 3},
+			wantSB:       map[int]int{1: 3, 2: 0, 3: 3},
+		},
+		{
+			// Полное равенство показателей решает рейтинг
+			name:    "full tie falls back to rating",
+			players: 4,
+			matches: []Match{
+				finished(BracketSwiss, 1, 1, 2, 1),
+				finished(BracketSwiss, 1, 3, 4, 3),
+				finished(BracketSwiss, 2, 4, 1, 4),
+				finished(BracketSwiss, 2, 2, 3, 2),
+			},
+			wantOrder:    []int{1, 2, 3, 4},
+			wantBuchholz: map[int]int{1: 6, 2: 6, 3: 6, 4: 6},
+			wantSB:       map[int]int{1: 3, 2: 3, 3: 3, 4: 3},
+		},
+		{
+			// Матчи других сеток не учитываются
+			name:    "ignores other brackets",
+			players: 2,
+			matches: []Match{
+				finished(BracketSwiss, 1, 1, 2, 2),
+				finished(BracketGroup, 1, 1, 2, 1),
+				finished(BracketWinners, 1, 1, 2, 1),
+			},
+			wantOrder:    []int{2, 1},
+			wantBuchholz: map[int]int{1: 3, 2: 0},
+			wantSB:       map[int]int{1: 0, 2: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			standings := CalculateSwissStandings(testPlayers(tt.players), tt.matches)
+
+			order := make([]int, len(standings))
+			for i, standing := range standings {
+				order[i] = standing.Player.ID
+				if standing.Rank != i+1 {
+					t.Errorf("player %d rank = %d, want %d", standing.Player.ID, standing.Rank, i+1)
+				}
+				if got := standing.Buchholz; got != tt.wantBuchholz[standing.Player.ID] {
+					t.Errorf("player %d buchholz = %d, want %d", standing.Player.ID, got, tt.wantBuchholz[standing.Player.ID])
+				}
+				if got := standing.SonnebornBerger; got != tt.wantSB[standing.Player.ID] {
+					t.Errorf("player %d sonneborn-berger = %d, want %d", standing.Player.ID, got, tt.wantSB[standing.Player.ID])
+				}
+			}
+
+			if !reflect.DeepEqual(order, tt.wantOrder) {
+				t.Errorf("order = %v, want %v", order, tt.wantOrder)
+			}
+		})
+	}
+}
+
+func TestAppendSwissRound(t *testing.T) {
+	bracket, err := GenerateSwiss(testPlayers(4), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = bracket.AppendSwissRound(); err == nil {
+		t.Error("expected error while first round is not finished")
+	}
+
+	playBracket(t, bracket, higherRated)
+
+	if _, err = bracket.AppendSwissRound(); err == nil {
+		t.Error("expected error after the last round")
+	}
+}
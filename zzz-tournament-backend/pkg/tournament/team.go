@@ -0,0 +1,60 @@
+// pkg/tournament/team.go
+package tournament
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Team команда-участник командного турнира
+type Team struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Rating      int    `json:"rating"`
+	CaptainID   int    `json:"captain_id"`
+	Players     []int  `json:"players"`     // Основные игроки, включая капитана
+	Substitutes []int  `json:"substitutes"` // Запасные игроки
+}
+
+// TeamPlayers представляет команды участниками сетки. Генераторы сеток
+// работают с участниками независимо от режима, поэтому команду в сетке
+// представляет ее капитан: ID участника - ID капитана, имя и рейтинг - команды.
+// Так результат матча от имени команды может отправить только капитан.
+func TeamPlayers(teams []Team, teamSize int) ([]Player, error) {
+	players := make([]Player, 0, len(teams))
+	seen := make(map[int]int, len(teams)*2)
+
+	for _, team := range teams {
+		if len(team.Players) < teamSize {
+			return nil, fmt.Errorf("team %q needs at least %d players", team.Name, teamSize)
+		}
+
+		captainFound := false
+		for _, userID := range append(append([]int{}, team.Players...), team.Substitutes...) {
+			if other, ok := seen[userID]; ok && other != team.ID {
+				return nil, fmt.Errorf("player %d is listed in more than one team", userID)
+			}
+			seen[userID] = team.ID
+			if userID == team.CaptainID {
+				captainFound = true
+			}
+		}
+
+		if !captainFound {
+			return nil, fmt.Errorf("captain of team %q is not in its roster", team.Name)
+		}
+
+		players = append(players, Player{
+			ID:       team.CaptainID,
+			Username: team.Name,
+			Rating:   team.Rating,
+			TeamID:   team.ID,
+		})
+	}
+
+	if len(players) < 2 {
+		return nil, errors.New("need at least 2 teams for tournament")
+	}
+
+	return players, nil
+}